@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"phase3-api-architecture/internal/event"
+	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/stream"
+	"phase3-api-architecture/repository"
+	"time"
+)
+
+// expiryAlertJob mengirim event 'stock-alerts' untuk lot yang baru masuk status near expiry / expired.
+// Setiap status hanya di-alert sekali per lot (dicatat di stock_lots.alerted_status).
+func expiryAlertJob(repo *repository.LotRepository, producer *stream.KafkaProducer, days int) worker.Job {
+	return worker.Job{
+		Name:     "lot-expiry-scan",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			// Alert dikirim sebelum tanda alerted_status di-commit; kalau Kafka gagal, scan berikutnya mengulang
+			lots, err := repo.MarkExpiryAlerts(ctx, days, func(lots []models.StockLot) error {
+				messages := make([]stream.Message, 0, len(lots))
+				for _, l := range lots {
+					alertType := event.AlertNearExpiry
+					if l.Status == models.LotStatusExpired {
+						alertType = event.AlertExpired
+					}
+					messages = append(messages, stream.Message{
+						Key: fmt.Sprintf("%d", l.ProductID),
+						Value: event.StockAlertEvent{
+							Type:        alertType,
+							ProductID:   l.ProductID,
+							ProductName: l.ProductName,
+							Stock:       l.QuantityRemaining,
+							DetectedAt:  time.Now(),
+							LotID:       l.ID,
+							LotNumber:   l.LotNumber,
+							ExpiryDate:  l.ExpiryDate,
+							LocationID:  l.LocationID,
+						},
+					})
+				}
+				return producer.SendMessages("stock-alerts", messages)
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(lots) > 0 {
+				log.Printf("[EXPIRY] %d lot baru near expiry / expired (batas %d hari)", len(lots), days)
+			}
+			return nil
+		},
+	}
+}
@@ -1,23 +1,34 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"phase3-api-architecture/internal/event"
 	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/health"
 	"phase3-api-architecture/pkg/search"
+	"phase3-api-architecture/pkg/stream"
+	"phase3-api-architecture/repository"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/IBM/sarama"
 	"github.com/elastic/go-elasticsearch/v7"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -34,6 +45,80 @@ func main() {
 	groupID := "inventory-worker-group"
 	esClient := search.InitES(esAddress)
 
+	// Postgres untuk job terjadwal (low stock, digest, dll)
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Panicf("[KAFKA-WORKER] Error opening database: %v", err)
+	}
+	defer db.Close()
+
+	// Migrasi index dijalankan satu replica saja (advisory lock), replica lain menunggu lalu
+	// mendapati alias sudah di versi terbaru. Tanpa template & alias, index pertama akan dibuat
+	// ES dengan mapping tebakan, jadi wajib berhasil.
+	err = worker.Exclusive(context.Background(), db, "es-product-index", func(ctx context.Context) error {
+		if err := search.EnsureProductIndex(ctx, esClient); err != nil {
+			return err
+		}
+		// Dokumen lama belum punya field suggest; gagal di sini cukup dicatat, autocomplete fallback ke Postgres
+		if n, err := search.BackfillSuggest(ctx, esClient); err != nil {
+			log.Printf("[KAFKA-WORKER] Backfill suggest gagal: %v", err)
+		} else if n > 0 {
+			log.Printf("[KAFKA-WORKER] Backfill suggest: %d produk", n)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("[KAFKA-WORKER] Index produk: %v", err)
+	}
+
+	// Redis hanya untuk hapus cache produk API setelah job mengubah produk (harga terjadwal)
+	redisHost, redisPort := os.Getenv("REDIS_HOST"), os.Getenv("REDIS_PORT")
+	if redisHost == "" {
+		redisHost = "redis"
+	}
+	if redisPort == "" {
+		redisPort = "6379"
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("%s:%s", redisHost, redisPort)})
+	defer rdb.Close()
+
+	// Producer untuk event yang dihasilkan worker (stock-alerts)
+	producer := stream.NewKafkaProducer(brokerList)
+	defer producer.Close()
+
+	// Zona waktu job harian (digest, purge), default WIB. Image worker tidak membawa tzdata,
+	// jadi database zona waktu di-embed lewat time/tzdata.
+	timezone := os.Getenv("SCHEDULER_TIMEZONE")
+	if timezone == "" {
+		timezone = "Asia/Jakarta"
+	}
+	schedulerLoc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Fatalf("[KAFKA-WORKER] SCHEDULER_TIMEZONE tidak valid: %v", err)
+	}
+
+	digestHour := 7 // Default kirim digest jam 7 pagi
+	if v, err := strconv.Atoi(os.Getenv("DIGEST_HOUR")); err == nil {
+		digestHour = v
+	}
+
+	retentionDays := 30 // Default produk di trash dipurge setelah 30 hari
+	if v, err := strconv.Atoi(os.Getenv("PRODUCT_TRASH_RETENTION_DAYS")); err == nil && v > 0 {
+		retentionDays = v
+	}
+
+	expiryDays := models.DefaultNearExpiryDays // Lot dianggap near expiry N hari sebelum tanggal kedaluwarsa
+	if v, err := strconv.Atoi(os.Getenv("EXPIRY_ALERT_DAYS")); err == nil && v >= 0 {
+		expiryDays = v
+	}
+
+	rollupDays := 2 // Default rollup penjualan dihitung ulang untuk 2 hari terakhir
+	if v, err := strconv.Atoi(os.Getenv("REPORT_ROLLUP_DAYS")); err == nil && v >= 0 {
+		rollupDays = v
+	}
+
 	// 2. Setup Sarama Config
 	config := sarama.NewConfig()
 	config.Version = sarama.V2_1_0_0
@@ -42,7 +127,22 @@ func main() {
 
 	// Inject ES Client ke Handler
 	consumer := &ConsumerHandler{
-		esClient: esClient,
+		esClient:      esClient,
+		products:      &repository.ProductRepository{DB: db},
+		bulkActions:   500,
+		bulkBytes:     5 << 20,
+		flushInterval: time.Second,
+	}
+	if v, err := strconv.Atoi(os.Getenv("ES_BULK_ACTIONS")); err == nil && v > 0 {
+		consumer.bulkActions = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("ES_BULK_FLUSH_MS")); err == nil && v > 0 {
+		consumer.flushInterval = time.Duration(v) * time.Millisecond
+	}
+
+	healthPort := os.Getenv("HEALTH_PORT")
+	if healthPort == "" {
+		healthPort = "8081"
 	}
 
 	// 3. Init Consumer Group
@@ -58,6 +158,20 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
 
+	// Job terjadwal
+	replenishmentRepo := &repository.ReplenishmentRepository{DB: db}
+	userRepo := &repository.UserRepository{DB: db}
+	scheduler := &worker.Scheduler{DB: db}
+	scheduler.Add(lowStockJob(replenishmentRepo, producer))
+	scheduler.Add(reorderDigestJob(replenishmentRepo, userRepo, digestHour, schedulerLoc))
+	scheduler.Add(scheduledPriceJob(&repository.PriceRepository{DB: db, Redis: rdb}, producer))
+	scheduler.Add(expiryAlertJob(&repository.LotRepository{DB: db}, producer, expiryDays))
+	scheduler.Add(purgeTrashJob(&repository.ProductRepository{DB: db}, time.Duration(retentionDays)*24*time.Hour, schedulerLoc))
+	reportRepo := &repository.ReportRepository{DB: db}
+	scheduler.Add(salesRollupJob(reportRepo, rollupDays))
+	scheduler.Add(stockSnapshotJob(reportRepo))
+	scheduler.Start(ctx, wg)
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -74,6 +188,36 @@ func main() {
 		}
 	}()
 
+	// Health server untuk probe K8s
+	healthChecker := health.NewChecker(2*time.Second, 5*time.Second)
+	healthChecker.Register("consumer_group", consumer.membershipCheck)
+	healthChecker.Register("postgres", db.PingContext)
+	healthChecker.Register("elasticsearch", func(ctx context.Context) error {
+		res, err := esClient.Ping(esClient.Ping.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			return fmt.Errorf("elasticsearch ping: %s", res.Status())
+		}
+		return nil
+	})
+
+	healthMux := http.NewServeMux()
+	healthMux.HandleFunc("GET /livez", health.LivenessHandler)
+	healthMux.HandleFunc("GET /readyz", healthChecker.ReadinessHandler)
+	healthSrv := &http.Server{
+		Addr:        ":" + healthPort,
+		Handler:     healthMux,
+		ReadTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := healthSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("[KAFKA-WORKER] Health server error: %v", err)
+		}
+	}()
+
 	log.Println("🚀 Kafka Worker Started! Listening to 'checkout-events'...")
 
 	<-sigterm // Block disini sampai ada CTRL+C
@@ -82,6 +226,10 @@ func main() {
 	cancel()  // Beritahu semua goroutine untuk berhenti
 	wg.Wait() // Tunggu sampai cleanup selesai
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	healthSrv.Shutdown(shutdownCtx)
+
 	if err = client.Close(); err != nil {
 		log.Panicf("[KAFKA-WORKER] Error closing client: %v", err)
 	}
@@ -97,128 +245,270 @@ type AuditLog struct {
 // --- CONSUMER HANDLER ---
 
 type ConsumerHandler struct {
-	esClient *elasticsearch.Client // Worker punya akses ke ES
+	esClient *elasticsearch.Client         // Worker punya akses ke ES
+	products *repository.ProductRepository // Jumlah terjual untuk bobot autocomplete
+
+	// Batas batch _bulk per partisi: jumlah aksi, ukuran body, dan jeda flush
+	bulkActions   int
+	bulkBytes     int
+	flushInterval time.Duration
+
+	// true selama worker tergabung di consumer group (antara Setup dan Cleanup)
+	member atomic.Bool
 }
 
 func (h *ConsumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.member.Store(true)
 	log.Println("[KAFKA-WORKER] Partition assigned")
 	return nil
 }
 
 func (h *ConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	h.member.Store(false)
 	log.Println("[KAFKA-WORKER] Partition revoked")
 	return nil
 }
 
+func (h *ConsumerHandler) membershipCheck(ctx context.Context) error {
+	if !h.member.Load() {
+		return errors.New("worker belum tergabung di consumer group")
+	}
+	return nil
+}
+
+// Jeda maksimal antar percobaan flush saat ES tidak bisa menerima batch
+const maxFlushBackoff = 30 * time.Second
+
+// ConsumeClaim memproses pesan satu partisi. Aksi ES dikumpulkan ke _bulk dan dikirim per
+// jumlah / ukuran (h.bulkActions, h.bulkBytes) atau per waktu (h.flushInterval). Offset baru
+// ditandai setelah batch yang memuat pesan tsb diterima ES, jadi kalau worker mati di tengah
+// batch pesannya dibaca ulang.
+//
+// Flush yang gagal dicoba lagi di sini sampai berhasil. Return error dari ConsumeClaim hanya
+// menutup claim ini di sarama (partisi berhenti dibaca sampai rebalance berikutnya), bukan
+// membaca ulang, jadi fungsi ini baru keluar saat session selesai.
 func (h *ConsumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for message := range claim.Messages() {
-		log.Printf("[KAFKA-WORKER] Got message topic=%s partition=%d offset=%d", message.Topic, message.Partition, message.Offset)
-
-		// Routing berdasarkan TOPIC
-		switch message.Topic {
-		case "checkout-events":
-			var task worker.TaskSendInvoice
-			if err := json.Unmarshal(message.Value, &task); err != nil {
-				processTask(task) // Logic lama kirim email
+	bulk := search.NewBulk(h.esClient, h.bulkActions, h.bulkBytes)
+	ticker := time.NewTicker(h.flushInterval)
+	defer ticker.Stop()
+
+	// Pesan terakhir yang sudah diproses tapi offset-nya belum ditandai
+	var pending *sarama.ConsumerMessage
+	// Produk yang bobot autocomplete-nya dihitung ulang saat flush (satu query per batch)
+	weights := map[int]struct{}{}
+	flush := func(ctx context.Context) error {
+		if len(weights) > 0 {
+			if err := h.addSuggestWeights(ctx, bulk, weights); err != nil {
+				return err
+			}
+		}
+		if bulk.Len() > 0 {
+			n := bulk.Len()
+			if err := bulk.Flush(ctx); err != nil {
+				return err
+			}
+			log.Printf("[ES-BULK] %d aksi terkirim (partition=%d)", n, claim.Partition())
+		}
+		if pending != nil {
+			session.MarkMessage(pending, "")
+			pending = nil
+		}
+		return nil
+	}
+	// Flush sampai berhasil dengan backoff (dobel, maksimal maxFlushBackoff). Selama menunggu
+	// pesan baru tidak dibaca dan offset tidak ditandai. Error hanya kalau session selesai.
+	flushRetry := func() error {
+		ctx := session.Context()
+		backoff := time.Second
+		for {
+			err := flush(ctx)
+			if err == nil {
+				return nil
+			}
+			log.Printf("[ES-BULK] Flush gagal (partition=%d), coba lagi dalam %s: %v", claim.Partition(), backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return err
 			}
+			backoff = min(backoff*2, maxFlushBackoff)
+		}
+	}
+	// Flush terakhir saat partisi dilepas, session context sudah dibatalkan. Kalau tetap gagal,
+	// offset tidak ditandai dan pemilik partisi berikutnya membaca ulang batch ini.
+	finalFlush := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return flush(ctx)
+	}
 
-		case "product-events":
-			var evt event.ProductEvent
-			if err := json.Unmarshal(message.Value, &evt); err != nil {
-				log.Printf("[ERROR] Gagal parse product event: %v", err)
-				continue
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return finalFlush()
+			}
+			log.Printf("[KAFKA-WORKER] Got message topic=%s partition=%d offset=%d", message.Topic, message.Partition, message.Offset)
+			h.handleMessage(message, bulk, weights)
+			pending = message
+			if bulk.Full() {
+				if err := flushRetry(); err != nil {
+					return finalFlush()
+				}
 			}
 
-			// panggil fungsi singkronisasi ke ES
-			h.syncProductToES(evt)
-		}
+		case <-ticker.C:
+			if err := flushRetry(); err != nil {
+				return finalFlush()
+			}
 
-		session.MarkMessage(message, "")
+		case <-session.Context().Done():
+			return finalFlush()
+		}
 	}
+}
 
-	return nil
+// handleMessage: routing berdasarkan TOPIC, aksi ES ditambahkan ke bulk. Produk yang bobot
+// autocomplete-nya perlu dihitung ulang dicatat di weights.
+func (h *ConsumerHandler) handleMessage(message *sarama.ConsumerMessage, bulk *search.Bulk, weights map[int]struct{}) {
+	switch message.Topic {
+	case "checkout-events":
+		var task worker.TaskSendInvoice
+		if err := json.Unmarshal(message.Value, &task); err != nil {
+			log.Printf("[ERROR] Gagal parse checkout event: %v", err)
+			return
+		}
+		processTask(task)
+		weights[task.ProductID] = struct{}{}
+
+	case "product-events":
+		var evt event.ProductEvent
+		if err := json.Unmarshal(message.Value, &evt); err != nil {
+			log.Printf("[ERROR] Gagal parse product event: %v", err)
+			return
+		}
+
+		// ID audit dari posisi pesan, jadi kalau pesan dibaca ulang log-nya tidak dobel
+		auditID := fmt.Sprintf("%s-%d-%d", message.Topic, message.Partition, message.Offset)
+		h.syncProductToES(evt, auditID, bulk, weights)
+	}
 }
 
 // Logic pemrosesan (bisa dipindah ke internal/worker/processor.go agar lebih rapi)
 func processTask(t worker.TaskSendInvoice) {
-	// Simulasi kerja berat
-	log.Printf("📧 Sending Invoice to %s for ProductID %d (Total: %d)...", t.Email, t.ProductID, t.TotalPrice)
-	time.Sleep(1 * time.Second)
-	log.Println("✅ Invoice Sent Successfully!")
+	subject := fmt.Sprintf("Invoice pembelian produk #%d", t.ProductID)
+	body := fmt.Sprintf("Terima kasih! Anda membeli produk #%d sebanyak %d dengan total %s.", t.ProductID, t.Quantity, t.TotalPrice)
+	if t.UnitName != "" {
+		body = fmt.Sprintf("Terima kasih! Anda membeli produk #%d sebanyak %d %s dengan total %s.", t.ProductID, t.UnitQuantity, t.UnitName, t.TotalPrice)
+	}
+
+	// Rincian total seperti struk: subtotal, diskon, pajak, grand total
+	if !t.Subtotal.IsZero() {
+		body += fmt.Sprintf("\n\nSubtotal: %s", t.Subtotal)
+		if !t.DiscountAmount.IsZero() {
+			body += fmt.Sprintf("\nDiskon: -%s", t.DiscountAmount)
+		}
+		if t.TaxName != "" {
+			mode := "belum termasuk"
+			if t.TaxInclusive {
+				mode = "sudah termasuk di harga"
+			}
+			body += fmt.Sprintf("\n%s (%s): %s", t.TaxName, mode, t.TaxAmount)
+		}
+		body += fmt.Sprintf("\nTotal: %s", t.TotalPrice)
+	}
+	if err := worker.SendEmail(t.Email, subject, body); err != nil {
+		log.Printf("[ERROR] Gagal kirim invoice ke %s: %v", t.Email, err)
+	}
+}
+
+// productDocument meratakan produk untuk ES: price tetap angka major unit (mapping lama integer),
+// nominal persisnya di price_minor + currency. Units & converted_prices tidak diindex karena
+// harganya object Money yang bentrok dengan mapping dinamis lama.
+func productDocument(p models.Product) map[string]interface{} {
+	doc := map[string]interface{}{}
+	data, _ := json.Marshal(p)
+	json.Unmarshal(data, &doc)
+
+	doc["price"] = p.Price.Major()
+	doc["price_minor"] = p.Price.Amount
+	doc["currency"] = p.Price.Currency
+	delete(doc, "units")
+	delete(doc, "converted_prices")
+	return doc
 }
 
-func (h *ConsumerHandler) syncProductToES(evt event.ProductEvent) {
-	ctx := context.Background()
-	indexName := "products"
+// addSuggestWeights menghitung jumlah terjual semua produk di weights dengan satu query lalu
+// memperbarui sales_count & bobot autocomplete lewat script update (tanpa index ulang dokumen).
+// Update ditaruh setelah aksi index di batch yang sama; produk yang belum ada di index (404)
+// diabaikan. weights dikosongkan kalau berhasil, kalau query gagal flush dicoba lagi.
+func (h *ConsumerHandler) addSuggestWeights(ctx context.Context, bulk *search.Bulk, weights map[int]struct{}) error {
+	ids := make([]int, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sold, err := h.products.SalesCounts(ctx, ids, time.Now().Add(-repository.SuggestSalesWindow))
+	if err != nil {
+		return fmt.Errorf("jumlah terjual %d produk: %w", len(ids), err)
+	}
+
+	for _, id := range ids {
+		err := bulk.Add(search.BulkItem{
+			Action: search.BulkUpdate,
+			Index:  search.ProductIndex,
+			ID:     strconv.Itoa(id),
+			Body: map[string]interface{}{
+				"script": map[string]interface{}{
+					"source": "ctx._source.sales_count = params.sold; if (ctx._source.suggest != null) { ctx._source.suggest.weight = params.weight }",
+					"params": map[string]interface{}{"sold": sold[id], "weight": search.SuggestWeight(sold[id])},
+				},
+			},
+		})
+		if err != nil {
+			log.Printf("[ERROR] Bulk update bobot suggest: %v", err)
+		}
+	}
+	clear(weights)
+	return nil
+}
+
+func (h *ConsumerHandler) syncProductToES(evt event.ProductEvent, auditID string, bulk *search.Bulk, weights map[int]struct{}) {
 	productID := fmt.Sprintf("%d", evt.Product.ID)
 
 	log.Printf("[ES-SYNC] Processing action %s for Product ID %s", evt.Action, productID)
 
 	switch evt.Action {
-	case event.ActionCreate, event.ActionUpdate:
-		// 1. Prepare Data
-		data, err := json.Marshal(evt.Product)
-		if err != nil {
-			log.Printf("[ERROR] Marshal JSON: %v", err)
-			return
+	case event.ActionCreate, event.ActionUpdate, event.ActionRestore:
+		// sales_count & bobot suggest diisi addSuggestWeights saat flush batch ini
+		doc := productDocument(evt.Product)
+		doc["sales_count"] = 0
+		doc["suggest"] = map[string]interface{}{
+			"input":  search.SuggestInputs(evt.Product.Name, evt.Product.SKU),
+			"weight": search.SuggestWeight(0),
 		}
 
-		// 2. Indexing (Insert/Replace)
 		// Menggunakan ID produk sebagai ID dokumen ES (Idempotent)
-		res, err := h.esClient.Index(
-			indexName,
-			bytes.NewReader(data),
-			h.esClient.Index.WithDocumentID(productID),
-			h.esClient.Index.WithContext(ctx),
-		)
-		if err != nil {
-			log.Printf("[ERROR] ES Indexing: %v", err)
+		if err := bulk.Add(search.BulkItem{Action: search.BulkIndex, Index: search.ProductIndex, ID: productID, Body: doc}); err != nil {
+			log.Printf("[ERROR] Marshal JSON: %v", err)
 			return
 		}
-		defer res.Body.Close()
-		if res.IsError() {
-			log.Printf("[ERROR] ES Indexing Response: %s", res.String())
-		} else {
-			log.Printf("✅ Product %s synced to Elasticsearch!", productID)
-		}
+		weights[evt.Product.ID] = struct{}{}
 
 	case event.ActionDelete:
-		// Delete Document
-		res, err := h.esClient.Delete(
-			indexName,
-			productID,
-			h.esClient.Delete.WithContext(ctx),
-		)
-		if err != nil {
-			log.Printf("[ERROR] ES Deleting: %v", err)
+		// 404 Not Found saat delete itu wajar, sudah diabaikan di Bulk
+		if err := bulk.Add(search.BulkItem{Action: search.BulkDelete, Index: search.ProductIndex, ID: productID}); err != nil {
+			log.Printf("[ERROR] Bulk delete produk %s: %v", productID, err)
 			return
 		}
-		defer res.Body.Close()
-		// 404 Not Found saat delete itu wajar, abaikan
-		if res.IsError() && res.StatusCode != 404 {
-			log.Printf("[ERROR] ES Delete Response: %s", res.String())
-		} else {
-			log.Printf("🗑️ Product %s deleted from Elasticsearch!", productID)
-		}
 	}
 
 	auditData := AuditLog{
 		Timestamp: time.Now(),
 		Action:    evt.Action,
 		ProductID: evt.Product.ID,
-		Payload:   evt.Product,
+		Payload:   productDocument(evt.Product),
 	}
-
-	logBytes, _ := json.Marshal(auditData)
-
-	res, err := h.esClient.Index(
-		"product-logs", // Index baru!
-		bytes.NewReader(logBytes),
-		h.esClient.Index.WithContext(ctx),
-	)
-
-	if err == nil {
-		defer res.Body.Close()
-		log.Printf("📝 Audit Log %s recorded to Elasticsearch", evt.Action)
+	if err := bulk.Add(search.BulkItem{Action: search.BulkIndex, Index: "product-logs", ID: auditID, Body: auditData}); err != nil {
+		log.Printf("[ERROR] Marshal audit log: %v", err)
 	}
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"phase3-api-architecture/internal/event"
+	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/pkg/stream"
+	"phase3-api-architecture/repository"
+	"time"
+)
+
+// scheduledPriceJob menerapkan harga terjadwal yang sudah jatuh tempo, lalu mengirim
+// 'product-events' UPDATE supaya index Elasticsearch ikut memakai harga baru
+func scheduledPriceJob(repo *repository.PriceRepository, producer *stream.KafkaProducer) worker.Job {
+	return worker.Job{
+		Name:     "scheduled-price-apply",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			applied, err := repo.ApplyDue(ctx)
+			// Produk yang sudah ter-commit tetap dikirim eventnya walaupun produk berikutnya gagal
+			for _, res := range applied {
+				evt := event.ProductEvent{
+					Action:  event.ActionUpdate,
+					Product: res.Product,
+					Changes: res.Changes,
+				}
+				if err := producer.SendMessage("product-events", fmt.Sprintf("%d", res.Product.ID), evt); err != nil {
+					log.Printf("[WARNING] Gagal kirim event harga produk %d: %v", res.Product.ID, err)
+				}
+			}
+
+			if len(applied) > 0 {
+				log.Printf("[PRICE] %d harga terjadwal diterapkan", len(applied))
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"phase3-api-architecture/internal/event"
+	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/stream"
+	"phase3-api-architecture/repository"
+	"strings"
+	"time"
+)
+
+const (
+	salesLookbackDays = 30 // periode penjualan untuk menghitung kecepatan jual
+	reorderCoverDays  = 14 // restock diharapkan cukup untuk 2 minggu
+)
+
+// lowStockJob mengirim event 'stock-alerts' untuk produk yang baru turun di bawah reorder point
+func lowStockJob(repo *repository.ReplenishmentRepository, producer *stream.KafkaProducer) worker.Job {
+	return worker.Job{
+		Name:     "low-stock-scan",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			items, err := repo.MarkLowStock(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, it := range items {
+				evt := event.StockAlertEvent{
+					Type:         event.AlertLowStock,
+					ProductID:    it.ProductID,
+					ProductName:  it.Name,
+					Stock:        it.Stock,
+					ReorderPoint: it.ReorderPoint,
+					DetectedAt:   time.Now(),
+				}
+				if err := producer.SendMessage("stock-alerts", fmt.Sprintf("%d", it.ProductID), evt); err != nil {
+					log.Printf("[WARNING] Gagal kirim stock alert produk %d: %v", it.ProductID, err)
+				}
+			}
+
+			if len(items) > 0 {
+				log.Printf("[LOW-STOCK] %d produk baru di bawah reorder point", len(items))
+			}
+			return nil
+		},
+	}
+}
+
+// reorderDigestJob (harian): buat draft PO dari kecepatan jual, lalu kirim ringkasan ke semua admin
+func reorderDigestJob(repo *repository.ReplenishmentRepository, users *repository.UserRepository, digestHour int, loc *time.Location) worker.Job {
+	return worker.Job{
+		Name:     "low-stock-digest",
+		Interval: 10 * time.Minute,
+		RunKey:   worker.DailyAt(digestHour, loc),
+		Run: func(ctx context.Context) error {
+			items, err := repo.GetLowStockItems(ctx, salesLookbackDays)
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
+
+			orders, err := repo.DraftReorders(ctx, items, salesLookbackDays, reorderCoverDays)
+			if err != nil {
+				return err
+			}
+
+			admins, err := users.GetEmailsByRole(ctx, "admin")
+			if err != nil {
+				return err
+			}
+
+			subject := fmt.Sprintf("[Inventory] %d produk perlu restock (%s)", len(items), time.Now().In(loc).Format("02 Jan 2006"))
+			body := buildLowStockDigest(items, orders)
+			for _, email := range admins {
+				if err := worker.SendEmail(email, subject, body); err != nil {
+					log.Printf("[ERROR] Gagal kirim digest ke %s: %v", email, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func buildLowStockDigest(items []models.LowStockItem, orders []models.PurchaseOrder) string {
+	var b strings.Builder
+
+	b.WriteString("Produk di bawah reorder point:\n")
+	for _, it := range items {
+		fmt.Fprintf(&b, "- #%d %s: stok %d (reorder point %d), terjual %d dalam %d hari\n",
+			it.ProductID, it.Name, it.Stock, it.ReorderPoint, it.SoldLastPeriod, salesLookbackDays)
+		if it.SupplierID == nil {
+			b.WriteString("  (belum ada supplier utama, draft PO tidak dibuat)\n")
+		}
+	}
+
+	if len(orders) > 0 {
+		b.WriteString("\nDraft purchase order yang dibuat otomatis:\n")
+		for _, po := range orders {
+			fmt.Fprintf(&b, "- PO #%d untuk supplier #%d:\n", po.ID, po.SupplierID)
+			for _, line := range po.Items {
+				fmt.Fprintf(&b, "    produk #%d x %d\n", line.ProductID, line.QuantityOrdered)
+			}
+		}
+		b.WriteString("\nSilakan review lalu kirim ke supplier lewat POST /purchase-orders/{id}/order.\n")
+	}
+
+	return b.String()
+}
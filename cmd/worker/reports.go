@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/repository"
+	"time"
+)
+
+// salesRollupJob menghitung ulang rollup penjualan harian (sales_daily) untuk beberapa hari terakhir,
+// endpoint /reports membaca dari tabel ini
+func salesRollupJob(repo *repository.ReportRepository, days int) worker.Job {
+	return worker.Job{
+		Name:     "sales-rollup",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			n, err := repo.RefreshSalesRollup(ctx, days)
+			if err != nil {
+				return err
+			}
+			log.Printf("[REPORT] rollup penjualan diperbarui (%d baris)", n)
+			return nil
+		},
+	}
+}
+
+// stockSnapshotJob menyimpan snapshot stok & harga pokok hari ini untuk laporan nilai persediaan.
+// Dijalankan berkala supaya snapshot sebuah tanggal berisi kondisi terakhir hari itu.
+func stockSnapshotJob(repo *repository.ReportRepository) worker.Job {
+	return worker.Job{
+		Name:     "stock-snapshot",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := repo.SnapshotStock(ctx)
+			return err
+		},
+	}
+}
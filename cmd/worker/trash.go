@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log"
+	"phase3-api-architecture/internal/worker"
+	"phase3-api-architecture/repository"
+	"time"
+)
+
+// purgeTrashJob (harian): hapus permanen produk di trash yang sudah lewat masa retensi
+// dan tidak direferensikan data lain. Produk yang masih punya histori tetap di trash.
+func purgeTrashJob(repo *repository.ProductRepository, retention time.Duration, loc *time.Location) worker.Job {
+	return worker.Job{
+		Name:     "product-trash-purge",
+		Interval: time.Hour,
+		RunKey:   worker.DailyAt(0, loc),
+		Run: func(ctx context.Context) error {
+			n, err := repo.PurgeDeleted(ctx, retention)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				log.Printf("[TRASH] %d produk dihapus permanen (retensi %s)", n, retention)
+			}
+			return nil
+		},
+	}
+}
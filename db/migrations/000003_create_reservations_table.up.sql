@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_reservation_user FOREIGN KEY(user_id) REFERENCES users(id),
+    CONSTRAINT fk_reservation_product FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+-- Dipakai untuk menghitung stok tersedia (stock - hold aktif) dan oleh sweeper
+CREATE INDEX IF NOT EXISTS idx_reservations_active ON reservations (product_id, expires_at) WHERE status = 'active';
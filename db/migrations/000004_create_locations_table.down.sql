@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS location_id;
+DROP TABLE IF EXISTS stock_transfer_items;
+DROP TABLE IF EXISTS stock_transfers;
+DROP TABLE IF EXISTS stock_levels;
+DROP TABLE IF EXISTS locations;
\ No newline at end of file
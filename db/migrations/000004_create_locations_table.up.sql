@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'store', -- store | warehouse
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hanya boleh ada satu lokasi default (dipakai checkout tanpa location_id)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_default ON locations (is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS stock_levels (
+    location_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (location_id, product_id),
+    CONSTRAINT fk_stock_location FOREIGN KEY(location_id) REFERENCES locations(id),
+    CONSTRAINT fk_stock_product FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_product ON stock_levels (product_id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_location_id INT NOT NULL,
+    to_location_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_transit', -- in_transit | received
+    created_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    received_at TIMESTAMP,
+    CONSTRAINT fk_transfer_from FOREIGN KEY(from_location_id) REFERENCES locations(id),
+    CONSTRAINT fk_transfer_to FOREIGN KEY(to_location_id) REFERENCES locations(id),
+    CONSTRAINT fk_transfer_user FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT chk_transfer_locations CHECK (from_location_id <> to_location_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    transfer_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (transfer_id, product_id),
+    CONSTRAINT fk_transfer_item_transfer FOREIGN KEY(transfer_id) REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transfer_item_product FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+-- Lokasi default + pindahkan stok lama ke sana.
+-- products.stock tetap dipertahankan sebagai total stok semua lokasi (kompatibel dengan client lama).
+INSERT INTO locations (code, name, type, is_default) VALUES ('MAIN', 'Toko Utama', 'store', TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO stock_levels (location_id, product_id, quantity)
+SELECT l.id, p.id, p.stock FROM products p CROSS JOIN locations l WHERE l.is_default
+ON CONFLICT DO NOTHING;
+
+-- Catat lokasi penjualan di setiap transaksi
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS location_id INT REFERENCES locations(id);
+UPDATE transactions SET location_id = (SELECT id FROM locations WHERE is_default) WHERE location_id IS NULL;
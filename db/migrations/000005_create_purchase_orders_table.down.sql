@@ -0,0 +1,7 @@
+ALTER TABLE products DROP COLUMN IF EXISTS cost_price;
+DROP TABLE IF EXISTS stock_adjustments;
+DROP TABLE IF EXISTS goods_receipt_items;
+DROP TABLE IF EXISTS goods_receipts;
+DROP TABLE IF EXISTS purchase_order_items;
+DROP TABLE IF EXISTS purchase_orders;
+DROP TABLE IF EXISTS suppliers;
\ No newline at end of file
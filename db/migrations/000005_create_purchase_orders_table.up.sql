@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS suppliers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    phone VARCHAR(30),
+    email VARCHAR(255),
+    address TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id SERIAL PRIMARY KEY,
+    supplier_id INT NOT NULL,
+    location_id INT NOT NULL, -- barang diterima di lokasi ini
+    status VARCHAR(20) NOT NULL DEFAULT 'draft', -- draft | ordered | partially_received | received
+    notes TEXT,
+    created_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_po_supplier FOREIGN KEY(supplier_id) REFERENCES suppliers(id),
+    CONSTRAINT fk_po_location FOREIGN KEY(location_id) REFERENCES locations(id),
+    CONSTRAINT fk_po_user FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON purchase_orders (status);
+
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    purchase_order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity_ordered INT NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INT NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    unit_cost INT NOT NULL CHECK (unit_cost >= 0),
+    PRIMARY KEY (purchase_order_id, product_id),
+    CONSTRAINT fk_po_item_po FOREIGN KEY(purchase_order_id) REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_po_item_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT chk_po_item_over_receipt CHECK (quantity_received <= quantity_ordered)
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INT NOT NULL,
+    location_id INT NOT NULL,
+    received_by INT NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_receipt_po FOREIGN KEY(purchase_order_id) REFERENCES purchase_orders(id),
+    CONSTRAINT fk_receipt_location FOREIGN KEY(location_id) REFERENCES locations(id),
+    CONSTRAINT fk_receipt_user FOREIGN KEY(received_by) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_items (
+    receipt_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_cost INT NOT NULL CHECK (unit_cost >= 0),
+    PRIMARY KEY (receipt_id, product_id),
+    CONSTRAINT fk_receipt_item_receipt FOREIGN KEY(receipt_id) REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_receipt_item_product FOREIGN KEY(product_id) REFERENCES products(id)
+);
+
+-- Koreksi stok manual (rusak, hilang, stock opname) selalu berupa selisih, bukan nilai absolut
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    location_id INT NOT NULL,
+    delta INT NOT NULL CHECK (delta <> 0),
+    reason VARCHAR(255) NOT NULL,
+    created_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_adjustment_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT fk_adjustment_location FOREIGN KEY(location_id) REFERENCES locations(id),
+    CONSTRAINT fk_adjustment_user FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+-- Harga pokok terakhir dari penerimaan barang
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS job_runs;
+ALTER TABLE products DROP COLUMN IF EXISTS low_stock_since;
+ALTER TABLE products DROP COLUMN IF EXISTS supplier_id;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
\ No newline at end of file
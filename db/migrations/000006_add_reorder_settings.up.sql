@@ -0,0 +1,16 @@
+-- reorder_point = 0 artinya produk tidak dipantau
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INT NOT NULL DEFAULT 0 CHECK (reorder_point >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_quantity INT NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS supplier_id INT REFERENCES suppliers(id); -- supplier utama untuk draft PO otomatis
+ALTER TABLE products ADD COLUMN IF NOT EXISTS low_stock_since TIMESTAMP; -- diisi worker, supaya alert tidak dikirim berulang
+
+-- Draft PO dari worker tidak punya pembuat (created_by NULL)
+ALTER TABLE purchase_orders ALTER COLUMN created_by DROP NOT NULL;
+
+-- Catatan eksekusi job terjadwal di worker, mencegah job harian jalan dua kali (multi replica / restart)
+CREATE TABLE IF NOT EXISTS job_runs (
+    job_name VARCHAR(100) NOT NULL,
+    run_key VARCHAR(50) NOT NULL,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (job_name, run_key)
+);
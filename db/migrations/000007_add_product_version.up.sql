@@ -0,0 +1,2 @@
+-- Optimistic concurrency: naik setiap kali produk diubah admin (dipakai sebagai ETag)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
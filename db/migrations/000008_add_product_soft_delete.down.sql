@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Soft delete: produk yang dihapus masuk "trash", dipurge worker setelah masa retensi
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_product_category;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS uq_products_barcode;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS uq_products_sku;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+ALTER TABLE products DROP COLUMN IF EXISTS barcode;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
+DROP TABLE IF EXISTS categories;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- Kategori bertingkat (parent_id NULL = kategori utama)
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_category_parent FOREIGN KEY(parent_id) REFERENCES categories(id)
+);
+
+-- Nama kategori unik di bawah parent yang sama
+CREATE UNIQUE INDEX IF NOT EXISTS uq_categories_parent_name ON categories (COALESCE(parent_id, 0), LOWER(name));
+
+-- SKU & barcode (EAN-13) opsional tapi unik, termasuk produk di trash supaya restore tidak bentrok
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS barcode VARCHAR(13);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id INT;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_products_sku') THEN
+        ALTER TABLE products ADD CONSTRAINT uq_products_sku UNIQUE (sku);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_products_barcode') THEN
+        ALTER TABLE products ADD CONSTRAINT uq_products_barcode UNIQUE (barcode);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_product_category') THEN
+        ALTER TABLE products ADD CONSTRAINT fk_product_category FOREIGN KEY(category_id) REFERENCES categories(id);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS unit_quantity;
+ALTER TABLE transactions DROP COLUMN IF EXISTS unit_id;
+DROP TABLE IF EXISTS product_units;
+ALTER TABLE products DROP COLUMN IF EXISTS base_unit;
\ No newline at end of file
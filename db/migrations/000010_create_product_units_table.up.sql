@@ -0,0 +1,25 @@
+-- Stok produk selalu disimpan dalam satuan dasar (base_unit), contoh: kg, batang, pcs
+ALTER TABLE products ADD COLUMN IF NOT EXISTS base_unit VARCHAR(20) NOT NULL DEFAULT 'pcs';
+
+-- Satuan jual (kemasan), contoh: "Karung 5kg" = 5 kg, "Bungkus isi 16" = 16 batang.
+-- Harga ditentukan per satuan (tidak harus factor x harga dasar).
+CREATE TABLE IF NOT EXISTS product_units (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    conversion_factor INT NOT NULL CHECK (conversion_factor > 0), -- jumlah satuan dasar per 1 satuan ini
+    price INT NOT NULL CHECK (price > 0),
+    sku VARCHAR(64),
+    active BOOLEAN NOT NULL DEFAULT TRUE, -- FALSE = dihapus, baris tetap ada untuk histori transaksi
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_unit_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT uq_product_units_sku UNIQUE (sku)
+);
+
+-- Nama satuan unik per produk, hanya untuk satuan yang masih aktif
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_units_name ON product_units (product_id, LOWER(name)) WHERE active;
+
+-- quantity di transaksi tetap satuan dasar, unit_quantity = jumlah dalam satuan yang dibeli
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS unit_id INT REFERENCES product_units(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS unit_quantity INT;
\ No newline at end of file
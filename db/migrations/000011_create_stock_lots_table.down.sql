@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_lot_allocations;
+DROP TABLE IF EXISTS stock_lots;
\ No newline at end of file
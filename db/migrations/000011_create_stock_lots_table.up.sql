@@ -0,0 +1,40 @@
+-- Lot / batch stok per lokasi. Stok tanpa lot (data lama, koreksi +) dianggap "untracked":
+-- stock_levels.quantity - SUM(quantity_remaining) lot di lokasi tsb.
+CREATE TABLE IF NOT EXISTS stock_lots (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    location_id INT NOT NULL,
+    lot_number VARCHAR(50),
+    expiry_date DATE, -- NULL = tidak kedaluwarsa
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    quantity_received INT NOT NULL CHECK (quantity_received > 0),
+    quantity_remaining INT NOT NULL CHECK (quantity_remaining >= 0),
+    goods_receipt_id INT,
+    source_lot_id INT, -- lot asal jika lot ini hasil transfer antar lokasi
+    alerted_status VARCHAR(20), -- status expiry terakhir yang sudah dikirim sebagai alert
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lot_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT fk_lot_location FOREIGN KEY(location_id) REFERENCES locations(id),
+    CONSTRAINT fk_lot_receipt FOREIGN KEY(goods_receipt_id) REFERENCES goods_receipts(id),
+    CONSTRAINT fk_lot_source FOREIGN KEY(source_lot_id) REFERENCES stock_lots(id)
+);
+
+-- Urutan FEFO: kedaluwarsa paling awal dulu, lot tanpa tanggal paling akhir
+CREATE INDEX IF NOT EXISTS idx_stock_lots_fefo ON stock_lots (product_id, location_id, expiry_date, received_at) WHERE quantity_remaining > 0;
+
+-- Jejak lot mana yang terpakai oleh transaksi / transfer / koreksi stok (untuk recall)
+CREATE TABLE IF NOT EXISTS stock_lot_allocations (
+    id SERIAL PRIMARY KEY,
+    lot_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    transaction_id INT,
+    transfer_id INT,
+    adjustment_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_allocation_lot FOREIGN KEY(lot_id) REFERENCES stock_lots(id),
+    CONSTRAINT fk_allocation_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+    CONSTRAINT fk_allocation_transfer FOREIGN KEY(transfer_id) REFERENCES stock_transfers(id),
+    CONSTRAINT fk_allocation_adjustment FOREIGN KEY(adjustment_id) REFERENCES stock_adjustments(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_lot_allocations_transfer ON stock_lot_allocations (transfer_id) WHERE transfer_id IS NOT NULL;
\ No newline at end of file
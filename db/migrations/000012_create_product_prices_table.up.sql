@@ -0,0 +1,23 @@
+-- Histori harga dasar produk. products.price tetap jadi harga yang berlaku saat ini,
+-- baris dengan effective_from di masa depan = harga terjadwal (diterapkan oleh worker).
+CREATE TABLE IF NOT EXISTS product_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    price INT NOT NULL CHECK (price > 0),
+    effective_from TIMESTAMP NOT NULL,
+    effective_to TIMESTAMP, -- NULL = berlaku sampai ada harga berikutnya
+    applied_at TIMESTAMP, -- kapan harga ini ditulis ke products.price, NULL = masih terjadwal
+    created_by INT, -- NULL jika perubahan tidak tercatat user-nya (contoh: lewat gRPC)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_price_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT fk_price_user FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices (product_id, effective_from DESC);
+
+-- Dipakai job worker untuk mencari harga terjadwal yang sudah jatuh tempo
+CREATE INDEX IF NOT EXISTS idx_product_prices_pending ON product_prices (effective_from) WHERE applied_at IS NULL;
+
+-- Harga saat ini dijadikan baris histori pertama
+INSERT INTO product_prices (product_id, price, effective_from, applied_at)
+SELECT id, price, COALESCE(created_at, NOW()), NOW() FROM products WHERE price > 0;
\ No newline at end of file
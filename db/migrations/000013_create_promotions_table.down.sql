@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS discount_amount;
+DROP TABLE IF EXISTS transaction_discounts;
+DROP TABLE IF EXISTS promotions;
\ No newline at end of file
@@ -0,0 +1,41 @@
+-- Promosi otomatis & kupon. Kupon = promosi yang punya code (hanya berlaku jika code dikirim saat checkout).
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    value INT NOT NULL DEFAULT 0, -- persen (1-100) atau potongan rupiah per baris
+    buy_quantity INT NOT NULL DEFAULT 0, -- khusus buy_x_get_y: beli X ...
+    get_quantity INT NOT NULL DEFAULT 0, -- ... gratis Y
+    min_quantity INT NOT NULL DEFAULT 1,
+    product_id INT, -- scope: produk tertentu
+    category_id INT, -- scope: kategori (termasuk sub-kategori). Keduanya NULL = semua produk
+    code VARCHAR(50), -- disimpan uppercase
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    usage_limit INT, -- total pemakaian, NULL = tanpa batas
+    usage_count INT NOT NULL DEFAULT 0,
+    per_user_limit INT, -- khusus kupon, NULL = tanpa batas
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_promotion_product FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT fk_promotion_category FOREIGN KEY(category_id) REFERENCES categories(id),
+    CONSTRAINT uq_promotions_code UNIQUE (code)
+);
+
+-- Diskon yang dipakai di setiap baris transaksi (juga dipakai menghitung batas per user)
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    promotion_id INT NOT NULL,
+    user_id INT NOT NULL,
+    amount INT NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_discount_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+    CONSTRAINT fk_discount_promotion FOREIGN KEY(promotion_id) REFERENCES promotions(id),
+    CONSTRAINT fk_discount_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_user ON transaction_discounts (promotion_id, user_id);
+
+-- total_price = subtotal - discount_amount
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_rate_bp;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_rate_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS subtotal;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_rate_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_rate_id;
+DROP TABLE IF EXISTS tax_rates;
\ No newline at end of file
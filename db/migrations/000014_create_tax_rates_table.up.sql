@@ -0,0 +1,38 @@
+-- Tarif pajak (PPN). rate_bp dalam basis poin: 1100 = 11%.
+-- inclusive = harga jual sudah termasuk pajak, selain itu pajak ditambahkan di atas harga.
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    rate_bp INT NOT NULL CHECK (rate_bp >= 0 AND rate_bp <= 10000),
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    rounding VARCHAR(10) NOT NULL DEFAULT 'half_up' CHECK (rounding IN ('half_up', 'down', 'up')),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE, -- dipakai jika produk & kategorinya tidak punya tarif
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hanya boleh ada satu tarif default
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tax_rates_default ON tax_rates (is_default) WHERE is_default;
+
+-- Tarif per produk, kalau kosong ikut kategori terdekat (sub-kategori dulu, lalu parent-nya)
+-- ADD CONSTRAINT tidak punya IF NOT EXISTS, jadi dicek manual supaya migrasi aman diulang
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_rate_id INT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_rate_id INT;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_product_tax_rate') THEN
+        ALTER TABLE products ADD CONSTRAINT fk_product_tax_rate FOREIGN KEY(tax_rate_id) REFERENCES tax_rates(id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_category_tax_rate') THEN
+        ALTER TABLE categories ADD CONSTRAINT fk_category_tax_rate FOREIGN KEY(tax_rate_id) REFERENCES tax_rates(id);
+    END IF;
+END $$;
+
+-- Rincian harga per baris transaksi. Tarif disalin supaya histori tidak berubah kalau tarifnya diubah.
+-- total_price = grand total: subtotal - discount_amount (+ tax_amount jika exclusive)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS subtotal INT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_rate_id INT REFERENCES tax_rates(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_rate_bp INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+
+UPDATE transactions SET subtotal = total_price + discount_amount WHERE subtotal IS NULL;
\ No newline at end of file
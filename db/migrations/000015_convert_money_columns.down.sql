@@ -0,0 +1,27 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+UPDATE promotions SET value = value / 100 WHERE type = 'fixed';
+ALTER TABLE promotions DROP COLUMN IF EXISTS currency;
+ALTER TABLE promotions ALTER COLUMN value TYPE INT;
+
+ALTER TABLE products DROP COLUMN IF EXISTS cost_currency;
+ALTER TABLE products ALTER COLUMN cost_price TYPE INT USING cost_price / 100;
+ALTER TABLE goods_receipt_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE goods_receipt_items ALTER COLUMN unit_cost TYPE INT USING unit_cost / 100;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE purchase_order_items ALTER COLUMN unit_cost TYPE INT USING unit_cost / 100;
+
+ALTER TABLE transaction_discounts ALTER COLUMN amount TYPE INT USING amount / 100;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE transactions ALTER COLUMN tax_amount TYPE INT USING tax_amount / 100;
+ALTER TABLE transactions ALTER COLUMN discount_amount TYPE INT USING discount_amount / 100;
+ALTER TABLE transactions ALTER COLUMN subtotal TYPE INT USING subtotal / 100;
+ALTER TABLE transactions ALTER COLUMN total_price TYPE INT USING total_price / 100;
+
+ALTER TABLE product_prices DROP COLUMN IF EXISTS currency;
+ALTER TABLE product_prices ALTER COLUMN price TYPE INT USING price / 100;
+ALTER TABLE product_units ALTER COLUMN price TYPE INT USING price / 100;
+
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+ALTER TABLE products ALTER COLUMN price TYPE INT USING price / 100;
\ No newline at end of file
@@ -0,0 +1,52 @@
+-- Semua nominal penjualan disimpan dalam minor unit (BIGINT) + kode mata uang ISO 4217.
+-- Data lama dalam rupiah utuh, jadi dikali 100 (IDR punya 2 digit minor unit).
+-- Kolom yang sudah BIGINT dilewati, jadi kalau file ini jalan ulang nominal tidak dikali 100 dua kali.
+-- Harga beli & harga pokok juga minor unit, dengan mata uang pembelian sendiri (bisa beda dengan harga jual).
+DO $$
+DECLARE
+    col TEXT[];
+BEGIN
+    FOREACH col SLICE 1 IN ARRAY ARRAY[
+        ['products', 'price'],
+        ['products', 'cost_price'],
+        ['product_units', 'price'],
+        ['product_prices', 'price'],
+        ['transactions', 'total_price'],
+        ['transactions', 'subtotal'],
+        ['transactions', 'discount_amount'],
+        ['transactions', 'tax_amount'],
+        ['transaction_discounts', 'amount'],
+        ['purchase_order_items', 'unit_cost'],
+        ['goods_receipt_items', 'unit_cost']
+    ] LOOP
+        IF (SELECT data_type FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = col[1] AND column_name = col[2]) <> 'bigint' THEN
+            EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE BIGINT USING %I::BIGINT * 100', col[1], col[2], col[2]);
+        END IF;
+    END LOOP;
+END $$;
+
+-- Harga satuan jual & histori harga selalu dalam mata uang produknya
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE product_prices ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- Promosi fixed: value = potongan dalam minor unit mata uang `currency`. Persentase tetap 1-100.
+-- Promosi fixed yang dibuat setelah migrasi selalu punya currency, jadi currency NULL = belum dikonversi.
+ALTER TABLE promotions ALTER COLUMN value TYPE BIGINT;
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE promotions SET value = value * 100, currency = 'IDR' WHERE type = 'fixed' AND currency IS NULL;
+
+-- Kurs manual: 1 unit base = rate unit quote, dipakai untuk menampilkan harga di mata uang lain
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    updated_by INT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base, quote),
+    CONSTRAINT fk_exchange_rate_user FOREIGN KEY(updated_by) REFERENCES users(id)
+);
\ No newline at end of file
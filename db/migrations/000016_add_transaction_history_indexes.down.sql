@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_product_created;
+DROP INDEX IF EXISTS idx_transactions_user_created;
+DROP INDEX IF EXISTS idx_transactions_created;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Index untuk histori transaksi (cursor pagination created_at DESC, id DESC)
+CREATE INDEX IF NOT EXISTS idx_transactions_created ON transactions (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created ON transactions (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_product_created ON transactions (product_id, created_at DESC, id DESC);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_snapshots;
+DROP TABLE IF EXISTS sales_daily;
+ALTER TABLE transactions DROP COLUMN IF EXISTS cost_amount;
\ No newline at end of file
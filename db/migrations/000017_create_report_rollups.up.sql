@@ -0,0 +1,41 @@
+-- HPP per transaksi dalam minor unit mata uang transaksi, dihitung dari products.cost_price saat checkout.
+-- NULL = harga pokok belum diketahui (cost_price 0) atau cost_currency beda dengan mata uang transaksi.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cost_amount BIGINT;
+
+-- Transaksi lama memakai cost_price saat ini sebagai perkiraan
+UPDATE transactions t SET cost_amount = p.cost_price * t.quantity
+FROM products p
+WHERE p.id = t.product_id AND p.cost_price > 0 AND t.currency = p.cost_currency AND t.cost_amount IS NULL;
+
+-- Rollup penjualan harian per produk, diisi ulang job worker (sales-rollup) dari transactions.
+-- Data turunan: tidak ada FK supaya bisa dihitung ulang kapan saja.
+CREATE TABLE IF NOT EXISTS sales_daily (
+    day DATE NOT NULL,
+    product_id INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    transactions INT NOT NULL,
+    quantity INT NOT NULL, -- satuan dasar
+    gross_amount BIGINT NOT NULL, -- subtotal sebelum diskon
+    discount_amount BIGINT NOT NULL,
+    tax_amount BIGINT NOT NULL,
+    total_amount BIGINT NOT NULL, -- grand total dibayar
+    revenue_amount BIGINT NOT NULL, -- grand total tanpa pajak
+    costed_quantity INT NOT NULL, -- quantity transaksi yang HPP-nya diketahui
+    costed_revenue BIGINT NOT NULL,
+    cost_amount BIGINT NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, product_id, currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_daily_product ON sales_daily (product_id, day);
+
+-- Snapshot stok fisik & harga pokok per hari (nilai terakhir hari itu), untuk valuasi persediaan di tanggal lampau
+CREATE TABLE IF NOT EXISTS stock_snapshots (
+    day DATE NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    unit_cost BIGINT NOT NULL, -- minor unit per satuan dasar, 0 = belum diketahui
+    currency CHAR(3) NOT NULL, -- mata uang unit_cost (products.cost_currency)
+    taken_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, product_id)
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_import_rows;
+DROP TABLE IF EXISTS product_imports;
\ No newline at end of file
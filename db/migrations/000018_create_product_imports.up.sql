@@ -0,0 +1,36 @@
+-- Import produk massal dari CSV / XLSX. Baris file disimpan dulu di staging (product_import_rows),
+-- lalu diproses di background per chunk: upsert berdasarkan SKU.
+CREATE TABLE IF NOT EXISTS product_imports (
+    id SERIAL PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL, -- csv / xlsx
+    status VARCHAR(20) NOT NULL DEFAULT 'uploading', -- uploading, pending, processing, completed, failed
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_count INT NOT NULL DEFAULT 0,
+    updated_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    error TEXT, -- error fatal (file rusak / gagal diproses), error per baris ada di product_import_rows
+    created_by INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    heartbeat_at TIMESTAMP, -- diperbarui setiap chunk, import yang macet diambil alih replica lain
+    finished_at TIMESTAMP,
+    CONSTRAINT fk_product_import_user FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_imports_queue ON product_imports (status, id) WHERE status IN ('pending', 'processing');
+
+CREATE TABLE IF NOT EXISTS product_import_rows (
+    import_id INT NOT NULL,
+    row_number INT NOT NULL, -- nomor baris di file (header = 1)
+    sku VARCHAR(64),
+    data JSONB, -- produk hasil parse, NULL jika baris tidak lolos validasi
+    status VARCHAR(10) NOT NULL DEFAULT 'pending', -- pending, created, updated, failed
+    product_id INT,
+    error TEXT,
+    PRIMARY KEY (import_id, row_number),
+    CONSTRAINT fk_import_row_import FOREIGN KEY(import_id) REFERENCES product_imports(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_import_rows_pending ON product_import_rows (import_id, row_number) WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS export_jobs;
\ No newline at end of file
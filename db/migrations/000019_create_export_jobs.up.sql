@@ -0,0 +1,21 @@
+-- Export besar yang dijalankan di background, hasil file disimpan di EXPORT_DIR sampai expires_at
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL, -- products / transactions
+    format VARCHAR(10) NOT NULL, -- csv / xlsx / ndjson
+    filters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, completed, failed, expired
+    row_count INT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    file_path TEXT,
+    error TEXT,
+    created_by INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    heartbeat_at TIMESTAMP, -- diperbarui setiap batch, job yang macet diambil alih replica lain
+    finished_at TIMESTAMP,
+    expires_at TIMESTAMP, -- file dihapus setelah waktu ini
+    CONSTRAINT fk_export_job_user FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_queue ON export_jobs (status, id) WHERE status IN ('pending', 'processing', 'completed');
\ No newline at end of file
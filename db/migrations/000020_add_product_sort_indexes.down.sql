@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_created_at_id;
+DROP INDEX IF EXISTS idx_products_stock_id;
+DROP INDEX IF EXISTS idx_products_price_id;
+DROP INDEX IF EXISTS idx_products_name_id;
+ALTER TABLE products ALTER COLUMN created_at DROP NOT NULL;
\ No newline at end of file
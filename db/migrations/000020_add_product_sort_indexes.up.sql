@@ -0,0 +1,9 @@
+-- created_at dipakai untuk urutan & cursor, jadi tidak boleh NULL
+UPDATE products SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE products ALTER COLUMN created_at SET NOT NULL;
+
+-- Index keyset pagination list produk (selalu ditambah id sebagai tiebreaker)
+CREATE INDEX IF NOT EXISTS idx_products_name_id ON products(name, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_price_id ON products(price, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_stock_id ON products(stock, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_created_at_id ON products(created_at, id) WHERE deleted_at IS NULL;
\ No newline at end of file
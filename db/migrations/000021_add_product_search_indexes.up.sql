@@ -0,0 +1,11 @@
+-- Pencarian produk tanpa Elasticsearch (SEARCH_BACKEND=postgres): full-text + trigram
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Config 'simple' (tanpa stemming) karena nama produk campur Indonesia / Inggris / merek
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(sku, '') || ' ' || COALESCE(barcode, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_product_variants_name;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_product_parent;
+ALTER TABLE products DROP COLUMN IF EXISTS variant_name;
+ALTER TABLE products DROP COLUMN IF EXISTS parent_id;
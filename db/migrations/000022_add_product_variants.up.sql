@@ -0,0 +1,17 @@
+-- Varian produk (rasa, ukuran, warna, ...) disimpan sebagai baris products sendiri dengan parent_id ke
+-- produk induk, jadi punya stok, SKU, barcode, harga & satuan jual sendiri. Hanya satu level:
+-- varian tidak bisa jadi induk varian lain.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS parent_id INT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS variant_name VARCHAR(50);
+
+DO $$
+BEGIN
+    -- Induk yang di-purge dari trash tidak ikut menghapus variannya
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_product_parent') THEN
+        ALTER TABLE products ADD CONSTRAINT fk_product_parent FOREIGN KEY(parent_id) REFERENCES products(id) ON DELETE SET NULL;
+    END IF;
+END $$;
+
+-- Nama varian unik per induk, hanya untuk varian yang tidak di trash
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_variants_name ON products (parent_id, LOWER(variant_name))
+    WHERE parent_id IS NOT NULL AND deleted_at IS NULL;
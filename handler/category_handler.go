@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+)
+
+type CategoryHandler struct {
+	Repo *repository.CategoryRepository
+}
+
+// GetAllCategories godoc
+// @Summary      Daftar Kategori
+// @Description  Semua kategori beserta path lengkapnya, sub-kategori tampil di bawah parent-nya
+// @Tags         Categories
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.Category}
+// @Security     BearerAuth
+// @Router       /categories [get]
+func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.Repo.GetAll(r.Context())
+	if err != nil {
+		slog.Error("list categories failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data kategori")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua kategori", categories)
+}
+
+// CreateCategory godoc
+// @Summary      Tambah Kategori (Admin Only)
+// @Description  Isi parent_id untuk membuat sub-kategori
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        request body models.Category true "Data Kategori"
+// @Success      201  {object}  utils.APIResponse{data=models.Category}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /categories [post]
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var c models.Category
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(c); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), &c); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			utils.ResponseError(w, http.StatusBadRequest, "Parent kategori tidak ditemukan")
+		case errors.Is(err, repository.ErrTaxRateNotFound):
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrCategoryExists):
+			utils.ResponseError(w, http.StatusConflict, err.Error())
+		default:
+			slog.Error("create category failed", "error", err)
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal menambahkan kategori")
+		}
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Kategori berhasil ditambahkan", c)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+)
+
+type ExchangeRateHandler struct {
+	Repo *repository.ExchangeRateRepository
+}
+
+// GetAllExchangeRates godoc
+// @Summary      Daftar Kurs
+// @Description  Kurs manual yang dipakai untuk converted_prices di detail produk (1 base = rate quote)
+// @Tags         Exchange Rates
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.ExchangeRate}
+// @Security     BearerAuth
+// @Router       /exchange-rates [get]
+func (h *ExchangeRateHandler) GetAllExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.Repo.GetAll(r.Context())
+	if err != nil {
+		slog.Error("list exchange rates failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data kurs")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua kurs", rates)
+}
+
+// SetExchangeRate godoc
+// @Summary      Simpan Kurs (Admin Only)
+// @Description  Tambah / ubah kurs base -> quote, rate berupa string desimal (contoh "0.0000625" untuk IDR -> USD).
+// @Description  Detail produk yang sudah di-cache memakai kurs baru paling lambat 10 menit kemudian.
+// @Tags         Exchange Rates
+// @Accept       json
+// @Produce      json
+// @Param        base     path    string  true  "Mata uang asal (ISO 4217)"
+// @Param        quote    path    string  true  "Mata uang tujuan (ISO 4217)"
+// @Param        request  body    models.ExchangeRateRequest  true  "Kurs"
+// @Success      200  {object}  utils.APIResponse{data=models.ExchangeRate}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /exchange-rates/{base}/{quote} [put]
+func (h *ExchangeRateHandler) SetExchangeRate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	var req models.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	rate := models.ExchangeRate{Base: r.PathValue("base"), Quote: r.PathValue("quote"), Rate: req.Rate}
+	if err := h.Repo.Upsert(r.Context(), userID, &rate); err != nil {
+		if errors.Is(err, money.ErrUnknownCurrency) || errors.Is(err, repository.ErrInvalidExchangeRate) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("set exchange rate failed", "error", err, "base", rate.Base, "quote", rate.Quote)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menyimpan kurs")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Kurs berhasil disimpan", rate)
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/export"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+	"time"
+)
+
+// Export langsung boleh jalan lama, batas tulis server (WriteTimeout) dilonggarkan sampai segini
+const exportStreamTimeout = 30 * time.Minute
+
+type ExportHandler struct {
+	Repo *repository.ExportRepository
+}
+
+// parseExportFilter membaca filter sesuai jenis export. Filter transaksi sama dengan GET /admin/transactions.
+func parseExportFilter(kind string, query url.Values) (models.ExportFilter, error) {
+	var f models.ExportFilter
+	var err error
+
+	switch kind {
+	case models.ExportProducts:
+		f.Search = query.Get("search")
+		if v := query.Get("category_id"); v != "" {
+			if f.CategoryID, err = strconv.Atoi(v); err != nil {
+				return f, errors.New("category_id tidak valid")
+			}
+		}
+		f.IncludeDeleted = query.Get("include_deleted") == "true"
+
+	case models.ExportTransactions:
+		if f.Transactions, err = parseTransactionFilter(query); err != nil {
+			return f, err
+		}
+		f.Transactions.Cursor, f.Transactions.Limit = "", 0
+		if v := query.Get("user_id"); v != "" {
+			if f.Transactions.UserID, err = strconv.Atoi(v); err != nil {
+				return f, errors.New("user_id tidak valid")
+			}
+		}
+
+	default:
+		return f, repository.ErrUnknownExport
+	}
+	return f, nil
+}
+
+// Export godoc
+// @Summary      Export Produk / Transaksi (Admin Only)
+// @Description  Stream seluruh data sebagai CSV, XLSX atau NDJSON (nominal uang berupa desimal major unit).
+// @Description  Filter products: search, category_id, include_deleted. Filter transactions sama dengan GET /admin/transactions.
+// @Description  async=true menjalankan export di background (202), status & link unduh lewat GET /admin/exports/{id}.
+// @Tags         Export
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/x-ndjson
+// @Param        kind             path   string  true   "products atau transactions"
+// @Param        format           query  string  false  "csv (default), xlsx atau ndjson"
+// @Param        async            query  bool    false  "Jalankan sebagai job background"
+// @Param        search           query  string  false  "products: nama mengandung"
+// @Param        category_id      query  int     false  "products: kategori (termasuk sub-kategori)"
+// @Param        include_deleted  query  bool    false  "products: ikut produk di trash"
+// @Param        user_id          query  int     false  "transactions: filter user"
+// @Param        product_id       query  int     false  "transactions: filter produk"
+// @Param        from             query  string  false  "transactions: mulai (RFC 3339 / YYYY-MM-DD)"
+// @Param        to               query  string  false  "transactions: sampai (RFC 3339 / YYYY-MM-DD, inklusif untuk tanggal)"
+// @Param        min_total        query  string  false  "transactions: grand total minimal"
+// @Param        max_total        query  string  false  "transactions: grand total maksimal"
+// @Param        currency         query  string  false  "transactions: mata uang min_total / max_total (default IDR)"
+// @Success      200  {file}    file
+// @Success      202  {object}  utils.APIResponse{data=models.ExportJob}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/export/{kind} [get]
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	query := r.URL.Query()
+	kind := r.PathValue("kind")
+	format := query.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !export.ValidFormat(format) {
+		utils.ResponseError(w, http.StatusBadRequest, export.ErrUnknownFormat.Error())
+		return
+	}
+	f, err := parseExportFilter(kind, query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if query.Get("async") == "true" {
+		job, err := h.Repo.CreateJob(r.Context(), userID, kind, format, f)
+		if err != nil {
+			slog.Error("create export job failed", "error", err)
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat job export")
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("/admin/exports/%d", job.ID))
+		utils.ResponseJSON(w, http.StatusAccepted, "Export sedang diproses", job)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(exportStreamTimeout))
+
+	filename := fmt.Sprintf("%s-%s.%s", kind, time.Now().Format("20060102-150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Type", export.ContentType(format))
+
+	out := &countingWriter{w: w}
+	ew, _ := export.NewWriter(format, out, kind)
+	n, err := h.Repo.Stream(r.Context(), kind, f, ew, func(int) { rc.Flush() })
+	if err != nil {
+		slog.Error("export stream failed", "kind", kind, "format", format, "rows", n, "error", err)
+		if out.n == 0 {
+			w.Header().Del("Content-Disposition")
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat export")
+			return
+		}
+		// Sebagian file sudah terkirim, putus koneksi supaya client tahu file-nya tidak utuh
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// countingWriter mencatat apakah sudah ada byte yang dikirim ke client
+type countingWriter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// GetExportJob godoc
+// @Summary      Status Export Background (Admin Only)
+// @Description  download_url terisi setelah status completed, file disimpan 24 jam
+// @Tags         Export
+// @Produce      json
+// @Param        id  path  int  true  "Export ID"
+// @Success      200  {object}  utils.APIResponse{data=models.ExportJob}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/exports/{id} [get]
+func (h *ExportHandler) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.Repo.GetJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrExportNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("get export job failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil status export")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Status export", job)
+}
+
+// DownloadExport godoc
+// @Summary      Unduh Hasil Export (Admin Only)
+// @Tags         Export
+// @Produce      octet-stream
+// @Param        id  path  int  true  "Export ID"
+// @Success      200  {file}    file
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse "Belum selesai / sudah kedaluwarsa"
+// @Security     BearerAuth
+// @Router       /admin/exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	job, file, err := h.Repo.OpenResult(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrExportNotFound):
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, repository.ErrExportNotReady):
+			utils.ResponseError(w, http.StatusConflict, err.Error())
+		default:
+			slog.Error("open export result failed", "error", err)
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuka file export")
+		}
+		return
+	}
+	defer file.Close()
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportStreamTimeout))
+	filename := fmt.Sprintf("%s-%d.%s", job.Kind, job.ID, job.Format)
+	w.Header().Set("Content-Type", export.ContentType(job.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	http.ServeContent(w, r, filename, *job.FinishedAt, file)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/url"
+	"phase3-api-architecture/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExportFilter(t *testing.T) {
+	q, _ := url.ParseQuery("search=beras&category_id=4&include_deleted=true")
+	f, err := parseExportFilter("products", q)
+	assert.NoError(t, err)
+	assert.Equal(t, "beras", f.Search)
+	assert.Equal(t, 4, f.CategoryID)
+	assert.True(t, f.IncludeDeleted)
+
+	// Cursor & limit histori tidak berlaku untuk export
+	q, _ = url.ParseQuery("user_id=3&from=2026-01-01&cursor=abc&limit=5")
+	f, err = parseExportFilter("transactions", q)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, f.Transactions.UserID)
+	assert.NotNil(t, f.Transactions.From)
+	assert.Empty(t, f.Transactions.Cursor)
+	assert.Zero(t, f.Transactions.Limit)
+
+	_, err = parseExportFilter("users", url.Values{})
+	assert.ErrorIs(t, err, repository.ErrUnknownExport)
+	q, _ = url.ParseQuery("to=kemarin")
+	_, err = parseExportFilter("transactions", q)
+	assert.Error(t, err)
+}
@@ -3,8 +3,14 @@ package handler
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math"
+	"phase3-api-architecture/models"
 	pb "phase3-api-architecture/pb/proto/inventory"
+	"phase3-api-architecture/pkg/money"
 	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -13,7 +19,22 @@ import (
 type GrpcInventoryHandler struct {
 	pb.UnimplementedInventoryServiceServer
 
-	Repo *repository.ProductRepository
+	Repo         *repository.ProductRepository
+	Locations    *repository.LocationRepository
+	Transactions *repository.TransactionRepository
+}
+
+// toPBMoney & legacyPrice: field price lama (int32 major unit) tetap diisi untuk client lama
+func toPBMoney(m money.Money) *pb.Money {
+	return &pb.Money{Currency: m.Currency, Amount: m.Amount}
+}
+
+func legacyPrice(m money.Money) int32 {
+	major := m.Major()
+	if major > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(major)
 }
 
 func (h *GrpcInventoryHandler) GetStock(ctx context.Context, req *pb.GetStockRequest) (*pb.GetStockResponse, error) {
@@ -26,9 +47,164 @@ func (h *GrpcInventoryHandler) GetStock(ctx context.Context, req *pb.GetStockReq
 		return nil, status.Error(codes.Internal, "error database")
 	}
 
+	stock := product.Stock
+	if req.LocationId != 0 {
+		stock, err = h.Locations.GetStockLevel(ctx, int(req.LocationId), product.ID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "error database")
+		}
+	}
+
 	return &pb.GetStockResponse{
-		Id:    int32(product.ID),
-		Name:  product.Name,
-		Stock: int32(product.Stock),
+		Id:      int32(product.ID),
+		Name:    product.Name,
+		Stock:   int32(stock),
+		Version: int32(product.Version),
+	}, nil
+}
+
+// UpdateProduct mengubah nama & harga dengan cek versi yang sama seperti PUT /products/{id}
+func (h *GrpcInventoryHandler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	validPrice := req.Price > 0
+	if req.PriceMoney != nil {
+		validPrice = req.PriceMoney.Amount > 0
+	}
+	if req.Name == "" || !validPrice {
+		return nil, status.Error(codes.InvalidArgument, "name wajib diisi dan price_money / price harus > 0")
+	}
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version wajib diisi")
+	}
+
+	// Ambil data lama supaya field lain (reorder point, supplier) tidak ter-reset
+	product, err := h.Repo.GetByID(ctx, int(req.Id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "produk tidak ditemukan")
+		}
+		return nil, status.Error(codes.Internal, "error database")
+	}
+
+	product.Name = req.Name
+	if req.PriceMoney != nil {
+		product.Price = money.New(req.PriceMoney.Amount, req.PriceMoney.Currency)
+	} else if product.Price, err = money.FromMajor(int64(req.Price), product.Price.Currency); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := h.Repo.Update(ctx, &product, int(req.Version)); err != nil {
+		switch {
+		case errors.Is(err, money.ErrCurrencyMismatch):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrProductNotFound):
+			return nil, status.Error(codes.NotFound, "produk tidak ditemukan")
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "error database")
+	}
+
+	return &pb.UpdateProductResponse{
+		Id:         int32(product.ID),
+		Name:       product.Name,
+		Price:      legacyPrice(product.Price),
+		Stock:      int32(product.Stock),
+		Version:    int32(product.Version),
+		PriceMoney: toPBMoney(product.Price),
 	}, nil
 }
+
+// GetProductByBarcode: lookup produk dari barcode, sama seperti GET /products/barcode/{code}
+func (h *GrpcInventoryHandler) GetProductByBarcode(ctx context.Context, req *pb.GetProductByBarcodeRequest) (*pb.ProductDetail, error) {
+	if !utils.ValidEAN13(req.Barcode) {
+		return nil, status.Error(codes.InvalidArgument, "barcode harus EAN-13 yang valid")
+	}
+
+	product, err := h.Repo.GetByBarcode(ctx, req.Barcode)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return nil, status.Error(codes.NotFound, "produk tidak ditemukan")
+		}
+		return nil, status.Error(codes.Internal, "error database")
+	}
+
+	detail := &pb.ProductDetail{
+		Id:         int32(product.ID),
+		Name:       product.Name,
+		Price:      legacyPrice(product.Price),
+		Stock:      int32(product.Stock),
+		Version:    int32(product.Version),
+		PriceMoney: toPBMoney(product.Price),
+	}
+	if product.SKU != nil {
+		detail.Sku = *product.SKU
+	}
+	if product.Barcode != nil {
+		detail.Barcode = *product.Barcode
+	}
+	if product.CategoryID != nil {
+		detail.CategoryId = int32(*product.CategoryID)
+	}
+	return detail, nil
+}
+
+// ListTransactions: histori transaksi untuk service internal, filter sama dengan GET /admin/transactions
+func (h *GrpcInventoryHandler) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	f := models.TransactionFilter{
+		UserID:    int(req.UserId),
+		ProductID: int(req.ProductId),
+		Cursor:    req.Cursor,
+		Limit:     int(req.Limit),
+	}
+	if req.Limit < 0 || req.Limit > 100 {
+		return nil, status.Error(codes.InvalidArgument, "limit harus 0-100")
+	}
+	var err error
+	if f.From, err = parseTime(req.From, false); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if f.To, err = parseTime(req.To, true); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.MinTotal != nil {
+		m := money.New(req.MinTotal.Amount, req.MinTotal.Currency)
+		f.MinTotal = &m
+	}
+	if req.MaxTotal != nil {
+		m := money.New(req.MaxTotal.Amount, req.MaxTotal.Currency)
+		f.MaxTotal = &m
+	}
+
+	page, err := h.Transactions.List(ctx, f)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "error database")
+	}
+
+	res := &pb.ListTransactionsResponse{NextCursor: page.NextCursor}
+	for _, t := range page.Items {
+		item := &pb.Transaction{
+			Id:             int32(t.ID),
+			UserId:         int32(t.UserID),
+			ProductId:      int32(t.ProductID),
+			ProductName:    t.ProductName,
+			Quantity:       int32(t.Quantity),
+			UnitName:       t.UnitName,
+			UnitQuantity:   int32(t.UnitQuantity),
+			LocationId:     int32(t.LocationID),
+			Subtotal:       toPBMoney(t.Subtotal),
+			DiscountAmount: toPBMoney(t.DiscountAmount),
+			TaxRateBp:      int32(t.TaxRateBP),
+			TaxInclusive:   t.TaxInclusive,
+			TaxAmount:      toPBMoney(t.TaxAmount),
+			TotalPrice:     toPBMoney(t.TotalPrice),
+			CreatedAt:      t.CreatedAt.Format(time.RFC3339),
+		}
+		if t.UnitID != nil {
+			item.UnitId = int32(*t.UnitID)
+		}
+		res.Transactions = append(res.Transactions, item)
+	}
+	return res, nil
+}
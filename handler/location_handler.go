@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type LocationHandler struct {
+	Repo *repository.LocationRepository
+}
+
+// GetAllLocations godoc
+// @Summary      Daftar Lokasi
+// @Description  Mengambil semua cabang warung & gudang
+// @Tags         Locations
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.Location}
+// @Failure      500  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /locations [get]
+func (h *LocationHandler) GetAllLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.Repo.GetAll(r.Context())
+	if err != nil {
+		slog.Error("list locations failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data lokasi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua lokasi", locations)
+}
+
+// CreateLocation godoc
+// @Summary      Tambah Lokasi (Admin Only)
+// @Description  Menambahkan cabang warung atau gudang baru
+// @Tags         Locations
+// @Accept       json
+// @Produce      json
+// @Param        request body models.Location true "Data Lokasi"
+// @Success      201  {object}  utils.APIResponse{data=models.Location}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /locations [post]
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var l models.Location
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(l); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), &l); err != nil {
+		slog.Error("create location failed", "error", err, "code", l.Code)
+		utils.ResponseError(w, http.StatusConflict, "Kode lokasi mungkin sudah dipakai")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Lokasi berhasil ditambahkan", l)
+}
+
+// GetProductStock godoc
+// @Summary      Stok Produk per Lokasi
+// @Description  Rincian stok produk di setiap lokasi, termasuk barang yang sedang ditransfer
+// @Tags         Locations
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {object}  utils.APIResponse{data=[]models.StockLevel}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/stock [get]
+func (h *LocationHandler) GetProductStock(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	levels, err := h.Repo.GetStockLevels(r.Context(), id)
+	if err != nil {
+		slog.Error("get stock levels failed", "error", err, "product_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil stok per lokasi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Stok per lokasi", levels)
+}
+
+// CreateTransfer godoc
+// @Summary      Transfer Stok Antar Lokasi (Admin Only)
+// @Description  Mengurangi stok lokasi asal, barang berstatus in_transit sampai diterima
+// @Tags         Locations
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TransferRequest true "Data Transfer"
+// @Success      201  {object}  utils.APIResponse{data=models.StockTransfer}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /transfers [post]
+func (h *LocationHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	var req models.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	t, err := h.Repo.CreateTransfer(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, repository.ErrExpiredStock) ||
+			errors.Is(err, repository.ErrLocationNotFound) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("create transfer failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat transfer")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Transfer dibuat, barang dalam perjalanan", t)
+}
+
+// GetTransfer godoc
+// @Summary      Detail Transfer (Admin Only)
+// @Tags         Locations
+// @Produce      json
+// @Param        id   path      int  true  "Transfer ID"
+// @Success      200  {object}  utils.APIResponse{data=models.StockTransfer}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /transfers/{id} [get]
+func (h *LocationHandler) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Transfer ID")
+		return
+	}
+
+	t, err := h.Repo.GetTransfer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTransferNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("get transfer failed", "error", err, "transfer_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil transfer")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Detail transfer", t)
+}
+
+// ReceiveTransfer godoc
+// @Summary      Terima Transfer (Admin Only)
+// @Description  Menambahkan barang in_transit ke stok lokasi tujuan
+// @Tags         Locations
+// @Produce      json
+// @Param        id   path      int  true  "Transfer ID"
+// @Success      200  {object}  utils.APIResponse{data=models.StockTransfer}
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /transfers/{id}/receive [post]
+func (h *LocationHandler) ReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Transfer ID")
+		return
+	}
+
+	t, err := h.Repo.ReceiveTransfer(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTransferNotFound):
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, repository.ErrTransferNotInTransit):
+			utils.ResponseError(w, http.StatusConflict, err.Error())
+		default:
+			slog.Error("receive transfer failed", "error", err, "transfer_id", id)
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal menerima transfer")
+		}
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Transfer diterima", t)
+}
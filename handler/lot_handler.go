@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type LotHandler struct {
+	Repo *repository.LotRepository
+}
+
+// nearExpiryDays membaca query ?days=, default models.DefaultNearExpiryDays
+func nearExpiryDays(r *http.Request) int {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 0 {
+		return models.DefaultNearExpiryDays
+	}
+	return days
+}
+
+// GetProductLots godoc
+// @Summary      Daftar Lot Produk
+// @Description  Lot yang masih ada sisanya per lokasi, urut FEFO (expiry paling dekat dulu)
+// @Tags         Products
+// @Produce      json
+// @Param        id    path      int  true   "Product ID"
+// @Param        days  query     int  false  "Batas hari status near_expiry (default 7)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.StockLot}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/lots [get]
+func (h *LotHandler) GetProductLots(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	lots, err := h.Repo.GetByProduct(r.Context(), productID, nearExpiryDays(r))
+	if err != nil {
+		slog.Error("list product lots failed", "error", err, "product_id", productID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data lot")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List lot produk", lots)
+}
+
+// GetExpiringLots godoc
+// @Summary      Laporan Stok Near Expiry & Expired (Admin Only)
+// @Description  Lot yang sudah kedaluwarsa atau akan kedaluwarsa dalam N hari
+// @Tags         Products
+// @Produce      json
+// @Param        days         query     int  false  "Batas hari near expiry (default 7)"
+// @Param        location_id  query     int  false  "Filter lokasi"
+// @Success      200  {object}  utils.APIResponse{data=[]models.StockLot}
+// @Security     BearerAuth
+// @Router       /lots/expiring [get]
+func (h *LotHandler) GetExpiringLots(w http.ResponseWriter, r *http.Request) {
+	locationID, _ := strconv.Atoi(r.URL.Query().Get("location_id"))
+
+	lots, err := h.Repo.GetExpiring(r.Context(), nearExpiryDays(r), locationID)
+	if err != nil {
+		slog.Error("list expiring lots failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data lot")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List lot near expiry & expired", lots)
+}
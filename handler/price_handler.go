@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type PriceHandler struct {
+	Repo *repository.PriceRepository
+}
+
+// priceErrorStatus memetakan error histori / jadwal harga ke HTTP status
+func priceErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound),
+		errors.Is(err, repository.ErrPriceNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, repository.ErrPriceNotScheduled):
+		return http.StatusConflict, true
+	case errors.Is(err, repository.ErrPriceNotFuture),
+		errors.Is(err, money.ErrCurrencyMismatch):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+// GetProductPrices godoc
+// @Summary      Histori & Jadwal Harga Produk
+// @Description  Semua periode harga dasar (scheduled, current, history), terbaru dulu
+// @Tags         Products
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {object}  utils.APIResponse{data=[]models.ProductPrice}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/prices [get]
+func (h *PriceHandler) GetProductPrices(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	prices, err := h.Repo.GetByProduct(r.Context(), productID)
+	if err != nil {
+		slog.Error("list product prices failed", "error", err, "product_id", productID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil histori harga")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Histori harga produk", prices)
+}
+
+// SchedulePrice godoc
+// @Summary      Jadwalkan Harga Baru (Admin Only)
+// @Description  Harga dasar berubah otomatis di effective_from (RFC 3339), diterapkan oleh worker
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int                          true  "Product ID"
+// @Param        request body    models.SchedulePriceRequest  true  "Harga & waktu berlaku"
+// @Success      201  {object}  utils.APIResponse{data=models.ProductPrice}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/prices [post]
+func (h *PriceHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SchedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	price, err := h.Repo.Schedule(r.Context(), userID, productID, req)
+	if err != nil {
+		if code, ok := priceErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("schedule price failed", "error", err, "product_id", productID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menjadwalkan harga")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Harga berhasil dijadwalkan", price)
+}
+
+// CancelScheduledPrice godoc
+// @Summary      Batalkan Jadwal Harga (Admin Only)
+// @Description  Hanya harga yang belum berlaku yang bisa dibatalkan
+// @Tags         Products
+// @Produce      json
+// @Param        id       path    int  true  "Product ID"
+// @Param        priceId  path    int  true  "Price ID"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/prices/{priceId} [delete]
+func (h *PriceHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+	priceID, err := strconv.Atoi(r.PathValue("priceId"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Price ID")
+		return
+	}
+
+	if err := h.Repo.Cancel(r.Context(), productID, priceID); err != nil {
+		if code, ok := priceErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("cancel scheduled price failed", "error", err, "price_id", priceID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membatalkan jadwal harga")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Jadwal harga dibatalkan", nil)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Grammar filter list produk: field[op]=nilai, contoh price[gte]=10000&stock[lt]=5&id[in]=1,2,3.
+// Tanpa op (price=10000) sama dengan eq. Field & op di luar daftar ini ditolak, nilai selalu
+// dibaca sesuai tipenya lalu dikirim sebagai parameter SQL.
+var productFilterOps = map[string][]string{
+	"price":      {"eq", "gt", "gte", "lt", "lte"},
+	"stock":      {"eq", "gt", "gte", "lt", "lte"},
+	"created_at": {"eq", "gt", "gte", "lt", "lte"},
+	"updated_at": {"eq", "gt", "gte", "lt", "lte"},
+	"id":         {"eq", "in"},
+}
+
+// Maksimal ID di filter id[in]
+const maxFilterIDs = 100
+
+var filterKeyPattern = regexp.MustCompile(`^([a-z_]+)\[([a-z]+)\]$`)
+
+// parseProductFilter membaca query param GET /products. Filter lanjutan langsung dinormalisasi
+// (gt/lt jadi batas inklusif, beberapa batas digabung jadi yang paling sempit, ID diurutkan)
+// supaya query yang setara menghasilkan filter (dan cache key) yang sama.
+func parseProductFilter(query url.Values) (models.ProductFilter, error) {
+	var f models.ProductFilter
+	var err error
+
+	f.Page, _ = strconv.Atoi(query.Get("page"))
+	f.Limit, _ = strconv.Atoi(query.Get("limit"))
+	f.LocationID, _ = strconv.Atoi(query.Get("location_id"))
+	f.CategoryID, _ = strconv.Atoi(query.Get("category_id"))
+	if f.Page == 0 {
+		f.Page = 1
+	}
+	if f.Limit == 0 {
+		f.Limit = 10
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	f.Search = query.Get("search")
+	f.Sort = query.Get("sort")
+	f.Order = query.Get("order")
+	f.Cursor = query.Get("cursor")
+
+	currency := strings.ToUpper(query.Get("currency"))
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if !money.Supported(currency) {
+		return f, money.ErrUnknownCurrency
+	}
+
+	var minPrice, maxPrice, minStock, maxStock *int64
+	for key, values := range query {
+		field, op := key, "eq"
+		m := filterKeyPattern.FindStringSubmatch(key)
+		if m != nil {
+			field, op = m[1], m[2]
+		}
+		ops, ok := productFilterOps[field]
+		if !ok {
+			if m != nil {
+				return f, fmt.Errorf("filter %q tidak dikenal", field)
+			}
+			continue // param biasa (page, search, dll)
+		}
+		if !slices.Contains(ops, op) {
+			return f, fmt.Errorf("operator %q tidak didukung untuk %s", op, field)
+		}
+
+		for _, v := range values {
+			switch field {
+			case "price":
+				price, err := money.Parse(v, currency)
+				if err != nil {
+					return f, fmt.Errorf("price: %w", err)
+				}
+				applyIntBound(&minPrice, &maxPrice, op, price.Amount)
+			case "stock":
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return f, errors.New("stock harus bilangan bulat")
+				}
+				applyIntBound(&minStock, &maxStock, op, int64(n))
+			case "created_at":
+				if err = applyTimeBound(&f.CreatedFrom, &f.CreatedTo, op, v); err != nil {
+					return f, err
+				}
+			case "updated_at":
+				if err = applyTimeBound(&f.UpdatedFrom, &f.UpdatedTo, op, v); err != nil {
+					return f, err
+				}
+			case "id":
+				for _, s := range strings.Split(v, ",") {
+					id, err := strconv.Atoi(strings.TrimSpace(s))
+					if err != nil || id < 1 {
+						return f, errors.New("id harus bilangan bulat positif")
+					}
+					f.IDs = append(f.IDs, id)
+				}
+			}
+		}
+	}
+
+	if query.Get("in_stock") == "true" {
+		applyIntBound(&minStock, &maxStock, "gte", 1)
+	}
+	if minStock != nil {
+		f.MinStock = ptr(int(*minStock))
+	}
+	if maxStock != nil {
+		f.MaxStock = ptr(int(*maxStock))
+	}
+	if minPrice != nil {
+		f.MinPrice = ptr(money.New(*minPrice, currency))
+	}
+	if maxPrice != nil {
+		f.MaxPrice = ptr(money.New(*maxPrice, currency))
+	}
+	if len(f.IDs) > 0 {
+		slices.Sort(f.IDs)
+		f.IDs = slices.Compact(f.IDs)
+		if len(f.IDs) > maxFilterIDs {
+			return f, fmt.Errorf("id maksimal %d", maxFilterIDs)
+		}
+	}
+	return f, nil
+}
+
+// applyIntBound mempersempit rentang [lo, hi] (inklusif) sesuai operator
+func applyIntBound(lo, hi **int64, op string, v int64) {
+	atLeast := func(n int64) {
+		if *lo == nil || n > **lo {
+			*lo = &n
+		}
+	}
+	atMost := func(n int64) {
+		if *hi == nil || n < **hi {
+			*hi = &n
+		}
+	}
+	switch op {
+	case "eq":
+		atLeast(v)
+		atMost(v)
+	case "gt":
+		atLeast(v + 1)
+	case "gte":
+		atLeast(v)
+	case "lt":
+		atMost(v - 1)
+	case "lte":
+		atMost(v)
+	}
+}
+
+// applyTimeBound mempersempit rentang waktu [from, to). Tanggal (YYYY-MM-DD) berarti satu hari
+// penuh, jadi created_at[lte]=2026-03-01 ikut seluruh tanggal 1 Maret.
+func applyTimeBound(from, to **time.Time, op, value string) error {
+	t, err := parseTime(value, false)
+	if err != nil {
+		return err
+	}
+	*t = t.UTC()
+	step := time.Microsecond // presisi timestamp postgres
+	if len(value) == len(time.DateOnly) {
+		step = 24 * time.Hour
+	}
+	atLeast := func(v time.Time) {
+		if *from == nil || v.After(**from) {
+			*from = &v
+		}
+	}
+	before := func(v time.Time) {
+		if *to == nil || v.Before(**to) {
+			*to = &v
+		}
+	}
+	switch op {
+	case "eq":
+		atLeast(*t)
+		before(t.Add(step))
+	case "gt":
+		atLeast(t.Add(step))
+	case "gte":
+		atLeast(*t)
+	case "lt":
+		before(*t)
+	case "lte":
+		before(t.Add(step))
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
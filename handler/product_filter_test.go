@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProductFilter(t *testing.T) {
+	q, _ := url.ParseQuery("price[gt]=10000&price[lte]=50000&price[lt]=40000.50&stock[gte]=0&in_stock=true" +
+		"&created_at[lte]=2026-03-01&created_at[gte]=2026-02-01T10:00:00%2B07:00&id[in]=5,3&id=3&search=Kopi")
+	f, err := parseProductFilter(q)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(1000001), f.MinPrice.Amount)
+	assert.Equal(t, int64(4000049), f.MaxPrice.Amount)
+	assert.Equal(t, "IDR", f.MaxPrice.Currency)
+	assert.Equal(t, 1, *f.MinStock)
+	assert.Nil(t, f.MaxStock)
+	assert.Equal(t, time.Date(2026, 2, 1, 3, 0, 0, 0, time.UTC), *f.CreatedFrom)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), *f.CreatedTo)
+	assert.Equal(t, []int{3, 5}, f.IDs)
+	assert.Equal(t, 1, f.Page)
+	assert.Equal(t, 10, f.Limit)
+
+	// Bentuk berbeda, batas yang sama
+	a, _ := parseProductFilter(url.Values{"stock[gt]": {"4"}})
+	b, _ := parseProductFilter(url.Values{"stock[gte]": {"5"}})
+	assert.Equal(t, a, b)
+
+	for _, bad := range []string{"password[eq]=x", "id[gte]=3", "price[gte]=abc", "created_at[lt]=kemarin", "id[in]=1,-2", "currency=XXX"} {
+		q, _ := url.ParseQuery(bad)
+		_, err := parseProductFilter(q)
+		assert.Error(t, err, bad)
+	}
+}
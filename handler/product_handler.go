@@ -1,23 +1,69 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/jsonpatch"
+	"phase3-api-architecture/pkg/money"
 	"phase3-api-architecture/pkg/resiliency"
 	"phase3-api-architecture/repository"
 	"phase3-api-architecture/utils"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+func init() {
+	// Barcode produk wajib EAN-13 dengan check digit yang benar
+	validate.RegisterValidation("ean13", func(fl validator.FieldLevel) bool {
+		return utils.ValidEAN13(fl.Field().String())
+	})
+	// Money divalidasi dari nominal minor unit-nya, jadi tag "required,gt=0" tetap berlaku
+	validate.RegisterCustomTypeFunc(func(v reflect.Value) interface{} {
+		return v.Interface().(money.Money).Amount
+	}, money.Money{})
+}
+
+// productWriteStatus memetakan error saat menyimpan produk (referensi & field unik) ke HTTP status
+func productWriteStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, repository.ErrSupplierNotFound),
+		errors.Is(err, repository.ErrCategoryNotFound),
+		errors.Is(err, repository.ErrTaxRateNotFound),
+		errors.Is(err, repository.ErrParentNotFound),
+		errors.Is(err, repository.ErrNestedVariant),
+		errors.Is(err, money.ErrCurrencyMismatch),
+		errors.Is(err, money.ErrOverflow):
+		return http.StatusBadRequest, true
+	case errors.Is(err, repository.ErrDuplicateSKU),
+		errors.Is(err, repository.ErrDuplicateBarcode),
+		errors.Is(err, repository.ErrDuplicateVariant):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
 type ProductHandler struct {
 	Repo *repository.ProductRepository
+
+	// Batas waktu GET /products/suggest per request, 0 = DefaultSuggestBudget
+	SuggestBudget time.Duration
 }
 
+// Autocomplete dipanggil tiap ketikan di POS, lebih baik kosong daripada lambat
+const DefaultSuggestBudget = 30 * time.Millisecond
+
 var validate = validator.New()
 
 func parseID(w http.ResponseWriter, r *http.Request) (int, bool) {
@@ -29,6 +75,32 @@ func parseID(w http.ResponseWriter, r *http.Request) (int, bool) {
 	return id, true
 }
 
+// productETag: versi produk dipakai sebagai strong ETag, contoh "3"
+func productETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion membaca header If-Match untuk PUT/PATCH/DELETE.
+// Header wajib ada (428). "*" berarti tanpa cek versi (0).
+// ETag yang tidak bisa dibaca (misal weak W/"..") dianggap tidak cocok (412).
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		utils.ResponseError(w, http.StatusPreconditionRequired, "Header If-Match wajib diisi (ambil ETag dari GET /products/{id})")
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 1 || strings.HasPrefix(header, "W/") {
+		utils.ResponseError(w, http.StatusPreconditionFailed, repository.ErrVersionMismatch.Error())
+		return 0, false
+	}
+	return version, true
+}
+
 func (h *ProductHandler) HandleCreateProduct(w http.ResponseWriter, r *http.Request) {
 	h.CreateProduct(w, r)
 }
@@ -40,12 +112,24 @@ func (h *ProductHandler) HandleUpdateProduct(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+func (h *ProductHandler) HandlePatchProduct(w http.ResponseWriter, r *http.Request) {
+	if id, ok := parseID(w, r); ok {
+		h.PatchProduct(w, r, id)
+	}
+}
+
 func (h *ProductHandler) HandleDeleteProduct(w http.ResponseWriter, r *http.Request) {
 	if id, ok := parseID(w, r); ok {
 		h.DeleteProduct(w, r, id)
 	}
 }
 
+func (h *ProductHandler) HandleRestoreProduct(w http.ResponseWriter, r *http.Request) {
+	if id, ok := parseID(w, r); ok {
+		h.RestoreProduct(w, r, id)
+	}
+}
+
 func (h *ProductHandler) HandleGetProductByID(w http.ResponseWriter, r *http.Request) {
 	if id, ok := parseID(w, r); ok {
 		h.GetProductByID(w, r, id)
@@ -54,47 +138,47 @@ func (h *ProductHandler) HandleGetProductByID(w http.ResponseWriter, r *http.Req
 
 // GetAllProducts godoc
 // @Summary      Ambil Semua Produk
-// @Description  Mengambil list produk dengan pagination & search
+// @Description  Mengambil list produk dengan pagination & search. Urutan selalu stabil (tiebreaker id).
+// @Description  Untuk halaman berikutnya kirim meta.next_cursor sebagai cursor; page tetap didukung untuk client lama.
+// @Description  Filter lanjutan: price[gte]=10000&price[lt]=50000&stock[gt]=0&created_at[gte]=2026-01-01&id[in]=1,2,3.
+// @Description  Tanggal (YYYY-MM-DD) berarti satu hari penuh. Stok yang difilter = stok yang ditampilkan.
 // @Tags         Products
 // @Accept       json
 // @Produce      json
-// @Param        page   query    int     false  "Halaman ke- (Default 1)"
-// @Param        limit  query    int     false  "Jumlah data (Default 10)"
-// @Param        search query    string  false  "Cari nama produk"
-// @Success      200  {object}  utils.APIResponse
+// @Param        page   query    int     false  "Halaman ke- (Default 1, diabaikan kalau ada cursor)"
+// @Param        limit  query    int     false  "Jumlah data (Default 10, maks 100)"
+// @Param        search query    string  false  "Cari nama produk (SEARCH_BACKEND=postgres: juga sku / barcode, toleran typo)"
+// @Param        location_id query int   false  "Tampilkan stok di lokasi tertentu"
+// @Param        category_id query int   false  "Filter kategori (termasuk sub-kategori)"
+// @Param        sort   query    string  false  "name, price, stock, created_at, relevance (default id, atau relevance kalau ada search dan SEARCH_BACKEND=postgres)"
+// @Param        order  query    string  false  "asc (default) atau desc"
+// @Param        cursor query    string  false  "next_cursor dari halaman sebelumnya"
+// @Param        price[gte]       query  string  false  "Filter lanjutan field[op]=nilai. field: price, stock, created_at, updated_at (op eq/gt/gte/lt/lte), id (op eq/in, contoh id[in]=1,2,3)"
+// @Param        currency         query  string  false  "Mata uang filter price (default IDR), hanya produk dengan mata uang ini"
+// @Param        in_stock         query  bool    false  "Hanya produk yang stoknya ada"
+// @Success      200  {object}  utils.APIResponse{data=[]models.Product,meta=utils.PageMeta}
+// @Failure      400  {object}  utils.APIResponse
 // @Failure      500  {object}  utils.APIResponse
 // @Security     BearerAuth
 // @Router       /products [get]
 func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-
-	pageStr := query.Get("page")
-	limitStr := query.Get("limit")
-	search := query.Get("search")
-
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	if page == 0 {
-		page = 1
-	}
-	if limit == 0 {
-		limit = 10
-	}
-
-	filter := models.ProductFilter{
-		Page:   page,
-		Limit:  limit,
-		Search: search,
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	products, err := h.Repo.GetAll(r.Context(), filter)
+	result, err := h.Repo.GetAll(r.Context(), filter)
 	if err != nil {
 		if errors.Is(err, resiliency.ErrServiceUnavailbale) {
 			w.Header().Set("Retry-After", "30") // Beritahu client coba 30 detik lagi
 			utils.ResponseError(w, http.StatusServiceUnavailable, "sistem sedang sibuk, silahkan coba beberapa saat lagi")
 			return
 		}
+		if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		// Jika error biasa (DB mati, SQL salah, dll)
 		// Log aslinya agar tau di server
@@ -103,12 +187,82 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	utils.ResponseJSON(w, http.StatusOK, "List semua produk", products)
+	utils.ResponsePage(w, http.StatusOK, "List semua produk", result.Items, productPageMeta(filter, result))
+}
+
+// productPageMeta: meta pagination list produk, page hanya untuk mode offset
+func productPageMeta(f models.ProductFilter, p models.ProductPage) utils.PageMeta {
+	meta := utils.PageMeta{
+		Limit:      f.Limit,
+		Total:      p.Total,
+		Sort:       p.Sort,
+		Order:      p.Order,
+		NextCursor: p.NextCursor,
+		HasMore:    p.NextCursor != "",
+	}
+	if f.Cursor == "" {
+		meta.Page = f.Page
+	}
+	return meta
+}
+
+// SuggestProducts godoc
+// @Summary      Autocomplete Produk (POS)
+// @Description  Saran produk dari potongan nama / SKU, toleran typo, produk laris didahulukan.
+// @Description  Pakai Elasticsearch kalau tersedia, fallback Postgres. Kalau budget waktu habis hasilnya kosong dengan timed_out=true.
+// @Tags         Products
+// @Produce      json
+// @Param        q      query  string  true   "Teks yang diketik"
+// @Param        limit  query  int     false  "Jumlah saran (default 8, maks 20)"
+// @Success      200  {object}  utils.APIResponse{data=models.SuggestResult}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/suggest [get]
+func (h *ProductHandler) SuggestProducts(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		utils.ResponseError(w, http.StatusBadRequest, "q wajib diisi")
+		return
+	}
+	if runes := []rune(q); len(runes) > 100 {
+		q = string(runes[:100])
+	}
+	limit := 8
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 20 {
+			utils.ResponseError(w, http.StatusBadRequest, "limit harus 1-20")
+			return
+		}
+		limit = n
+	}
+
+	budget := h.SuggestBudget
+	if budget <= 0 {
+		budget = DefaultSuggestBudget
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), budget)
+	defer cancel()
+
+	result, err := h.Repo.Suggest(ctx, q, limit)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.TimedOut = true
+			utils.ResponseJSON(w, http.StatusOK, "Saran produk", result)
+			return
+		}
+		slog.Error("suggest products failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil saran produk")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Saran produk", result)
 }
 
 // CreateProduct godoc
 // @Summary      Tambah Produk Baru (Admin Only)
-// @Description  Menambahkan data produk ke database
+// @Description  Menambahkan data produk ke database. Isi parent_id + variant_name untuk membuat varian
+// @Description  (stok, SKU, barcode & harga sendiri) dari produk lain; induknya harus bukan varian & mata uangnya sama.
 // @Tags         Products
 // @Accept       json
 // @Produce      json
@@ -132,6 +286,10 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.Repo.Create(r.Context(), &p); err != nil {
+		if code, ok := productWriteStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
 		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat produk")
 		return
 	}
@@ -147,6 +305,7 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        id   path      int  true  "Product ID"
 // @Success      200  {object}  utils.APIResponse
+// @Header       200  {string}  ETag  "Versi produk, kirim balik lewat If-Match saat update/hapus"
 // @Failure      404  {object}  utils.APIResponse
 // @Security     BearerAuth
 // @Router       /products/{id} [get]
@@ -157,19 +316,89 @@ func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	w.Header().Set("ETag", productETag(product.Version))
+	utils.ResponseJSON(w, http.StatusOK, "Detail produk", product)
+}
+
+// GetProductByBarcode godoc
+// @Summary      Cari Produk dari Barcode
+// @Description  Dipakai kasir saat scan barcode EAN-13
+// @Tags         Products
+// @Produce      json
+// @Param        code   path      string  true  "Barcode EAN-13"
+// @Success      200  {object}  utils.APIResponse{data=models.Product}
+// @Header       200  {string}  ETag  "Versi produk"
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/barcode/{code} [get]
+func (h *ProductHandler) GetProductByBarcode(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	if !utils.ValidEAN13(code) {
+		utils.ResponseError(w, http.StatusBadRequest, "Barcode harus EAN-13 yang valid")
+		return
+	}
+
+	product, err := h.Repo.GetByBarcode(r.Context(), code)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+			return
+		}
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data produk")
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
 	utils.ResponseJSON(w, http.StatusOK, "Detail produk", product)
 }
 
+// GetProductVariants godoc
+// @Summary      Daftar Varian Produk
+// @Description  Varian aktif produk (masing-masing produk sendiri dengan stok tersedia, SKU & barcode), urut nama varian
+// @Tags         Products
+// @Produce      json
+// @Param        id   path      int  true  "Product ID induk"
+// @Success      200  {object}  utils.APIResponse{data=[]models.Product}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/variants [get]
+func (h *ProductHandler) GetProductVariants(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	variants, err := h.Repo.GetVariants(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+			return
+		}
+		slog.Error("list product variants failed", "error", err, "product_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil varian produk")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List varian produk", variants)
+}
+
 // HandleUpdateProduct godoc
 // @Summary      Update Produk (Admin Only)
-// @Description  Mengubah data produk berdasarkan ID
+// @Description  Mengubah nama & harga produk. Field stock, base_unit, parent_id & variant_name diabaikan, stok diubah lewat goods receipt atau stock adjustment.
+// @Description  Wajib kirim header If-Match berisi ETag dari GET /products/{id} (atau "*" untuk overwrite).
 // @Tags         Products
 // @Accept       json
 // @Produce      json
-// @Param        id      path    int             true  "Product ID"
-// @Param        request body    models.Product  true  "Data Update"
+// @Param        id       path    int             true  "Product ID"
+// @Param        If-Match header  string          true  "ETag produk"
+// @Param        request  body    models.Product  true  "Data Update"
 // @Success      200     {object}  utils.APIResponse
+// @Header       200     {string}  ETag  "Versi produk setelah update"
 // @Failure      400     {object}  utils.APIResponse
+// @Failure      404     {object}  utils.APIResponse
+// @Failure      412     {object}  utils.APIResponse "Versi tidak cocok (produk sudah diubah)"
+// @Failure      428     {object}  utils.APIResponse "Header If-Match tidak ada"
 // @Security     BearerAuth
 // @Router       /products/{id} [put]
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request, id int) {
@@ -187,40 +416,192 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request, i
 		return
 	}
 
-	_, err := h.Repo.GetByID(r.Context(), id)
-	if err != nil {
-		utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.Repo.Update(r.Context(), &p); err != nil {
-		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengupdate produk")
+	if err := h.Repo.Update(r.Context(), &p, version); err != nil {
+		code, known := productWriteStatus(err)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+		case errors.Is(err, repository.ErrVersionMismatch):
+			utils.ResponseError(w, http.StatusPreconditionFailed, err.Error())
+		case known:
+			utils.ResponseError(w, code, err.Error())
+		default:
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengupdate produk")
+		}
 		return
 	}
 
+	w.Header().Set("ETag", productETag(p.Version))
 	utils.ResponseJSON(w, http.StatusOK, "Produk berhasil diupdate", p)
 }
 
+// HandlePatchProduct godoc
+// @Summary      Update Sebagian Field Produk (Admin Only)
+// @Description  Content-Type application/merge-patch+json (RFC 7396, default) atau application/json-patch+json (RFC 6902).
+// @Description  Field: name, price, stock, reorder_point, reorder_quantity, supplier_id, sku, barcode, category_id, tax_rate_id. Stock = stok fisik, perubahannya dicatat sebagai stock adjustment.
+// @Description  Hanya field yang berubah yang divalidasi & ditulis. Wajib kirim header If-Match.
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        id       path    int     true  "Product ID"
+// @Param        If-Match header  string  true  "ETag produk"
+// @Param        request  body    object  true  "Merge patch atau daftar operasi JSON Patch"
+// @Success      200     {object}  utils.APIResponse{data=models.ProductPatchResponse}
+// @Header       200     {string}  ETag  "Versi produk setelah update"
+// @Failure      400     {object}  utils.APIResponse
+// @Failure      404     {object}  utils.APIResponse
+// @Failure      409     {object}  utils.APIResponse "Operasi test gagal / stok tidak cukup"
+// @Failure      412     {object}  utils.APIResponse "Versi tidak cocok (produk sudah diubah)"
+// @Failure      415     {object}  utils.APIResponse
+// @Failure      428     {object}  utils.APIResponse "Header If-Match tidak ada"
+// @Security     BearerAuth
+// @Router       /products/{id} [patch]
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request, id int) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	var applyPatch func(doc, patch []byte) ([]byte, error)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case jsonpatch.ContentTypeJSONPatch:
+		applyPatch = jsonpatch.Apply
+	case jsonpatch.ContentTypeMergePatch, "application/json", "":
+		applyPatch = jsonpatch.MergePatch
+	default:
+		utils.ResponseError(w, http.StatusUnsupportedMediaType, "Content-Type harus "+jsonpatch.ContentTypeMergePatch+" atau "+jsonpatch.ContentTypeJSONPatch)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	changes, err := h.Repo.Patch(r.Context(), userID, id, version, func(cur models.ProductFields) (models.ProductFields, error) {
+		return patchProductFields(cur, body, applyPatch)
+	})
+	if err != nil {
+		code, known := productWriteStatus(err)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+		case errors.Is(err, repository.ErrVersionMismatch):
+			utils.ResponseError(w, http.StatusPreconditionFailed, err.Error())
+		case errors.Is(err, jsonpatch.ErrTestFailed),
+			errors.Is(err, repository.ErrInsufficientStock):
+			utils.ResponseError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, jsonpatch.ErrInvalidPatch):
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		case known:
+			utils.ResponseError(w, code, err.Error())
+		default:
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengupdate produk")
+		}
+		return
+	}
+
+	product, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data produk")
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	utils.ResponseJSON(w, http.StatusOK, "Produk berhasil diupdate", models.ProductPatchResponse{
+		Product: product,
+		Changes: changes,
+	})
+}
+
+// patchProductFields menerapkan patch ke dokumen produk lalu memvalidasi field yang berubah saja.
+// Field di luar ProductFields (id, version, dll) ditolak.
+func patchProductFields(cur models.ProductFields, patch []byte, applyPatch func(doc, patch []byte) ([]byte, error)) (models.ProductFields, error) {
+	var next models.ProductFields
+
+	doc, err := json.Marshal(cur)
+	if err != nil {
+		return next, err
+	}
+	patched, err := applyPatch(doc, patch)
+	if err != nil {
+		return next, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&next); err != nil {
+		return next, fmt.Errorf("%w: %v", jsonpatch.ErrInvalidPatch, err)
+	}
+
+	if fields := changedStructFields(cur.Diff(next)); len(fields) > 0 {
+		if err := validate.StructPartial(next, fields...); err != nil {
+			return next, fmt.Errorf("%w: %v", jsonpatch.ErrInvalidPatch, err)
+		}
+	}
+	return next, nil
+}
+
+// changedStructFields memetakan nama JSON dari diff ke nama field struct untuk StructPartial
+func changedStructFields(changes []models.FieldChange) []string {
+	changed := map[string]bool{}
+	for _, c := range changes {
+		changed[c.Field] = true
+	}
+
+	t := reflect.TypeOf(models.ProductFields{})
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if changed[tag] {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	return names
+}
+
 // HandleDeleteProduct godoc
 // @Summary      Hapus Produk (Admin Only)
-// @Description  Menghapus produk dari database
+// @Description  Memindahkan produk ke trash (soft delete). Bisa di-restore sampai dipurge worker. Wajib kirim header If-Match seperti update.
 // @Tags         Products
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Product ID"
+// @Param        id       path    int     true  "Product ID"
+// @Param        If-Match header  string  true  "ETag produk"
 // @Success      200  {object}  utils.APIResponse
 // @Failure      404  {object}  utils.APIResponse
+// @Failure      412  {object}  utils.APIResponse "Versi tidak cocok (produk sudah diubah)"
+// @Failure      428  {object}  utils.APIResponse "Header If-Match tidak ada"
 // @Security     BearerAuth
 // @Router       /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request, id int) {
-	_, err := h.Repo.GetByID(r.Context(), id)
-	if err != nil {
-		utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.Repo.Delete(r.Context(), id); err != nil {
-		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menghapus produk")
+	if err := h.Repo.Delete(r.Context(), id, version); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ditemukan")
+		case errors.Is(err, repository.ErrVersionMismatch):
+			utils.ResponseError(w, http.StatusPreconditionFailed, err.Error())
+		default:
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal menghapus produk")
+		}
 		return
 	}
 
@@ -228,14 +609,73 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request, i
 
 }
 
+// GetTrashProducts godoc
+// @Summary      Daftar Produk di Trash (Admin Only)
+// @Description  Produk yang sudah di-soft delete, terbaru lebih dulu
+// @Tags         Products
+// @Produce      json
+// @Param        page   query    int     false  "Halaman ke- (Default 1)"
+// @Param        limit  query    int     false  "Jumlah data (Default 10, max 100)"
+// @Param        search query    string  false  "Cari nama produk"
+// @Success      200  {object}  utils.APIResponse{data=[]models.Product}
+// @Security     BearerAuth
+// @Router       /products/trash [get]
+func (h *ProductHandler) GetTrashProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter := models.ProductFilter{Page: page, Limit: limit, Search: query.Get("search")}
+	products, err := h.Repo.GetDeleted(r.Context(), filter)
+	if err != nil {
+		fmt.Printf("[ERROR] Failed to fetch trash: %v\n", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data trash")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List produk di trash", products)
+}
+
+// RestoreProduct godoc
+// @Summary      Restore Produk dari Trash (Admin Only)
+// @Tags         Products
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {object}  utils.APIResponse{data=models.Product}
+// @Header       200  {string}  ETag  "Versi produk setelah restore"
+// @Failure      404  {object}  utils.APIResponse "Produk tidak ada di trash"
+// @Security     BearerAuth
+// @Router       /products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request, id int) {
+	product, err := h.Repo.Restore(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, "Produk tidak ada di trash")
+			return
+		}
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal me-restore produk")
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	utils.ResponseJSON(w, http.StatusOK, "Produk berhasil di-restore", product)
+}
+
 // / HandleCheckout godoc
 // @Summary      Beli Produk
-// @Description  User membeli produk (mengurangi stok dan catat transaksi)
+// @Description  User membeli produk (mengurangi stok dan catat transaksi). variant_id = varian dari /products/{id}/variants.
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
 // @Param        request body models.CheckoutRequest true "Data Pembelian"  <-- Pastikan model ini ada
-// @Success      200  {object}  utils.APIResponse
+// @Success      200  {object}  utils.APIResponse{data=models.CheckoutResult}
 // @Failure      400  {object}  utils.APIResponse
 // @Security     BearerAuth
 // @Router       /checkout [post]
@@ -262,10 +702,11 @@ func (h *ProductHandler) HandleCheckout(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.Repo.Checkout(r.Context(), userID, userEmail, req); err != nil {
+	result, err := h.Repo.Checkout(r.Context(), userID, userEmail, req)
+	if err != nil {
 		utils.ResponseError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.ResponseJSON(w, http.StatusOK, "Pembelian berhasil, invoice akan dikirim via email", nil)
+	utils.ResponseJSON(w, http.StatusOK, "Pembelian berhasil, invoice akan dikirim via email", result)
 }
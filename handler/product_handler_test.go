@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/jsonpatch"
+	"phase3-api-architecture/pkg/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	cases := []struct {
+		header  string
+		version int
+		ok      bool
+		code    int
+	}{
+		{header: `"3"`, version: 3, ok: true},
+		{header: "*", version: 0, ok: true},
+		{header: "", ok: false, code: http.StatusPreconditionRequired},
+		{header: `W/"3"`, ok: false, code: http.StatusPreconditionFailed},
+		{header: `"abc"`, ok: false, code: http.StatusPreconditionFailed},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPut, "/products/1", nil)
+		if c.header != "" {
+			req.Header.Set("If-Match", c.header)
+		}
+		rr := httptest.NewRecorder()
+
+		version, ok := ifMatchVersion(rr, req)
+
+		assert.Equal(t, c.ok, ok, c.header)
+		if c.ok {
+			assert.Equal(t, c.version, version, c.header)
+		} else {
+			assert.Equal(t, c.code, rr.Code, c.header)
+		}
+	}
+}
+
+func TestPatchProductFields(t *testing.T) {
+	cur := models.ProductFields{Name: "Kopi", Price: money.New(1500000, "IDR"), Stock: 5}
+
+	// Merge patch: set stok ke 0 & ubah harga saja
+	next, err := patchProductFields(cur, []byte(`{"stock":0,"price":12000}`), jsonpatch.MergePatch)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductFields{Name: "Kopi", Price: money.New(1200000, "IDR"), Stock: 0}, next)
+
+	// JSON Patch dengan nilai yang tidak valid untuk field yang diubah
+	_, err = patchProductFields(cur, []byte(`[{"op":"replace","path":"/price","value":0}]`), jsonpatch.Apply)
+	assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+
+	// Field read-only tidak boleh di-patch
+	_, err = patchProductFields(cur, []byte(`{"version":9}`), jsonpatch.MergePatch)
+	assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+}
+
+func TestSuggestProductsValidation(t *testing.T) {
+	h := &ProductHandler{}
+	for _, target := range []string{"/products/suggest", "/products/suggest?q=%20%20", "/products/suggest?q=kopi&limit=50"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		h.SuggestProducts(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/pkg/xlsx"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportSize    = 20 << 20 // 20 MB
+	maxImportRows    = 50000
+	importStageBatch = 500
+)
+
+// importColumns: kolom yang dikenali di header file import (nama = field JSON produk)
+var importColumns = map[string]bool{
+	"sku": true, "name": true, "price": true, "currency": true, "stock": true, "base_unit": true, "barcode": true,
+	"category_id": true, "tax_rate_id": true, "reorder_point": true, "reorder_quantity": true, "supplier_id": true,
+}
+
+var errInvalidImportFile = errors.New("file import tidak valid")
+
+type ProductImportHandler struct {
+	Repo *repository.ProductImportRepository
+}
+
+// rowReader: sumber baris file import (csv.Reader / xlsx.Reader)
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// newCSVReader mendeteksi pemisah dari baris header: Excel dengan locale Indonesia menyimpan CSV pakai ';'
+func newCSVReader(r io.Reader) *csv.Reader {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(4096)
+	if i := strings.IndexByte(string(header), '\n'); i >= 0 {
+		header = header[:i]
+	}
+
+	cr := csv.NewReader(br)
+	if strings.Count(string(header), ";") > strings.Count(string(header), ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	return cr
+}
+
+// parseImportHeader memetakan nama kolom -> index. sku, name & price wajib ada.
+func parseImportHeader(cells []string) (map[string]int, error) {
+	cols := map[string]int{}
+	for i, c := range cells {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(c, "\ufeff")))
+		if name == "" {
+			continue
+		}
+		if !importColumns[name] {
+			return nil, fmt.Errorf("%w: kolom %q tidak dikenal", errInvalidImportFile, name)
+		}
+		if _, dup := cols[name]; dup {
+			return nil, fmt.Errorf("%w: kolom %q muncul dua kali", errInvalidImportFile, name)
+		}
+		cols[name] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("%w: kolom %q wajib ada", errInvalidImportFile, required)
+		}
+	}
+	return cols, nil
+}
+
+// parseImportRow mengubah satu baris menjadi produk lalu memvalidasinya dengan aturan yang sama dengan CreateProduct
+func parseImportRow(cols map[string]int, cells []string) (models.Product, error) {
+	get := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(cells) {
+			return ""
+		}
+		return strings.TrimSpace(cells[i])
+	}
+	intField := func(name string) (int, error) {
+		v := get(name)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s harus bilangan bulat", name)
+		}
+		return n, nil
+	}
+	idField := func(name string) (*int, error) {
+		n, err := intField(name)
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		return &n, nil
+	}
+
+	var p models.Product
+	var err error
+	sku := get("sku")
+	if sku == "" {
+		return p, errors.New("sku wajib diisi")
+	}
+	p.SKU = &sku
+	p.Name = get("name")
+	p.BaseUnit = get("base_unit")
+	if barcode := get("barcode"); barcode != "" {
+		p.Barcode = &barcode
+	}
+
+	currency := strings.ToUpper(get("currency"))
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if get("price") == "" {
+		return p, errors.New("price wajib diisi")
+	}
+	if p.Price, err = money.Parse(get("price"), currency); err != nil {
+		return p, fmt.Errorf("price tidak valid: %w", err)
+	}
+
+	if p.Stock, err = intField("stock"); err != nil {
+		return p, err
+	}
+	if p.ReorderPoint, err = intField("reorder_point"); err != nil {
+		return p, err
+	}
+	if p.ReorderQuantity, err = intField("reorder_quantity"); err != nil {
+		return p, err
+	}
+	if p.CategoryID, err = idField("category_id"); err != nil {
+		return p, err
+	}
+	if p.TaxRateID, err = idField("tax_rate_id"); err != nil {
+		return p, err
+	}
+	if p.SupplierID, err = idField("supplier_id"); err != nil {
+		return p, err
+	}
+
+	if err := validate.Struct(p); err != nil {
+		return p, errors.New("Validation error: " + err.Error())
+	}
+	return p, nil
+}
+
+// openImportFile membuka part file upload sesuai formatnya. CSV dibaca langsung dari request (streaming),
+// XLSX (zip) butuh akses acak jadi disalin dulu ke file sementara.
+func openImportFile(part *multipart.Part, format string) (rowReader, func(), error) {
+	if format == "csv" {
+		return newCSVReader(part), func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "product-import-*.xlsx")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, part)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	xr, err := xlsx.Open(tmp, size)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: %w", errInvalidImportFile, err)
+	}
+	return xr, func() {
+		xr.Close()
+		cleanup()
+	}, nil
+}
+
+// stageImport membaca seluruh baris file, memvalidasi, lalu menyimpannya ke staging per batch
+func (h *ProductImportHandler) stageImport(r *http.Request, importID int, rows rowReader) error {
+	header, err := rows.Read()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("%w: file kosong", errInvalidImportFile)
+		}
+		return fmt.Errorf("%w: %w", errInvalidImportFile, err)
+	}
+	cols, err := parseImportHeader(header)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]int{} // SKU -> baris pertama yang memakainya
+	batch := make([]models.ProductImportRow, 0, importStageBatch)
+	total := 0
+	for rowNumber := 2; ; rowNumber++ {
+		cells, err := rows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: baris %d: %w", errInvalidImportFile, rowNumber, err)
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue // baris kosong dilewati
+		}
+		if total++; total > maxImportRows {
+			return fmt.Errorf("%w: maksimal %d baris per file", errInvalidImportFile, maxImportRows)
+		}
+
+		row := models.ProductImportRow{Row: rowNumber}
+		p, err := parseImportRow(cols, cells)
+		if p.SKU != nil {
+			row.SKU = *p.SKU
+		}
+		switch {
+		case err != nil:
+			row.Error = err.Error()
+		case seen[row.SKU] != 0:
+			row.Error = fmt.Sprintf("SKU sama dengan baris %d", seen[row.SKU])
+		default:
+			seen[row.SKU] = rowNumber
+			row.Product = &p
+		}
+
+		if batch = append(batch, row); len(batch) == importStageBatch {
+			if err := h.Repo.StageRows(r.Context(), importID, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		return h.Repo.StageRows(r.Context(), importID, batch)
+	}
+	return nil
+}
+
+// importFileError memetakan error saat membaca / menyimpan file import ke HTTP status
+func importFileError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		utils.ResponseError(w, http.StatusRequestEntityTooLarge, "Ukuran file maksimal 20 MB")
+	case errors.Is(err, errInvalidImportFile):
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error("product import upload failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal memproses file import")
+	}
+}
+
+// ImportProducts godoc
+// @Summary      Import Produk dari CSV / XLSX (Admin Only)
+// @Description  Upload file (field "file") berisi header: sku, name, price wajib; currency, stock, base_unit, barcode,
+// @Description  category_id, tax_rate_id, reorder_point, reorder_quantity, supplier_id opsional.
+// @Description  Produk di-upsert berdasarkan SKU di background: SKU baru dibuat, SKU yang sudah ada diubah seperti PUT
+// @Description  (stok & satuan dasar tidak berubah). Status & error per baris dipoll lewat GET /admin/products/imports/{id}.
+// @Tags         Products
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "File .csv atau .xlsx (maks 20 MB)"
+// @Success      202  {object}  utils.APIResponse{data=models.ProductImport}
+// @Header       202  {string}  Location  "URL status import"
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/products/import [post]
+func (h *ProductImportHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	// Upload file besar bisa lebih lama dari ReadTimeout server
+	http.NewResponseController(w).SetReadDeadline(time.Now().Add(5 * time.Minute))
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Request harus multipart/form-data dengan field file")
+		return
+	}
+	var part *multipart.Part
+	for {
+		if part, err = mr.NextPart(); err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "Field file tidak ditemukan")
+			return
+		}
+		if part.FormName() == "file" {
+			break
+		}
+	}
+	filename := filepath.Base(part.FileName())
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if format != "csv" && format != "xlsx" {
+		utils.ResponseError(w, http.StatusBadRequest, "File harus .csv atau .xlsx")
+		return
+	}
+
+	rows, closeFile, err := openImportFile(part, format)
+	if err != nil {
+		importFileError(w, err)
+		return
+	}
+	defer closeFile()
+
+	imp, err := h.Repo.Create(r.Context(), userID, filename, format)
+	if err != nil {
+		slog.Error("create product import failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat import")
+		return
+	}
+
+	if err := h.stageImport(r, imp.ID, rows); err != nil {
+		h.Repo.Fail(r.Context(), imp.ID, err.Error())
+		importFileError(w, err)
+		return
+	}
+
+	imp, err = h.Repo.Enqueue(r.Context(), imp.ID)
+	if err != nil {
+		slog.Error("enqueue product import failed", "import_id", imp.ID, "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal memproses import")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/admin/products/imports/%d", imp.ID))
+	utils.ResponseJSON(w, http.StatusAccepted, "Import sedang diproses", imp)
+}
+
+// GetProductImport godoc
+// @Summary      Status Import Produk (Admin Only)
+// @Description  Progress import beserta laporan error per baris (nomor baris sesuai file, header = 1)
+// @Tags         Products
+// @Produce      json
+// @Param        id  path  int  true  "Import ID"
+// @Success      200  {object}  utils.APIResponse{data=models.ProductImport}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/products/imports/{id} [get]
+func (h *ProductImportHandler) GetProductImport(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	imp, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("get product import failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil status import")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Status import", imp)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportRows(t *testing.T) {
+	// CSV dari Excel locale Indonesia: BOM + pemisah ';'
+	r := newCSVReader(strings.NewReader("\ufeffSKU;Name;Price;Stock;Category_ID\nBRS-5;Beras 5kg;65000,50;10;\nX;ab;1000;satu;\n"))
+
+	header, err := r.Read()
+	require.NoError(t, err)
+	cols, err := parseImportHeader(header)
+	require.NoError(t, err)
+
+	// Desimal pakai koma tidak valid, harga tetap titik
+	cells, err := r.Read()
+	require.NoError(t, err)
+	_, err = parseImportRow(cols, cells)
+	assert.ErrorContains(t, err, "price tidak valid")
+
+	cells, err = r.Read()
+	require.NoError(t, err)
+	_, err = parseImportRow(cols, cells)
+	assert.EqualError(t, err, "stock harus bilangan bulat")
+
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	p, err := parseImportRow(cols, []string{"BRS-5", "Beras 5kg", "65000.50", "10", "3"})
+	require.NoError(t, err)
+	assert.Equal(t, "BRS-5", *p.SKU)
+	assert.Equal(t, int64(6500050), p.Price.Amount)
+	assert.Equal(t, "IDR", p.Price.Currency)
+	assert.Equal(t, 10, p.Stock)
+	assert.Equal(t, 3, *p.CategoryID)
+
+	// Aturan validator sama dengan CreateProduct (name min 3)
+	_, err = parseImportRow(cols, []string{"X", "ab", "1000"})
+	assert.ErrorContains(t, err, "Validation error")
+
+	_, err = parseImportHeader([]string{"sku", "name"})
+	assert.ErrorIs(t, err, errInvalidImportFile)
+	_, err = parseImportHeader([]string{"sku", "name", "price", "warna"})
+	assert.ErrorIs(t, err, errInvalidImportFile)
+}
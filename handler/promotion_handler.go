@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type PromotionHandler struct {
+	Repo *repository.PromotionRepository
+}
+
+// GetAllPromotions godoc
+// @Summary      Daftar Promosi & Kupon (Admin Only)
+// @Tags         Promotions
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.Promotion}
+// @Security     BearerAuth
+// @Router       /promotions [get]
+func (h *PromotionHandler) GetAllPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.Repo.GetAll(r.Context())
+	if err != nil {
+		slog.Error("list promotions failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data promosi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua promosi", promotions)
+}
+
+// CreatePromotion godoc
+// @Summary      Tambah Promosi / Kupon (Admin Only)
+// @Description  type: percentage (value = persen), fixed (amount = {"amount","currency"} per baris), buy_x_get_y (buy_quantity + get_quantity).
+// @Description  Isi code untuk membuat kupon, tanpa code promosi diterapkan otomatis saat checkout.
+// @Tags         Promotions
+// @Accept       json
+// @Produce      json
+// @Param        request body models.Promotion true "Data Promosi"
+// @Success      201  {object}  utils.APIResponse{data=models.Promotion}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /promotions [post]
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var p models.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(p); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), &p); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidPromotion),
+			errors.Is(err, repository.ErrProductNotFound),
+			errors.Is(err, repository.ErrCategoryNotFound):
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrDuplicateCoupon):
+			utils.ResponseError(w, http.StatusConflict, err.Error())
+		default:
+			slog.Error("create promotion failed", "error", err)
+			utils.ResponseError(w, http.StatusInternalServerError, "Gagal menambahkan promosi")
+		}
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Promosi berhasil ditambahkan", p)
+}
+
+// DeactivatePromotion godoc
+// @Summary      Nonaktifkan Promosi (Admin Only)
+// @Description  Promosi tidak berlaku lagi, diskon di transaksi lama tetap tersimpan
+// @Tags         Promotions
+// @Produce      json
+// @Param        id   path      int  true  "Promotion ID"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /promotions/{id} [delete]
+func (h *PromotionHandler) DeactivatePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Promotion ID")
+		return
+	}
+
+	if err := h.Repo.Deactivate(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrPromotionNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("deactivate promotion failed", "error", err, "promotion_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menonaktifkan promosi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Promosi dinonaktifkan", nil)
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type PurchaseHandler struct {
+	Repo *repository.PurchaseRepository
+}
+
+// purchaseErrorStatus memetakan error domain purchase ke HTTP status
+func purchaseErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, repository.ErrPurchaseOrderNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, repository.ErrPurchaseOrderStatus):
+		return http.StatusConflict, true
+	case errors.Is(err, repository.ErrSupplierNotFound),
+		errors.Is(err, repository.ErrLocationNotFound),
+		errors.Is(err, repository.ErrProductNotFound),
+		errors.Is(err, repository.ErrOverReceipt),
+		errors.Is(err, repository.ErrItemNotInOrder),
+		errors.Is(err, repository.ErrInsufficientStock),
+		errors.Is(err, repository.ErrLotNotFound),
+		errors.Is(err, repository.ErrLotPositive),
+		errors.Is(err, money.ErrCurrencyMismatch):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+func parsePurchaseOrderID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Purchase Order ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// GetAllSuppliers godoc
+// @Summary      Daftar Supplier (Admin Only)
+// @Tags         Purchasing
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.Supplier}
+// @Security     BearerAuth
+// @Router       /suppliers [get]
+func (h *PurchaseHandler) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.Repo.GetAllSuppliers(r.Context())
+	if err != nil {
+		slog.Error("list suppliers failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data supplier")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua supplier", suppliers)
+}
+
+// CreateSupplier godoc
+// @Summary      Tambah Supplier (Admin Only)
+// @Tags         Purchasing
+// @Accept       json
+// @Produce      json
+// @Param        request body models.Supplier true "Data Supplier"
+// @Success      201  {object}  utils.APIResponse{data=models.Supplier}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /suppliers [post]
+func (h *PurchaseHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var s models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(s); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.CreateSupplier(r.Context(), &s); err != nil {
+		slog.Error("create supplier failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menambahkan supplier")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Supplier berhasil ditambahkan", s)
+}
+
+// CreatePurchaseOrder godoc
+// @Summary      Buat Purchase Order (Admin Only)
+// @Description  PO dibuat dengan status draft, stok belum berubah
+// @Tags         Purchasing
+// @Accept       json
+// @Produce      json
+// @Param        request body models.PurchaseOrderRequest true "Data PO"
+// @Success      201  {object}  utils.APIResponse{data=models.PurchaseOrder}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /purchase-orders [post]
+func (h *PurchaseHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	var req models.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	po, err := h.Repo.CreateOrder(r.Context(), userID, req)
+	if err != nil {
+		if code, ok := purchaseErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("create purchase order failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat purchase order")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Purchase order dibuat", po)
+}
+
+// GetAllPurchaseOrders godoc
+// @Summary      Daftar Purchase Order (Admin Only)
+// @Tags         Purchasing
+// @Produce      json
+// @Param        status query string false "Filter status (draft, ordered, partially_received, received)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.PurchaseOrder}
+// @Security     BearerAuth
+// @Router       /purchase-orders [get]
+func (h *PurchaseHandler) GetAllPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.Repo.GetAllOrders(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("list purchase orders failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil purchase order")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List purchase order", orders)
+}
+
+// GetPurchaseOrder godoc
+// @Summary      Detail Purchase Order (Admin Only)
+// @Tags         Purchasing
+// @Produce      json
+// @Param        id   path      int  true  "Purchase Order ID"
+// @Success      200  {object}  utils.APIResponse{data=models.PurchaseOrder}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /purchase-orders/{id} [get]
+func (h *PurchaseHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePurchaseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	po, err := h.Repo.GetOrder(r.Context(), id)
+	if err != nil {
+		if code, ok := purchaseErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("get purchase order failed", "error", err, "purchase_order_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil purchase order")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Detail purchase order", po)
+}
+
+// MarkPurchaseOrderOrdered godoc
+// @Summary      Kirim PO ke Supplier (Admin Only)
+// @Description  Mengubah status PO dari draft menjadi ordered
+// @Tags         Purchasing
+// @Produce      json
+// @Param        id   path      int  true  "Purchase Order ID"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /purchase-orders/{id}/order [post]
+func (h *PurchaseHandler) MarkPurchaseOrderOrdered(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePurchaseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.MarkOrdered(r.Context(), id); err != nil {
+		if code, ok := purchaseErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("mark purchase order failed", "error", err, "purchase_order_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengubah status purchase order")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Purchase order dikirim ke supplier", nil)
+}
+
+// ReceiveGoods godoc
+// @Summary      Penerimaan Barang (Admin Only)
+// @Description  Menambah stok (additive) di lokasi PO dan mencatat harga pokok. Bisa diterima sebagian.
+// @Tags         Purchasing
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int                         true  "Purchase Order ID"
+// @Param        request body    models.GoodsReceiptRequest  true  "Barang yang diterima"
+// @Success      201  {object}  utils.APIResponse{data=models.GoodsReceipt}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /purchase-orders/{id}/receipts [post]
+func (h *PurchaseHandler) ReceiveGoods(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	id, ok := parsePurchaseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.GoodsReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	receipt, err := h.Repo.Receive(r.Context(), userID, id, req)
+	if err != nil {
+		if code, ok := purchaseErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("receive goods failed", "error", err, "purchase_order_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mencatat penerimaan barang")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Barang diterima, stok bertambah", receipt)
+}
+
+// AdjustStock godoc
+// @Summary      Koreksi Stok (Admin Only)
+// @Description  Menambah/mengurangi stok dengan delta + alasan (rusak, hilang, hasil opname)
+// @Tags         Purchasing
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int                            true  "Product ID"
+// @Param        request body    models.StockAdjustmentRequest  true  "Data Koreksi"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/adjustments [post]
+func (h *PurchaseHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Adjust(r.Context(), userID, id, req); err != nil {
+		if code, ok := purchaseErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("adjust stock failed", "error", err, "product_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengoreksi stok")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Stok berhasil dikoreksi", nil)
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rentang default laporan: 30 hari terakhir termasuk hari ini
+const defaultReportDays = 30
+
+type ReportHandler struct {
+	Repo *repository.ReportRepository
+}
+
+// parseReportRange membaca from / to (YYYY-MM-DD, inklusif) dan currency dari query param
+func parseReportRange(query url.Values) (models.ReportRange, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	rng := models.ReportRange{
+		From:     today.AddDate(0, 0, -(defaultReportDays - 1)),
+		To:       today,
+		Currency: money.DefaultCurrency,
+	}
+
+	for key, dst := range map[string]*time.Time{"from": &rng.From, "to": &rng.To} {
+		if v := query.Get(key); v != "" {
+			t, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				return rng, errors.New(key + " harus format YYYY-MM-DD")
+			}
+			*dst = t
+		}
+	}
+	if rng.From.After(rng.To) {
+		return rng, errors.New("from tidak boleh setelah to")
+	}
+	if v := query.Get("currency"); v != "" {
+		rng.Currency = strings.ToUpper(v)
+		if !money.Supported(rng.Currency) {
+			return rng, money.ErrUnknownCurrency
+		}
+	}
+	return rng, nil
+}
+
+// parseReportLimit: limit default 10, max 100
+func parseReportLimit(query url.Values) (int, error) {
+	v := query.Get("limit")
+	if v == "" {
+		return 10, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > 100 {
+		return 0, errors.New("limit harus 1-100")
+	}
+	return limit, nil
+}
+
+func reportPeriod(query url.Values) string {
+	if p := query.Get("period"); p != "" {
+		return p
+	}
+	return models.ReportDaily
+}
+
+// GetSalesReport godoc
+// @Summary      Laporan Penjualan (Admin Only)
+// @Description  Total penjualan per hari / minggu / bulan dari rollup harian (diperbarui worker tiap 15 menit).
+// @Tags         Reports
+// @Produce      json
+// @Param        period    query  string  false  "daily (default), weekly atau monthly"
+// @Param        from      query  string  false  "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param        to        query  string  false  "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param        currency  query  string  false  "Mata uang transaksi (default IDR)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.SalesPeriod}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/reports/sales [get]
+func (h *ReportHandler) GetSalesReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rng, err := parseReportRange(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	periods, err := h.Repo.Sales(r.Context(), reportPeriod(query), rng)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPeriod) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("sales report failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil laporan penjualan")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Laporan penjualan", periods)
+}
+
+// GetTopProducts godoc
+// @Summary      Produk Terlaris (Admin Only)
+// @Tags         Reports
+// @Produce      json
+// @Param        by        query  string  false  "quantity (default) atau revenue"
+// @Param        from      query  string  false  "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param        to        query  string  false  "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param        currency  query  string  false  "Mata uang transaksi (default IDR)"
+// @Param        limit     query  int     false  "Jumlah produk (default 10, max 100)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.ProductSales}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/reports/top-products [get]
+func (h *ReportHandler) GetTopProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rng, err := parseReportRange(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := parseReportLimit(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	by := query.Get("by")
+	if by != "" && by != "quantity" && by != "revenue" {
+		utils.ResponseError(w, http.StatusBadRequest, "by harus quantity atau revenue")
+		return
+	}
+
+	products, err := h.Repo.TopProducts(r.Context(), rng, by == "revenue", limit)
+	if err != nil {
+		slog.Error("top products report failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil produk terlaris")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Produk terlaris", products)
+}
+
+// GetSlowMovers godoc
+// @Summary      Produk Slow Moving (Admin Only)
+// @Description  Produk aktif yang masih ada stoknya dengan penjualan paling sedikit di rentang tanggal (semua mata uang)
+// @Tags         Reports
+// @Produce      json
+// @Param        from   query  string  false  "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param        to     query  string  false  "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param        limit  query  int     false  "Jumlah produk (default 10, max 100)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.SlowMover}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/reports/slow-movers [get]
+func (h *ReportHandler) GetSlowMovers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rng, err := parseReportRange(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := parseReportLimit(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := h.Repo.SlowMovers(r.Context(), rng, limit)
+	if err != nil {
+		slog.Error("slow movers report failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil produk slow moving")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Produk slow moving", items)
+}
+
+// GetGrossMargin godoc
+// @Summary      Laporan Gross Margin (Admin Only)
+// @Description  Margin kotor per periode, hanya dari transaksi yang harga pokoknya diketahui (lihat cost_coverage_bp).
+// @Tags         Reports
+// @Produce      json
+// @Param        period    query  string  false  "daily (default), weekly atau monthly"
+// @Param        from      query  string  false  "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param        to        query  string  false  "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param        currency  query  string  false  "Mata uang transaksi (default IDR)"
+// @Success      200  {object}  utils.APIResponse{data=[]models.MarginPeriod}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/reports/gross-margin [get]
+func (h *ReportHandler) GetGrossMargin(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rng, err := parseReportRange(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	periods, err := h.Repo.GrossMargin(r.Context(), reportPeriod(query), rng)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPeriod) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("gross margin report failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil laporan margin")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Laporan gross margin", periods)
+}
+
+// GetInventoryValuation godoc
+// @Summary      Nilai Persediaan (Admin Only)
+// @Description  Stok fisik x harga pokok pada tanggal tertentu. Tanggal lampau dibaca dari snapshot harian worker,
+// @Description  hari ini (default) dihitung langsung dari stok saat ini.
+// @Tags         Reports
+// @Produce      json
+// @Param        date      query  string  false  "Tanggal YYYY-MM-DD (default hari ini)"
+// @Param        currency  query  string  false  "Mata uang harga pokok (default IDR)"
+// @Success      200  {object}  utils.APIResponse{data=models.InventoryValuation}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/reports/inventory-valuation [get]
+func (h *ReportHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	currency := money.DefaultCurrency
+	if v := r.URL.Query().Get("currency"); v != "" {
+		currency = strings.ToUpper(v)
+		if !money.Supported(currency) {
+			utils.ResponseError(w, http.StatusBadRequest, money.ErrUnknownCurrency.Error())
+			return
+		}
+	}
+
+	var date *time.Time
+	if v := r.URL.Query().Get("date"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "date harus format YYYY-MM-DD")
+			return
+		}
+		date = &t
+	}
+
+	valuation, err := h.Repo.InventoryValuation(r.Context(), date, currency)
+	if err != nil {
+		if errors.Is(err, repository.ErrSnapshotNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("inventory valuation failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menghitung nilai persediaan")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Nilai persediaan", valuation)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReportRange(t *testing.T) {
+	rng, err := parseReportRange(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", rng.Currency)
+	assert.Equal(t, defaultReportDays-1, int(rng.To.Sub(rng.From).Hours()/24))
+
+	q, _ := url.ParseQuery("from=2026-01-01&to=2026-01-31&currency=usd")
+	rng, err = parseReportRange(q)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), rng.From)
+	assert.Equal(t, time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), rng.To)
+	assert.Equal(t, "USD", rng.Currency)
+
+	for _, bad := range []string{"from=2026-02-01&to=2026-01-01", "to=besok", "currency=XYZ"} {
+		q, _ := url.ParseQuery(bad)
+		_, err := parseReportRange(q)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type ReservationHandler struct {
+	Repo *repository.ReservationRepository
+}
+
+// CreateReservation godoc
+// @Summary      Hold Stok Sebelum Checkout
+// @Description  Menyisihkan stok untuk user selama ttl_minutes (default 15 menit). Gunakan reservation_id saat checkout.
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ReservationRequest true "Data Reservasi"
+// @Success      201  {object}  utils.APIResponse{data=models.Reservation}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /reservations [post]
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	var req models.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Format input salah!")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := h.Repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("create reservation failed", "error", err, "user_id", userID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membuat reservasi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Stok berhasil di-hold", res)
+}
+
+// ReleaseReservation godoc
+// @Summary      Batalkan Hold
+// @Description  Melepas hold milik user sebelum kedaluwarsa
+// @Tags         Transactions
+// @Produce      json
+// @Param        id   path      int  true  "Reservation ID"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /reservations/{id} [delete]
+func (h *ReservationHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Reservation ID")
+		return
+	}
+
+	if err := h.Repo.Release(r.Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			utils.ResponseError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		slog.Error("release reservation failed", "error", err, "reservation_id", id)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal membatalkan reservasi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Reservasi dibatalkan", nil)
+}
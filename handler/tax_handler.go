@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+)
+
+type TaxHandler struct {
+	Repo *repository.TaxRepository
+}
+
+// GetAllTaxRates godoc
+// @Summary      Daftar Tarif Pajak (Admin Only)
+// @Tags         Taxes
+// @Produce      json
+// @Success      200  {object}  utils.APIResponse{data=[]models.TaxRate}
+// @Security     BearerAuth
+// @Router       /tax-rates [get]
+func (h *TaxHandler) GetAllTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.Repo.GetAll(r.Context())
+	if err != nil {
+		slog.Error("list tax rates failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil data tarif pajak")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List semua tarif pajak", rates)
+}
+
+// CreateTaxRate godoc
+// @Summary      Tambah Tarif Pajak (Admin Only)
+// @Description  rate_bp dalam basis poin (1100 = 11%). Pasang ke produk / kategori lewat tax_rate_id,
+// @Description  atau jadikan is_default untuk produk yang tidak punya tarif.
+// @Tags         Taxes
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TaxRate true "Data Tarif Pajak"
+// @Success      201  {object}  utils.APIResponse{data=models.TaxRate}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /tax-rates [post]
+func (h *TaxHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var t models.TaxRate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validate.Struct(t); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), &t); err != nil {
+		slog.Error("create tax rate failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menambahkan tarif pajak")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Tarif pajak berhasil ditambahkan", t)
+}
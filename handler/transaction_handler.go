@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TransactionHandler struct {
+	Repo *repository.TransactionRepository
+}
+
+// parseTime menerima RFC 3339 atau tanggal saja (YYYY-MM-DD, UTC).
+// endOfDay: tanggal saja dipakai sebagai batas akhir inklusif, jadi digeser ke awal hari berikutnya.
+func parseTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("format waktu %q harus RFC 3339 atau YYYY-MM-DD", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// parseTotal membaca filter nominal desimal major unit, currency kosong = mata uang default
+func parseTotal(amount, currency string) (*money.Money, error) {
+	if amount == "" {
+		return nil, nil
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	m, err := money.Parse(amount, strings.ToUpper(currency))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// parseTransactionFilter membaca query param histori transaksi (dipakai endpoint user & admin)
+func parseTransactionFilter(query url.Values) (models.TransactionFilter, error) {
+	var f models.TransactionFilter
+	var err error
+
+	f.Cursor = query.Get("cursor")
+	if v := query.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > 100 {
+			return f, errors.New("limit harus 1-100")
+		}
+	}
+	if v := query.Get("product_id"); v != "" {
+		if f.ProductID, err = strconv.Atoi(v); err != nil {
+			return f, errors.New("product_id tidak valid")
+		}
+	}
+	if f.From, err = parseTime(query.Get("from"), false); err != nil {
+		return f, err
+	}
+	if f.To, err = parseTime(query.Get("to"), true); err != nil {
+		return f, err
+	}
+	if f.MinTotal, err = parseTotal(query.Get("min_total"), query.Get("currency")); err != nil {
+		return f, err
+	}
+	if f.MaxTotal, err = parseTotal(query.Get("max_total"), query.Get("currency")); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+func (h *TransactionHandler) list(w http.ResponseWriter, r *http.Request, f models.TransactionFilter) {
+	page, err := h.Repo.List(r.Context(), f)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			utils.ResponseError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("list transactions failed", "error", err)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil histori transaksi")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Histori transaksi", page)
+}
+
+// GetMyTransactions godoc
+// @Summary      Histori Pembelian Saya
+// @Description  Transaksi user yang sedang login, terbaru dulu. Halaman berikutnya pakai next_cursor.
+// @Tags         Transactions
+// @Produce      json
+// @Param        cursor      query  string  false  "next_cursor dari halaman sebelumnya"
+// @Param        limit       query  int     false  "Jumlah data (default 20, max 100)"
+// @Param        product_id  query  int     false  "Filter produk"
+// @Param        from        query  string  false  "Mulai (RFC 3339 / YYYY-MM-DD)"
+// @Param        to          query  string  false  "Sampai (RFC 3339 / YYYY-MM-DD, inklusif untuk tanggal)"
+// @Param        min_total   query  string  false  "Grand total minimal, contoh 50000"
+// @Param        max_total   query  string  false  "Grand total maksimal"
+// @Param        currency    query  string  false  "Mata uang untuk min_total / max_total (default IDR)"
+// @Success      200  {object}  utils.APIResponse{data=models.TransactionPage}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /me/transactions [get]
+func (h *TransactionHandler) GetMyTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		utils.ResponseError(w, http.StatusUnauthorized, "User ID tidak valid!")
+		return
+	}
+
+	f, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f.UserID = userID
+
+	h.list(w, r, f)
+}
+
+// GetAllTransactions godoc
+// @Summary      Semua Transaksi (Admin Only)
+// @Description  Histori penjualan semua user dengan filter, terbaru dulu. Halaman berikutnya pakai next_cursor.
+// @Tags         Transactions
+// @Produce      json
+// @Param        cursor      query  string  false  "next_cursor dari halaman sebelumnya"
+// @Param        limit       query  int     false  "Jumlah data (default 20, max 100)"
+// @Param        user_id     query  int     false  "Filter user"
+// @Param        product_id  query  int     false  "Filter produk"
+// @Param        from        query  string  false  "Mulai (RFC 3339 / YYYY-MM-DD)"
+// @Param        to          query  string  false  "Sampai (RFC 3339 / YYYY-MM-DD, inklusif untuk tanggal)"
+// @Param        min_total   query  string  false  "Grand total minimal, contoh 50000"
+// @Param        max_total   query  string  false  "Grand total maksimal"
+// @Param        currency    query  string  false  "Mata uang untuk min_total / max_total (default IDR)"
+// @Success      200  {object}  utils.APIResponse{data=models.TransactionPage}
+// @Failure      400  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /admin/transactions [get]
+func (h *TransactionHandler) GetAllTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f, err := parseTransactionFilter(query)
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if v := query.Get("user_id"); v != "" {
+		if f.UserID, err = strconv.Atoi(v); err != nil {
+			utils.ResponseError(w, http.StatusBadRequest, "user_id tidak valid")
+			return
+		}
+	}
+
+	h.list(w, r, f)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/url"
+	"phase3-api-architecture/pkg/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTransactionFilter(t *testing.T) {
+	q, _ := url.ParseQuery("limit=50&product_id=7&from=2026-01-01&to=2026-01-31&min_total=10000.50&currency=idr")
+	f, err := parseTransactionFilter(q)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, f.Limit)
+	assert.Equal(t, 7, f.ProductID)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *f.From)
+	// Tanggal "to" inklusif -> batas eksklusif awal hari berikutnya
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), *f.To)
+	assert.Equal(t, money.New(1000050, "IDR"), *f.MinTotal)
+	assert.Nil(t, f.MaxTotal)
+
+	for _, bad := range []string{"limit=500", "from=kemarin", "max_total=abc", "min_total=1&currency=XYZ"} {
+		q, _ := url.ParseQuery(bad)
+		_, err := parseTransactionFilter(q)
+		assert.Error(t, err, bad)
+	}
+}
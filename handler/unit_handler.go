@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/repository"
+	"phase3-api-architecture/utils"
+	"strconv"
+)
+
+type ProductUnitHandler struct {
+	Repo *repository.ProductUnitRepository
+}
+
+// unitErrorStatus memetakan error satuan produk ke HTTP status
+func unitErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound),
+		errors.Is(err, repository.ErrUnitNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, repository.ErrUnitExists),
+		errors.Is(err, repository.ErrDuplicateUnit):
+		return http.StatusConflict, true
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+func parseUnitID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("unitId"))
+	if err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid Unit ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// GetProductUnits godoc
+// @Summary      Daftar Satuan Jual Produk
+// @Description  Satuan selain satuan dasar, urut dari kemasan terkecil
+// @Tags         Products
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {object}  utils.APIResponse{data=[]models.ProductUnit}
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/units [get]
+func (h *ProductUnitHandler) GetProductUnits(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	units, err := h.Repo.GetByProduct(r.Context(), productID)
+	if err != nil {
+		if code, ok := unitErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("list product units failed", "error", err, "product_id", productID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengambil satuan produk")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "List satuan produk", units)
+}
+
+// CreateProductUnit godoc
+// @Summary      Tambah Satuan Jual (Admin Only)
+// @Description  conversion_factor = jumlah satuan dasar dalam 1 satuan ini (contoh: 1 karung = 5 kg)
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int                 true  "Product ID"
+// @Param        request body    models.ProductUnit  true  "Data Satuan"
+// @Success      201  {object}  utils.APIResponse{data=models.ProductUnit}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/units [post]
+func (h *ProductUnitHandler) CreateProductUnit(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	var u models.ProductUnit
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	u.ProductID = productID
+
+	if err := validate.Struct(u); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), &u); err != nil {
+		if code, ok := unitErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("create product unit failed", "error", err, "product_id", productID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menambahkan satuan")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusCreated, "Satuan berhasil ditambahkan", u)
+}
+
+// UpdateProductUnit godoc
+// @Summary      Update Satuan Jual (Admin Only)
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int                 true  "Product ID"
+// @Param        unitId  path    int                 true  "Unit ID"
+// @Param        request body    models.ProductUnit  true  "Data Satuan"
+// @Success      200  {object}  utils.APIResponse{data=models.ProductUnit}
+// @Failure      400  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Failure      409  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/units/{unitId} [put]
+func (h *ProductUnitHandler) UpdateProductUnit(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+	unitID, ok := parseUnitID(w, r)
+	if !ok {
+		return
+	}
+
+	var u models.ProductUnit
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	u.ID = unitID
+	u.ProductID = productID
+
+	if err := validate.Struct(u); err != nil {
+		utils.ResponseError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		return
+	}
+
+	if err := h.Repo.Update(r.Context(), &u); err != nil {
+		if code, ok := unitErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("update product unit failed", "error", err, "unit_id", unitID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal mengupdate satuan")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Satuan berhasil diupdate", u)
+}
+
+// DeleteProductUnit godoc
+// @Summary      Hapus Satuan Jual (Admin Only)
+// @Description  Satuan tidak bisa dipakai checkout lagi, histori transaksi tetap tersimpan
+// @Tags         Products
+// @Produce      json
+// @Param        id      path    int  true  "Product ID"
+// @Param        unitId  path    int  true  "Unit ID"
+// @Success      200  {object}  utils.APIResponse
+// @Failure      404  {object}  utils.APIResponse
+// @Security     BearerAuth
+// @Router       /products/{id}/units/{unitId} [delete]
+func (h *ProductUnitHandler) DeleteProductUnit(w http.ResponseWriter, r *http.Request) {
+	productID, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+	unitID, ok := parseUnitID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.Deactivate(r.Context(), productID, unitID); err != nil {
+		if code, ok := unitErrorStatus(err); ok {
+			utils.ResponseError(w, code, err.Error())
+			return
+		}
+		slog.Error("delete product unit failed", "error", err, "unit_id", unitID)
+		utils.ResponseError(w, http.StatusInternalServerError, "Gagal menghapus satuan")
+		return
+	}
+
+	utils.ResponseJSON(w, http.StatusOK, "Satuan berhasil dihapus", nil)
+}
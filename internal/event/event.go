@@ -1,16 +1,46 @@
 package event
 
-import "phase3-api-architecture/models"
+import (
+	"phase3-api-architecture/models"
+	"time"
+)
 
 // Constants untuk tipe akse
 const (
-	ActionCreate = "CREATE"
-	ActionUpdate = "UPDATE"
-	ActionDelete = "DELETE"
+	ActionCreate  = "CREATE"
+	ActionUpdate  = "UPDATE"
+	ActionDelete  = "DELETE"
+	ActionRestore = "RESTORE" // keluar dari trash, perlu di-index ulang
 )
 
 // payload yang dikirim ke kafka topic 'product-events'
 type ProductEvent struct {
 	Action  string         `json:"action"`
 	Product models.Product `json:"payload"`
+
+	// Khusus UPDATE lewat PATCH: hanya field yang berubah
+	Changes []models.FieldChange `json:"changes,omitempty"`
+}
+
+// Tipe alert untuk topic 'stock-alerts'
+const (
+	AlertLowStock   = "LOW_STOCK"
+	AlertNearExpiry = "NEAR_EXPIRY" // Lot akan kedaluwarsa dalam N hari
+	AlertExpired    = "EXPIRED"     // Lot sudah lewat tanggal kedaluwarsa
+)
+
+// payload yang dikirim ke kafka topic 'stock-alerts'
+type StockAlertEvent struct {
+	Type         string    `json:"type"`
+	ProductID    int       `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	Stock        int       `json:"stock"`
+	ReorderPoint int       `json:"reorder_point"`
+	DetectedAt   time.Time `json:"detected_at"`
+
+	// Khusus alert expiry: lot yang dimaksud, Stock = sisa di lot tersebut
+	LotID      int        `json:"lot_id,omitempty"`
+	LotNumber  string     `json:"lot_number,omitempty"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	LocationID int        `json:"location_id,omitempty"`
 }
@@ -1,11 +1,24 @@
 package worker
 
+import "phase3-api-architecture/pkg/money"
+
 type TaskSendInvoice struct {
-	UserID     int    `json:"user_id"`
-	Email      string `json:"email"`
-	ProductID  int    `json:"product_id"`
-	Quantity   int    `json:"quantity"`
-	TotalPrice int    `json:"total_price"`
+	UserID     int         `json:"user_id"`
+	Email      string      `json:"email"`
+	ProductID  int         `json:"product_id"`
+	Quantity   int         `json:"quantity"` // Dalam satuan dasar
+	TotalPrice money.Money `json:"total_price"`
+
+	// Satuan yang dibeli untuk ditampilkan di invoice, contoh 2 "Karung 5kg"
+	UnitName     string `json:"unit_name,omitempty"`
+	UnitQuantity int    `json:"unit_quantity,omitempty"`
+
+	// Rincian harga: TotalPrice = Subtotal - DiscountAmount (+ TaxAmount jika pajak tidak inclusive)
+	Subtotal       money.Money `json:"subtotal"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	TaxName        string      `json:"tax_name,omitempty"` // Kosong = tidak kena pajak
+	TaxInclusive   bool        `json:"tax_inclusive,omitempty"`
+	TaxAmount      money.Money `json:"tax_amount"`
 }
 
 const QueueInvoice = `queue:invoice_sending`
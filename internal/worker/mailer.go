@@ -0,0 +1,16 @@
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// SendEmail adalah jalur kirim email worker (invoice, digest, dll).
+// Masih simulasi: belum ada SMTP, cukup log + delay seperti kerja berat.
+func SendEmail(to, subject, body string) error {
+	log.Printf("📧 Sending email to %s | Subject: %s", to, subject)
+	log.Printf("📧 Body:\n%s", body)
+	time.Sleep(1 * time.Second)
+	log.Printf("✅ Email to %s sent successfully!", to)
+	return nil
+}
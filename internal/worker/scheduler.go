@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job adalah pekerjaan terjadwal di worker.
+// RunKey opsional: jika diisi, job hanya dijalankan sekali per key (contoh: per tanggal untuk job harian).
+type Job struct {
+	Name     string
+	Interval time.Duration
+	RunKey   func(now time.Time) string
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler menjalankan job secara periodik. Worker bisa jalan beberapa replica,
+// jadi setiap eksekusi dikunci dengan Postgres advisory lock supaya hanya satu replica yang jalan.
+type Scheduler struct {
+	DB   *sql.DB
+	jobs []Job
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start menjalankan semua job di goroutine masing-masing sampai ctx dibatalkan
+func (s *Scheduler) Start(ctx context.Context, wg *sync.WaitGroup) {
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := s.runOnce(ctx, job); err != nil {
+						log.Printf("[SCHEDULER] Job %s gagal: %v", job.Name, err)
+					}
+				}
+			}
+		}(job)
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) error {
+	var runKey string
+	if job.RunKey != nil {
+		if runKey = job.RunKey(time.Now()); runKey == "" {
+			return nil // belum waktunya
+		}
+	}
+
+	// Advisory lock terikat ke koneksi, jadi pakai satu koneksi khusus sampai unlock
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", job.Name).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil // replica lain sedang menjalankan job ini
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", job.Name)
+
+	if runKey != "" {
+		res, err := conn.ExecContext(ctx,
+			"INSERT INTO job_runs (job_name, run_key) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			job.Name, runKey)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil // sudah pernah jalan untuk key ini
+		}
+	}
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		if runKey != "" {
+			// Hapus catatan supaya dicoba lagi di tick berikutnya
+			conn.ExecContext(context.Background(), "DELETE FROM job_runs WHERE job_name = $1 AND run_key = $2", job.Name, runKey)
+		}
+		return err
+	}
+	log.Printf("[SCHEDULER] Job %s selesai dalam %s", job.Name, time.Since(start))
+	return nil
+}
+
+// DailyAt menghasilkan RunKey untuk job harian yang baru boleh jalan setelah jam tertentu.
+// Jam & tanggal dihitung di zona loc (bukan zona container). Sebelum jam tsb key-nya kosong
+// dan job dilewati.
+func DailyAt(hour int, loc *time.Location) func(now time.Time) string {
+	return func(now time.Time) string {
+		now = now.In(loc)
+		if now.Hour() < hour {
+			return ""
+		}
+		return now.Format("2006-01-02")
+	}
+}
+
+// Exclusive menjalankan fn sambil memegang advisory lock name. Beda dengan job terjadwal,
+// replica lain menunggu (bukan dilewati), jadi setelah Exclusive selesai pekerjaannya pasti sudah
+// dijalankan oleh salah satu replica. Dipakai untuk langkah startup seperti migrasi index.
+func Exclusive(ctx context.Context, db *sql.DB, name string, fn func(ctx context.Context) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", name); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+
+	return fn(ctx)
+}
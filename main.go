@@ -8,12 +8,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"phase3-api-architecture/handler"
 	"phase3-api-architecture/middleware"
 	pb "phase3-api-architecture/pb/proto/inventory"
+	"phase3-api-architecture/pkg/health"
+	"phase3-api-architecture/pkg/resiliency"
+	"phase3-api-architecture/pkg/search"
 	"phase3-api-architecture/pkg/stream"
 	"phase3-api-architecture/pkg/telemetry"
 	"phase3-api-architecture/repository"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -121,9 +126,77 @@ func main() {
 	defer kafkaProducer.Close()
 
 	productRepo := repository.NewProductRepository(db, rdb, kafkaProducer)
+	// Tanpa Elasticsearch: SEARCH_BACKEND=postgres untuk pencarian full-text + trigram dengan ranking
+	if os.Getenv("SEARCH_BACKEND") == repository.SearchBackendPostgres {
+		productRepo.SearchBackend = repository.SearchBackendPostgres
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("SEARCH_SIMILARITY_THRESHOLD"), 64); err == nil && v > 0 && v <= 1 {
+		productRepo.SearchThreshold = v
+	}
+	// Autocomplete pakai ES kalau ELASTICSEARCH_ADDRESS diisi, tanpa itu langsung Postgres
+	if esAddress := os.Getenv("ELASTICSEARCH_ADDRESS"); esAddress != "" {
+		if productRepo.ES, err = search.NewClient(esAddress); err != nil {
+			log.Printf("Elasticsearch client tidak valid, autocomplete pakai Postgres: %v", err)
+		}
+	}
 	productHandler := &handler.ProductHandler{Repo: productRepo}
+	if v, err := strconv.Atoi(os.Getenv("SUGGEST_BUDGET_MS")); err == nil && v > 0 {
+		productHandler.SuggestBudget = time.Duration(v) * time.Millisecond
+	}
 	userRepo := &repository.UserRepository{DB: db}
 	authHandler := &handler.AuthHandler{Repo: userRepo}
+	reservationRepo := &repository.ReservationRepository{DB: db, Redis: rdb}
+	reservationHandler := &handler.ReservationHandler{Repo: reservationRepo}
+	locationRepo := &repository.LocationRepository{DB: db, Redis: rdb}
+	locationHandler := &handler.LocationHandler{Repo: locationRepo}
+	purchaseRepo := &repository.PurchaseRepository{DB: db, Redis: rdb}
+	purchaseHandler := &handler.PurchaseHandler{Repo: purchaseRepo}
+
+	categoryRepo := &repository.CategoryRepository{DB: db}
+	categoryHandler := &handler.CategoryHandler{Repo: categoryRepo}
+
+	unitRepo := &repository.ProductUnitRepository{DB: db, Redis: rdb}
+	unitHandler := &handler.ProductUnitHandler{Repo: unitRepo}
+
+	lotRepo := &repository.LotRepository{DB: db}
+	lotHandler := &handler.LotHandler{Repo: lotRepo}
+
+	priceRepo := &repository.PriceRepository{DB: db}
+	priceHandler := &handler.PriceHandler{Repo: priceRepo}
+
+	promotionRepo := &repository.PromotionRepository{DB: db}
+	promotionHandler := &handler.PromotionHandler{Repo: promotionRepo}
+
+	taxRepo := &repository.TaxRepository{DB: db}
+	taxHandler := &handler.TaxHandler{Repo: taxRepo}
+
+	exchangeRateRepo := &repository.ExchangeRateRepository{DB: db}
+	exchangeRateHandler := &handler.ExchangeRateHandler{Repo: exchangeRateRepo}
+
+	transactionRepo := &repository.TransactionRepository{DB: db}
+	transactionHandler := &handler.TransactionHandler{Repo: transactionRepo}
+
+	productImportRepo := repository.NewProductImportRepository(db, rdb, kafkaProducer)
+	productImportHandler := &handler.ProductImportHandler{Repo: productImportRepo}
+
+	exportDir := os.Getenv("EXPORT_DIR") // Harus shared volume jika API jalan beberapa replica
+	if exportDir == "" {
+		exportDir = filepath.Join(os.TempDir(), "exports")
+	}
+	exportRepo := repository.NewExportRepository(db, exportDir)
+	exportHandler := &handler.ExportHandler{Repo: exportRepo}
+
+	reportRepo := &repository.ReportRepository{DB: db}
+	reportHandler := &handler.ReportHandler{Repo: reportRepo}
+
+	// Background job: lepas hold yang sudah kedaluwarsa setiap menit
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go reservationRepo.RunExpirySweeper(bgCtx, time.Minute)
+	// Background job: proses import produk yang antre (langsung dibangunkan saat upload selesai)
+	go productImportRepo.RunImporter(bgCtx, 30*time.Second)
+	// Background job: export besar + hapus file export yang kedaluwarsa
+	go exportRepo.RunExporter(bgCtx, time.Minute)
 
 	// Allow 20 request/detik, dengan burst maksimal 30
 	rateLimitter := middleware.NewIPRateLimiter(rate.Limit(20), 30)
@@ -139,7 +212,7 @@ func main() {
 		)
 
 		// Register Handler ke Server gRPC
-		inventoryGrpcHandler := &handler.GrpcInventoryHandler{Repo: productRepo}
+		inventoryGrpcHandler := &handler.GrpcInventoryHandler{Repo: productRepo, Locations: locationRepo, Transactions: transactionRepo}
 		pb.RegisterInventoryServiceServer(grpcServer, inventoryGrpcHandler)
 
 		reflection.Register(grpcServer)
@@ -154,10 +227,17 @@ func main() {
 	mux := http.NewServeMux()
 
 	// Health Check Endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	// /livez  -> proses hidup (dipakai livenessProbe)
+	// /readyz -> semua dependency sehat (dipakai readinessProbe), hasil di-cache 5 detik
+	healthChecker := health.NewChecker(2*time.Second, 5*time.Second)
+	healthChecker.Register("postgres", db.PingContext)
+	healthChecker.Register("redis", func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
 	})
+	healthChecker.Register("kafka", kafkaProducer.Ping)
+	healthChecker.Register("db_breaker", resiliency.BreakerCheck(productRepo.Breaker))
+
+	// Handler probe didaftarkan di root router (di bawah), di luar rate limiter
 
 	// Logger Only
 	stackLogger := func(h http.Handler) http.Handler {
@@ -185,6 +265,7 @@ func main() {
 	// --- 2. USER ROUTES ---
 	// Gunakan fungsi spesifik 'GetAllProducts' (bukan dispatcher HandlerProducts)
 	mux.Handle("GET /products", stackAuth(http.HandlerFunc(productHandler.GetAllProducts)))
+	mux.Handle("GET /products/suggest", stackAuth(http.HandlerFunc(productHandler.SuggestProducts)))
 
 	// Gunakan fungsi spesifik 'GetProductByID'
 	mux.Handle("GET /products/{id}", stackAuth(http.HandlerFunc(productHandler.HandleGetProductByID)))
@@ -192,19 +273,124 @@ func main() {
 	// Gunakan fungsi spesifik 'HandleCheckout'
 	mux.Handle("POST /checkout", stackAuth(http.HandlerFunc(productHandler.HandleCheckout)))
 
+	// Histori transaksi
+	mux.Handle("GET /me/transactions", stackAuth(http.HandlerFunc(transactionHandler.GetMyTransactions)))
+	mux.Handle("GET /admin/transactions", stackAdmin(http.HandlerFunc(transactionHandler.GetAllTransactions)))
+
+	// Export CSV / XLSX / NDJSON (langsung di-stream atau job background)
+	mux.Handle("GET /admin/export/{kind}", stackAdmin(http.HandlerFunc(exportHandler.Export)))
+	mux.Handle("GET /admin/exports/{id}", stackAdmin(http.HandlerFunc(exportHandler.GetExportJob)))
+	mux.Handle("GET /admin/exports/{id}/download", stackAdmin(http.HandlerFunc(exportHandler.DownloadExport)))
+
+	// Laporan penjualan & persediaan (dari rollup worker)
+	mux.Handle("GET /admin/reports/sales", stackAdmin(http.HandlerFunc(reportHandler.GetSalesReport)))
+	mux.Handle("GET /admin/reports/top-products", stackAdmin(http.HandlerFunc(reportHandler.GetTopProducts)))
+	mux.Handle("GET /admin/reports/slow-movers", stackAdmin(http.HandlerFunc(reportHandler.GetSlowMovers)))
+	mux.Handle("GET /admin/reports/gross-margin", stackAdmin(http.HandlerFunc(reportHandler.GetGrossMargin)))
+	mux.Handle("GET /admin/reports/inventory-valuation", stackAdmin(http.HandlerFunc(reportHandler.GetInventoryValuation)))
+
+	// Hold stok sebelum checkout
+	mux.Handle("POST /reservations", stackAuth(http.HandlerFunc(reservationHandler.CreateReservation)))
+	mux.Handle("DELETE /reservations/{id}", stackAuth(http.HandlerFunc(reservationHandler.ReleaseReservation)))
+
+	// Stok per lokasi
+	mux.Handle("GET /locations", stackAuth(http.HandlerFunc(locationHandler.GetAllLocations)))
+
+	// GET /products/{id}/stock, /units, /variants, /lots, /prices dan GET /products/barcode/{code} bentrok di ServeMux
+	// (tidak ada yang lebih spesifik), jadi didaftarkan lewat satu pola lalu dipilah di sini
+	mux.Handle("GET /products/{id}/{sub}", stackAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.PathValue("id") == "barcode":
+			r.SetPathValue("code", r.PathValue("sub"))
+			productHandler.GetProductByBarcode(w, r)
+		case r.PathValue("sub") == "stock":
+			locationHandler.GetProductStock(w, r)
+		case r.PathValue("sub") == "units":
+			unitHandler.GetProductUnits(w, r)
+		case r.PathValue("sub") == "variants":
+			productHandler.GetProductVariants(w, r)
+		case r.PathValue("sub") == "lots":
+			lotHandler.GetProductLots(w, r)
+		case r.PathValue("sub") == "prices":
+			priceHandler.GetProductPrices(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+
+	// Kategori produk
+	mux.Handle("GET /categories", stackAuth(http.HandlerFunc(categoryHandler.GetAllCategories)))
+
 	// --- 3. ADMIN ROUTES ---
 	// Create
 	mux.Handle("POST /products", stackAdmin(http.HandlerFunc(productHandler.HandleCreateProduct)))
 
-	// Update (PUT)
+	// Import massal CSV / XLSX (diproses di background, status dipoll)
+	mux.Handle("POST /admin/products/import", stackAdmin(http.HandlerFunc(productImportHandler.ImportProducts)))
+	mux.Handle("GET /admin/products/imports/{id}", stackAdmin(http.HandlerFunc(productImportHandler.GetProductImport)))
+
+	// Update (PUT = full, PATCH = sebagian)
 	mux.Handle("PUT /products/{id}", stackAdmin(http.HandlerFunc(productHandler.HandleUpdateProduct)))
+	mux.Handle("PATCH /products/{id}", stackAdmin(http.HandlerFunc(productHandler.HandlePatchProduct)))
 
-	// Delete (DELETE)
+	// Delete (DELETE) = soft delete, produk masuk trash
 	mux.Handle("DELETE /products/{id}", stackAdmin(http.HandlerFunc(productHandler.HandleDeleteProduct)))
+	mux.Handle("GET /products/trash", stackAdmin(http.HandlerFunc(productHandler.GetTrashProducts)))
+	mux.Handle("POST /products/{id}/restore", stackAdmin(http.HandlerFunc(productHandler.HandleRestoreProduct)))
+
+	// Satuan jual produk (konversi ke satuan dasar)
+	mux.Handle("POST /products/{id}/units", stackAdmin(http.HandlerFunc(unitHandler.CreateProductUnit)))
+	mux.Handle("PUT /products/{id}/units/{unitId}", stackAdmin(http.HandlerFunc(unitHandler.UpdateProductUnit)))
+	mux.Handle("DELETE /products/{id}/units/{unitId}", stackAdmin(http.HandlerFunc(unitHandler.DeleteProductUnit)))
+
+	// Jadwal harga
+	mux.Handle("POST /products/{id}/prices", stackAdmin(http.HandlerFunc(priceHandler.SchedulePrice)))
+	mux.Handle("DELETE /products/{id}/prices/{priceId}", stackAdmin(http.HandlerFunc(priceHandler.CancelScheduledPrice)))
+
+	// Kategori
+	mux.Handle("POST /categories", stackAdmin(http.HandlerFunc(categoryHandler.CreateCategory)))
+
+	// Lokasi & transfer stok antar lokasi
+	mux.Handle("POST /locations", stackAdmin(http.HandlerFunc(locationHandler.CreateLocation)))
+	mux.Handle("POST /transfers", stackAdmin(http.HandlerFunc(locationHandler.CreateTransfer)))
+	mux.Handle("GET /transfers/{id}", stackAdmin(http.HandlerFunc(locationHandler.GetTransfer)))
+	mux.Handle("POST /transfers/{id}/receive", stackAdmin(http.HandlerFunc(locationHandler.ReceiveTransfer)))
+
+	// Supplier, purchase order & penerimaan barang (stok hanya berubah secara additive)
+	mux.Handle("GET /suppliers", stackAdmin(http.HandlerFunc(purchaseHandler.GetAllSuppliers)))
+	mux.Handle("POST /suppliers", stackAdmin(http.HandlerFunc(purchaseHandler.CreateSupplier)))
+	mux.Handle("GET /purchase-orders", stackAdmin(http.HandlerFunc(purchaseHandler.GetAllPurchaseOrders)))
+	mux.Handle("POST /purchase-orders", stackAdmin(http.HandlerFunc(purchaseHandler.CreatePurchaseOrder)))
+	mux.Handle("GET /purchase-orders/{id}", stackAdmin(http.HandlerFunc(purchaseHandler.GetPurchaseOrder)))
+	mux.Handle("POST /purchase-orders/{id}/order", stackAdmin(http.HandlerFunc(purchaseHandler.MarkPurchaseOrderOrdered)))
+	mux.Handle("POST /purchase-orders/{id}/receipts", stackAdmin(http.HandlerFunc(purchaseHandler.ReceiveGoods)))
+	mux.Handle("POST /products/{id}/adjustments", stackAdmin(http.HandlerFunc(purchaseHandler.AdjustStock)))
+	mux.Handle("GET /lots/expiring", stackAdmin(http.HandlerFunc(lotHandler.GetExpiringLots)))
+
+	// Promosi & kupon
+	mux.Handle("GET /promotions", stackAdmin(http.HandlerFunc(promotionHandler.GetAllPromotions)))
+	mux.Handle("POST /promotions", stackAdmin(http.HandlerFunc(promotionHandler.CreatePromotion)))
+	mux.Handle("DELETE /promotions/{id}", stackAdmin(http.HandlerFunc(promotionHandler.DeactivatePromotion)))
+
+	// Tarif pajak
+	mux.Handle("GET /tax-rates", stackAdmin(http.HandlerFunc(taxHandler.GetAllTaxRates)))
+	mux.Handle("POST /tax-rates", stackAdmin(http.HandlerFunc(taxHandler.CreateTaxRate)))
+
+	// Kurs manual untuk harga multi mata uang
+	mux.Handle("GET /exchange-rates", stackAuth(http.HandlerFunc(exchangeRateHandler.GetAllExchangeRates)))
+	mux.Handle("PUT /exchange-rates/{base}/{quote}", stackAdmin(http.HandlerFunc(exchangeRateHandler.SetExchangeRate)))
 
 	// Otomatis membuat "Span" untuk setiap req HTTP yang masuk
 	otelHandler := otelhttp.NewHandler(mux, "server-root")
-	finalHandler := rateLimitter.Limit(otelHandler)
+
+	// Probe di luar rate limiter: kubelet memanggilnya terus dari IP yang sama, kalau kena 429
+	// pod dianggap tidak sehat lalu di-restart / dikeluarkan dari service.
+	finalHandler := http.NewServeMux()
+	finalHandler.HandleFunc("GET /livez", health.LivenessHandler)
+	finalHandler.HandleFunc("GET /readyz", healthChecker.ReadinessHandler)
+	// /health dipertahankan untuk client lama
+	finalHandler.HandleFunc("GET /health", health.LivenessHandler)
+	finalHandler.Handle("/", rateLimitter.Limit(otelHandler))
 
 	srv := &http.Server{
 		Addr:         ":8080",
@@ -226,6 +412,7 @@ func main() {
 
 	<-quit
 	fmt.Println("\n⚠️  Server sedang dimatikan...")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
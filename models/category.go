@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Category bisa bertingkat lewat ParentID (contoh: Minuman > Kopi > Kopi Susu)
+type Category struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" validate:"required,min=2,max=100"`
+	ParentID  *int      `json:"parent_id,omitempty"`
+	TaxRateID *int      `json:"tax_rate_id,omitempty"` // Berlaku juga untuk sub-kategori yang tidak punya tarif sendiri
+	Path      string    `json:"path"`                  // Read-only: nama lengkap dari kategori utama, contoh "Minuman > Kopi"
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ExchangeRate: 1 unit Base = Rate unit Quote. Rate disimpan sebagai string desimal supaya presisinya tidak hilang.
+type ExchangeRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      string    `json:"rate"`
+	UpdatedBy *int      `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExchangeRateRequest struct {
+	Rate string `json:"rate" validate:"required"` // Contoh "0.0000625" untuk IDR -> USD
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Jenis data yang bisa di-export lewat /admin/export/{kind}
+const (
+	ExportProducts     = "products"
+	ExportTransactions = "transactions"
+)
+
+// Status job export background
+const (
+	ExportPending    = "pending"
+	ExportProcessing = "processing"
+	ExportCompleted  = "completed"
+	ExportFailed     = "failed"
+	ExportExpired    = "expired" // file sudah dihapus
+)
+
+// ExportFilter: filter export, disimpan sebagai JSON di export_jobs untuk job background
+type ExportFilter struct {
+	// Khusus products
+	Search         string `json:"search,omitempty"`
+	CategoryID     int    `json:"category_id,omitempty"` // Termasuk sub-kategori
+	IncludeDeleted bool   `json:"include_deleted,omitempty"`
+
+	// Khusus transactions (Cursor & Limit diabaikan)
+	Transactions TransactionFilter `json:"transactions"`
+}
+
+// ExportJob: export yang dijalankan di background, file diunduh lewat DownloadURL setelah completed
+type ExportJob struct {
+	ID          int        `json:"id"`
+	Kind        string     `json:"kind"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	Rows        int        `json:"rows"` // Baris yang sudah ditulis (progress selama processing)
+	SizeBytes   int64      `json:"size_bytes"`
+	Error       string     `json:"error,omitempty"`
+	CreatedBy   int        `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+
+	FilePath string       `json:"-"`
+	Filter   ExportFilter `json:"-"`
+}
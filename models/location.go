@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+const (
+	LocationStore     = "store"
+	LocationWarehouse = "warehouse"
+
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+)
+
+type Location struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code" validate:"required,max=20"`
+	Name      string    `json:"name" validate:"required,min=3"`
+	Type      string    `json:"type" validate:"required,oneof=store warehouse"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockLevel adalah stok satu produk di satu lokasi
+type StockLevel struct {
+	LocationID   int    `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	LocationName string `json:"location_name"`
+	Quantity     int    `json:"quantity"`
+	InTransit    int    `json:"in_transit"` // sedang dikirim menuju lokasi ini
+}
+
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Status         string              `json:"status"`
+	CreatedBy      int                 `json:"created_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	ReceivedAt     *time.Time          `json:"received_at,omitempty"`
+	Items          []StockTransferItem `json:"items"`
+}
+
+type StockTransferItem struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gt=0"`
+}
+
+type TransferRequest struct {
+	FromLocationID int                 `json:"from_location_id" validate:"required"`
+	ToLocationID   int                 `json:"to_location_id" validate:"required,nefield=FromLocationID"`
+	Items          []StockTransferItem `json:"items" validate:"required,min=1,unique=ProductID,dive"`
+}
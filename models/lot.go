@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Status expiry sebuah lot, dihitung saat dibaca
+const (
+	LotStatusOK         = "ok"
+	LotStatusNearExpiry = "near_expiry"
+	LotStatusExpired    = "expired"
+
+	// DefaultNearExpiryDays: lot dianggap hampir kedaluwarsa jika expired dalam N hari
+	DefaultNearExpiryDays = 7
+)
+
+// StockLot adalah satu batch barang di satu lokasi, dialokasikan FEFO saat stok keluar
+type StockLot struct {
+	ID                int        `json:"id"`
+	ProductID         int        `json:"product_id"`
+	ProductName       string     `json:"product_name,omitempty"`
+	LocationID        int        `json:"location_id"`
+	LotNumber         string     `json:"lot_number,omitempty"`
+	ExpiryDate        *time.Time `json:"expiry_date,omitempty"`
+	ReceivedAt        time.Time  `json:"received_at"`
+	QuantityReceived  int        `json:"quantity_received"`
+	QuantityRemaining int        `json:"quantity_remaining"`
+	GoodsReceiptID    *int       `json:"goods_receipt_id,omitempty"`
+	Status            string     `json:"status"`
+}
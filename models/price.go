@@ -0,0 +1,32 @@
+package models
+
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+// Status baris histori harga, dihitung saat dibaca
+const (
+	PriceStatusScheduled = "scheduled" // belum berlaku
+	PriceStatusCurrent   = "current"
+	PriceStatusHistory   = "history" // sudah digantikan harga lain
+)
+
+// ProductPrice adalah satu periode harga dasar produk (effective_from s/d effective_to)
+type ProductPrice struct {
+	ID            int         `json:"id"`
+	ProductID     int         `json:"product_id"`
+	Price         money.Money `json:"price"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	EffectiveTo   *time.Time  `json:"effective_to,omitempty"`
+	AppliedAt     *time.Time  `json:"applied_at,omitempty"`
+	CreatedBy     *int        `json:"created_by,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Status        string      `json:"status"`
+}
+
+// SchedulePriceRequest menjadwalkan harga dasar baru, EffectiveFrom harus di masa depan (RFC 3339)
+type SchedulePriceRequest struct {
+	Price         money.Money `json:"price" validate:"required,gt=0"` // Mata uang harus sama dengan mata uang produk
+	EffectiveFrom time.Time   `json:"effective_from" validate:"required"`
+}
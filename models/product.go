@@ -1,16 +1,190 @@
 package models
 
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
 type Product struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name" validate:"required,min=3"`
-	Price int    `json:"price" validate:"required,gt=0"`
-	Stock int    `json:"stock" validate:"required,gte=0"`
+	ID    int         `json:"id"`
+	Name  string      `json:"name" validate:"required,min=3"`
+	Price money.Money `json:"price" validate:"required,gt=0"` // {"amount": "15000.00", "currency": "IDR"}, angka saja = IDR
+	Stock int         `json:"stock" validate:"gte=0"`
+
+	// Read-only: harga dikonversi ke mata uang lain memakai tabel kurs (hanya terisi di detail produk)
+	ConvertedPrices []money.Money `json:"converted_prices,omitempty"`
+
+	// Satuan dasar stok & harga (Price per 1 BaseUnit). Hanya bisa diisi saat create, default "pcs".
+	BaseUnit string `json:"base_unit" validate:"omitempty,max=20"`
+
+	// Read-only: satuan jual lain, dikelola lewat /products/{id}/units (hanya terisi di detail produk)
+	Units []ProductUnit `json:"units,omitempty"`
+
+	// Identitas produk: SKU internal & barcode EAN-13 (keduanya opsional tapi unik)
+	SKU        *string `json:"sku,omitempty" validate:"omitempty,min=1,max=64"`
+	Barcode    *string `json:"barcode,omitempty" validate:"omitempty,ean13"`
+	CategoryID *int    `json:"category_id,omitempty"`
+
+	// Varian (mis. rasa / ukuran): produk anak dari ParentID dengan stok, SKU, barcode & harga sendiri.
+	// Hanya bisa diisi saat create, daftarnya lewat GET /products/{id}/variants.
+	ParentID    *int    `json:"parent_id,omitempty"`
+	VariantName *string `json:"variant_name,omitempty" validate:"required_with=ParentID,excluded_without=ParentID,omitempty,min=1,max=50"`
+
+	// Tarif pajak khusus produk ini, kosong = ikut kategori / tarif default
+	TaxRateID *int `json:"tax_rate_id,omitempty"`
+
+	// Pengaturan restock: alert muncul saat stok tersedia <= ReorderPoint (0 = tidak dipantau)
+	ReorderPoint    int  `json:"reorder_point" validate:"gte=0"`
+	ReorderQuantity int  `json:"reorder_quantity" validate:"gte=0"`
+	SupplierID      *int `json:"supplier_id,omitempty"` // Supplier utama untuk draft PO otomatis
+
+	// Read-only: naik setiap kali produk diubah, dipakai sebagai ETag / If-Match
+	Version int `json:"version"`
+
+	// Read-only: terisi jika produk ada di trash (soft delete)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Read-only: jumlah stok yang sedang di-hold reservasi aktif.
+	// Saat dibaca, Stock sudah dikurangi Reserved (stok tersedia).
+	Reserved int `json:"reserved"`
+}
+
+// ProductFields adalah dokumen yang di-patch lewat PATCH /products/{id}.
+// Berbeda dengan GET, Stock di sini adalah stok fisik (belum dikurangi reservasi).
+type ProductFields struct {
+	Name            string      `json:"name" validate:"required,min=3"`
+	Price           money.Money `json:"price" validate:"required,gt=0"`
+	Stock           int         `json:"stock" validate:"gte=0"`
+	ReorderPoint    int         `json:"reorder_point" validate:"gte=0"`
+	ReorderQuantity int         `json:"reorder_quantity" validate:"gte=0"`
+	SupplierID      *int        `json:"supplier_id"`
+
+	SKU        *string `json:"sku" validate:"omitempty,min=1,max=64"`
+	Barcode    *string `json:"barcode" validate:"omitempty,ean13"`
+	CategoryID *int    `json:"category_id"`
+	TaxRateID  *int    `json:"tax_rate_id"`
+}
+
+// FieldChange mencatat satu field yang berubah beserta nilai lama & barunya
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// ProductPatchResponse: hasil PATCH berisi data terbaru + daftar field yang berubah
+type ProductPatchResponse struct {
+	Product Product       `json:"product"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// Diff membandingkan dua versi produk. Field memakai nama JSON (= nama kolom di tabel products).
+func (f ProductFields) Diff(next ProductFields) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, o, n any) {
+		changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+	}
+
+	if f.Name != next.Name {
+		add("name", f.Name, next.Name)
+	}
+	if f.Price != next.Price {
+		add("price", f.Price, next.Price)
+	}
+	if f.Stock != next.Stock {
+		add("stock", f.Stock, next.Stock)
+	}
+	if f.ReorderPoint != next.ReorderPoint {
+		add("reorder_point", f.ReorderPoint, next.ReorderPoint)
+	}
+	if f.ReorderQuantity != next.ReorderQuantity {
+		add("reorder_quantity", f.ReorderQuantity, next.ReorderQuantity)
+	}
+	if !ptrEqual(f.SupplierID, next.SupplierID) {
+		add("supplier_id", f.SupplierID, next.SupplierID)
+	}
+	if !ptrEqual(f.SKU, next.SKU) {
+		add("sku", f.SKU, next.SKU)
+	}
+	if !ptrEqual(f.Barcode, next.Barcode) {
+		add("barcode", f.Barcode, next.Barcode)
+	}
+	if !ptrEqual(f.CategoryID, next.CategoryID) {
+		add("category_id", f.CategoryID, next.CategoryID)
+	}
+	if !ptrEqual(f.TaxRateID, next.TaxRateID) {
+		add("tax_rate_id", f.TaxRateID, next.TaxRateID)
+	}
+	return changes
+}
+
+// ptrEqual: dua field nullable sama jika sama-sama nil atau nilainya sama
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 type ProductFilter struct {
 	Page   int    `json:"page" validate:"gte=1"`
 	Limit  int    `json:"limit" validate:"gte=1,lte=100"`
 	Search string `json:"search"`
+
+	// Opsional: hanya produk di kategori ini (termasuk sub-kategorinya)
+	CategoryID int `json:"category_id"`
+
+	// Opsional: tampilkan stok di lokasi tertentu saja
+	LocationID int `json:"location_id"`
+
+	// Urutan: name, price, stock, created_at (default id), Order asc (default) / desc
+	Sort  string `json:"sort"`
+	Order string `json:"order"`
+
+	// Cursor dari next_cursor halaman sebelumnya. Kalau diisi, Page diabaikan dan
+	// Sort / Order ikut isi cursor.
+	Cursor string `json:"cursor"`
+
+	// Filter lanjutan, sudah dinormalisasi: batas angka inklusif, rentang waktu [From, To)
+	MinPrice *money.Money `json:"min_price,omitempty"` // hanya produk dengan mata uang yang sama
+	MaxPrice *money.Money `json:"max_price,omitempty"`
+	MinStock *int         `json:"min_stock,omitempty"` // stok yang ditampilkan (tersedia / per lokasi)
+	MaxStock *int         `json:"max_stock,omitempty"`
+
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	UpdatedFrom *time.Time `json:"updated_from,omitempty"`
+	UpdatedTo   *time.Time `json:"updated_to,omitempty"`
+
+	// Hanya produk dengan ID ini (urut & unik)
+	IDs []int `json:"ids,omitempty"`
+}
+
+// ProductPage: satu halaman list produk. NextCursor kosong berarti sudah halaman terakhir.
+type ProductPage struct {
+	Items      []Product `json:"items"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	// Urutan yang benar-benar dipakai (default terisi, atau ikut cursor)
+	Sort  string `json:"sort"`
+	Order string `json:"order"`
+}
+
+// ProductSuggestion: satu saran autocomplete, data ringkas untuk POS
+type ProductSuggestion struct {
+	ID    int         `json:"id"`
+	Name  string      `json:"name"`
+	SKU   *string     `json:"sku,omitempty"`
+	Price money.Money `json:"price"`
+}
+
+// SuggestResult: hasil GET /products/suggest. Source = backend yang menjawab
+// (elasticsearch / postgres), TimedOut = budget habis sebelum ada hasil.
+type SuggestResult struct {
+	Query    string              `json:"query"`
+	Source   string              `json:"source,omitempty"`
+	TimedOut bool                `json:"timed_out,omitempty"`
+	Items    []ProductSuggestion `json:"items"`
 }
 
 func (f *ProductFilter) GetOffset() int {
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Status import produk
+const (
+	ImportUploading  = "uploading" // file masih dibaca ke staging
+	ImportPending    = "pending"   // menunggu diproses background job
+	ImportProcessing = "processing"
+	ImportCompleted  = "completed"
+	ImportFailed     = "failed" // error fatal, lihat Error
+)
+
+// ProductImport: satu job import produk dari CSV / XLSX, dipoll lewat GET /admin/products/imports/{id}
+type ProductImport struct {
+	ID            int        `json:"id"`
+	Filename      string     `json:"filename"`
+	Format        string     `json:"format"` // csv / xlsx
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"` // Termasuk baris yang gagal validasi
+	Created       int        `json:"created"`
+	Updated       int        `json:"updated"`
+	Failed        int        `json:"failed"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+
+	// Laporan error per baris (hanya terisi di detail import)
+	Errors []ProductImportError `json:"errors,omitempty"`
+}
+
+type ProductImportError struct {
+	Row   int    `json:"row"` // Nomor baris di file, header = 1
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ProductImportRow: satu baris file di staging. Product nil jika baris tidak lolos validasi (Error terisi).
+type ProductImportRow struct {
+	Row     int
+	SKU     string
+	Product *Product
+	Error   string
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+// ProductUnit adalah satuan jual (kemasan) sebuah produk, contoh "Karung 5kg" atau "Bungkus isi 16".
+// ConversionFactor = jumlah satuan dasar (Product.BaseUnit) dalam 1 satuan ini.
+type ProductUnit struct {
+	ID               int         `json:"id"`
+	ProductID        int         `json:"product_id"`
+	Name             string      `json:"name" validate:"required,min=1,max=50"`
+	ConversionFactor int         `json:"conversion_factor" validate:"required,gt=0"`
+	Price            money.Money `json:"price" validate:"required,gt=0"` // Harga per 1 satuan ini, mata uang sama dengan produk
+	SKU              *string     `json:"sku,omitempty" validate:"omitempty,min=1,max=64"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+// Tipe promosi
+const (
+	PromotionPercentage = "percentage"  // Value persen dari sisa harga baris
+	PromotionFixed      = "fixed"       // Potongan Amount per baris, hanya untuk produk dengan mata uang yang sama
+	PromotionBuyXGetY   = "buy_x_get_y" // Setiap beli BuyQuantity, GetQuantity berikutnya gratis
+)
+
+// Promotion adalah aturan diskon. Jika Code diisi, promosi menjadi kupon yang hanya
+// berlaku saat code dikirim di checkout; selain itu diterapkan otomatis.
+type Promotion struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Type        string `json:"type" validate:"required,oneof=percentage fixed buy_x_get_y"`
+	Value       int    `json:"value" validate:"gte=0"` // Persen, khusus tipe percentage
+	BuyQuantity int    `json:"buy_quantity" validate:"gte=0"`
+	GetQuantity int    `json:"get_quantity" validate:"gte=0"`
+	MinQuantity int    `json:"min_quantity" validate:"gte=0"` // 0 dianggap 1
+
+	// Nominal potongan, wajib untuk tipe fixed
+	Amount *money.Money `json:"amount,omitempty"`
+
+	// Scope: produk atau kategori (termasuk sub-kategori), keduanya kosong = semua produk
+	ProductID  *int `json:"product_id,omitempty"`
+	CategoryID *int `json:"category_id,omitempty"`
+
+	Code         *string    `json:"code,omitempty" validate:"omitempty,min=3,max=50"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	UsageLimit   *int       `json:"usage_limit,omitempty" validate:"omitempty,gt=0"`
+	PerUserLimit *int       `json:"per_user_limit,omitempty" validate:"omitempty,gt=0"`
+
+	// Read-only
+	UsageCount int       `json:"usage_count"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AppliedDiscount adalah satu promosi yang dipakai di baris transaksi
+type AppliedDiscount struct {
+	PromotionID int         `json:"promotion_id"`
+	Name        string      `json:"name"`
+	Code        string      `json:"code,omitempty"`
+	Amount      money.Money `json:"amount"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+const (
+	PurchaseDraft             = "draft"
+	PurchaseOrdered           = "ordered"
+	PurchasePartiallyReceived = "partially_received"
+	PurchaseReceived          = "received"
+)
+
+type Supplier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" validate:"required,min=3"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email" validate:"omitempty,email"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PurchaseOrder struct {
+	ID         int                 `json:"id"`
+	SupplierID int                 `json:"supplier_id"`
+	LocationID int                 `json:"location_id"`
+	Status     string              `json:"status"`
+	Notes      string              `json:"notes,omitempty"`
+	CreatedBy  int                 `json:"created_by"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Items      []PurchaseOrderItem `json:"items"`
+}
+
+type PurchaseOrderItem struct {
+	ProductID        int         `json:"product_id" validate:"required"`
+	QuantityOrdered  int         `json:"quantity_ordered" validate:"required,gt=0"`
+	QuantityReceived int         `json:"quantity_received"`
+	UnitCost         money.Money `json:"unit_cost" validate:"gte=0"` // {"amount": "12500.00", "currency": "IDR"}, angka saja = IDR
+}
+
+type PurchaseOrderRequest struct {
+	SupplierID int                 `json:"supplier_id" validate:"required"`
+	LocationID int                 `json:"location_id"` // Default: lokasi utama
+	Notes      string              `json:"notes"`
+	Items      []PurchaseOrderItem `json:"items" validate:"required,min=1,unique=ProductID,dive"`
+}
+
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	LocationID      int                `json:"location_id"`
+	ReceivedBy      int                `json:"received_by"`
+	ReceivedAt      time.Time          `json:"received_at"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ProductID int          `json:"product_id" validate:"required"`
+	Quantity  int          `json:"quantity" validate:"required,gt=0"`
+	UnitCost  *money.Money `json:"unit_cost,omitempty" validate:"omitempty,gte=0"` // Kosong = pakai harga di PO, mata uang harus sama
+
+	// Opsional: setiap baris penerimaan dicatat sebagai satu lot
+	LotNumber  string `json:"lot_number,omitempty" validate:"max=50"`
+	ExpiryDate string `json:"expiry_date,omitempty" validate:"omitempty,datetime=2006-01-02"` // Format YYYY-MM-DD
+	LotID      int    `json:"lot_id,omitempty"`                                               // Read-only: lot yang terbentuk
+}
+
+type GoodsReceiptRequest struct {
+	Items []GoodsReceiptItem `json:"items" validate:"required,min=1,unique=ProductID,dive"`
+}
+
+// StockAdjustmentRequest dipakai untuk koreksi stok (rusak, hilang, hasil opname)
+type StockAdjustmentRequest struct {
+	LocationID int    `json:"location_id"` // Default: lokasi utama
+	Delta      int    `json:"delta" validate:"required,ne=0"`
+	LotID      int    `json:"lot_id"` // Opsional untuk delta negatif: kurangi dari lot tertentu (misal lot kedaluwarsa)
+	Reason     string `json:"reason" validate:"required,min=3"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// LowStockItem adalah produk yang stok tersedianya sudah di bawah reorder point
+type LowStockItem struct {
+	ProductID       int    `json:"product_id"`
+	Name            string `json:"name"`
+	Stock           int    `json:"stock"`
+	ReorderPoint    int    `json:"reorder_point"`
+	ReorderQuantity int    `json:"reorder_quantity"`
+	SupplierID      *int   `json:"supplier_id,omitempty"`
+	SoldLastPeriod  int    `json:"sold_last_period"` // jumlah terjual selama periode lookback
+}
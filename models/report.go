@@ -0,0 +1,81 @@
+package models
+
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+// Periode laporan penjualan
+const (
+	ReportDaily   = "daily"
+	ReportWeekly  = "weekly" // Minggu mulai hari Senin
+	ReportMonthly = "monthly"
+)
+
+// ReportRange: rentang tanggal laporan (inklusif) dalam satu mata uang
+type ReportRange struct {
+	From     time.Time
+	To       time.Time
+	Currency string
+}
+
+// SalesPeriod: total penjualan satu periode. Revenue = Total tanpa pajak.
+type SalesPeriod struct {
+	PeriodStart  time.Time   `json:"period_start"`
+	Transactions int         `json:"transactions"`
+	Quantity     int         `json:"quantity"` // Satuan dasar
+	Gross        money.Money `json:"gross"`    // Subtotal sebelum diskon
+	Discount     money.Money `json:"discount"`
+	Tax          money.Money `json:"tax"`
+	Total        money.Money `json:"total"`
+	Revenue      money.Money `json:"revenue"`
+}
+
+// ProductSales: penjualan per produk (top seller)
+type ProductSales struct {
+	ProductID    int         `json:"product_id"`
+	Name         string      `json:"name"`
+	Transactions int         `json:"transactions"`
+	Quantity     int         `json:"quantity"`
+	Revenue      money.Money `json:"revenue"`
+}
+
+// SlowMover: produk yang masih ada stoknya tapi jarang / tidak terjual dalam periode laporan
+type SlowMover struct {
+	ProductID    int        `json:"product_id"`
+	Name         string     `json:"name"`
+	Stock        int        `json:"stock"`
+	SoldQuantity int        `json:"sold_quantity"`
+	LastSoldOn   *time.Time `json:"last_sold_on,omitempty"` // nil = belum pernah terjual
+}
+
+// MarginPeriod: gross margin satu periode, hanya dari transaksi yang HPP-nya diketahui.
+// CostCoverageBP = porsi quantity terjual yang HPP-nya diketahui (basis poin, 10000 = 100%).
+type MarginPeriod struct {
+	PeriodStart    time.Time   `json:"period_start"`
+	Revenue        money.Money `json:"revenue"`        // Semua transaksi
+	CostedRevenue  money.Money `json:"costed_revenue"` // Transaksi yang HPP-nya diketahui
+	Cost           money.Money `json:"cost"`
+	GrossMargin    money.Money `json:"gross_margin"` // CostedRevenue - Cost
+	MarginBP       int         `json:"margin_bp"`    // GrossMargin / CostedRevenue
+	CostCoverageBP int         `json:"cost_coverage_bp"`
+}
+
+// InventoryValuation: nilai persediaan (stok fisik x harga pokok) pada satu tanggal.
+// Tanggal lampau dibaca dari snapshot harian, hari ini dihitung langsung dari stok saat ini.
+type InventoryValuation struct {
+	Date             time.Time                `json:"date"`
+	Live             bool                     `json:"live"`
+	Currency         string                   `json:"currency"` // Mata uang harga pokok yang dihitung
+	TotalValue       money.Money              `json:"total_value"`
+	UncostedProducts int                      `json:"uncosted_products"` // Produk dengan stok tapi harga pokok belum diketahui
+	Items            []InventoryValuationItem `json:"items"`
+}
+
+type InventoryValuationItem struct {
+	ProductID int         `json:"product_id"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	UnitCost  money.Money `json:"unit_cost"`
+	Value     money.Money `json:"value"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	ReservationActive    = "active"
+	ReservationConverted = "converted" // sudah dipakai checkout
+	ReservationReleased  = "released"  // dibatalkan user
+	ReservationExpired   = "expired"   // dilepas oleh sweeper
+)
+
+type Reservation struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReservationRequest struct {
+	ProductID  int `json:"product_id" validate:"required"`
+	Quantity   int `json:"quantity" validate:"required,gt=0"`
+	TTLMinutes int `json:"ttl_minutes" validate:"omitempty,gte=1,lte=120"` // Default 15 menit
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TaxRate adalah tarif pajak (PPN) yang bisa dipasang di produk, kategori, atau jadi default.
+// RateBP dalam basis poin: 1100 = 11%.
+type TaxRate struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" validate:"required,min=2,max=50"`
+	RateBP    int       `json:"rate_bp" validate:"gte=0,lte=10000"`
+	Inclusive bool      `json:"inclusive"`                                           // Harga jual sudah termasuk pajak
+	Rounding  string    `json:"rounding" validate:"omitempty,oneof=half_up down up"` // Default half_up
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
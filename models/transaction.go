@@ -1,17 +1,94 @@
 package models
 
-import "time"
+import (
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
 
 type Transaction struct {
-	ID         int       `json:"id"`
-	UserID     int       `json:"user_id"`
-	ProductID  int       `json:"product_id"`
-	Quantity   int       `json:"quantity"`
-	TotalPrice int       `json:"total_price"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int         `json:"id"`
+	UserID     int         `json:"user_id"`
+	ProductID  int         `json:"product_id"`
+	Quantity   int         `json:"quantity"`    // Dalam satuan dasar produk
+	TotalPrice money.Money `json:"total_price"` // Grand total (setelah diskon & pajak)
+	LocationID int         `json:"location_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+
+	// Satuan yang dibeli (nil = satuan dasar) dan jumlahnya dalam satuan tsb
+	UnitID       *int `json:"unit_id,omitempty"`
+	UnitQuantity int  `json:"unit_quantity"`
+
+	// Rincian harga: potongan promosi / kupon (detail di transaction_discounts) dan pajak
+	Subtotal       money.Money `json:"subtotal"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	TaxRateBP      int         `json:"tax_rate_bp"`
+	TaxInclusive   bool        `json:"tax_inclusive"`
+	TaxAmount      money.Money `json:"tax_amount"`
+
+	// Read-only: diisi saat dibaca dari histori transaksi
+	ProductName string            `json:"product_name,omitempty"`
+	UnitName    string            `json:"unit_name,omitempty"`
+	Discounts   []AppliedDiscount `json:"discounts,omitempty"`
+}
+
+// TransactionFilter untuk histori transaksi. Semua filter opsional (nilai kosong = tidak difilter).
+type TransactionFilter struct {
+	UserID    int
+	ProductID int
+	From      *time.Time // created_at >= From
+	To        *time.Time // created_at < To
+
+	// Rentang grand total (inklusif), hanya transaksi dengan mata uang yang sama
+	MinTotal *money.Money
+	MaxTotal *money.Money
+
+	// Cursor dari NextCursor halaman sebelumnya, kosong = halaman pertama
+	Cursor string
+	Limit  int
+}
+
+// TransactionPage: satu halaman histori, terbaru dulu. NextCursor kosong = sudah halaman terakhir.
+type TransactionPage struct {
+	Items      []Transaction `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 type CheckoutRequest struct {
 	ProductID int `json:"product_id" validate:"required"`
-	Quantity  int `json:"quantity" validate:"required,gt=0"`
+	Quantity  int `json:"quantity" validate:"required,gt=0,max=1000000"` // Dalam satuan UnitID
+
+	// Opsional: varian dari /products/{id}/variants. Stok, harga & satuan diambil dari varian,
+	// jadi UnitID (kalau ada) harus satuan milik varian tersebut.
+	VariantID int `json:"variant_id,omitempty"`
+
+	// Opsional: satuan jual dari /products/{id}/units, default satuan dasar.
+	// Stok berkurang Quantity x conversion_factor satuan dasar.
+	UnitID int `json:"unit_id,omitempty"`
+
+	// Opsional: checkout dari hold yang dibuat lewat POST /reservations
+	ReservationID int `json:"reservation_id,omitempty"`
+
+	// Opsional: lokasi pengambilan stok, default ke lokasi utama
+	LocationID int `json:"location_id,omitempty"`
+
+	// Opsional: kode kupon (tidak case-sensitive)
+	CouponCode string `json:"coupon_code,omitempty" validate:"omitempty,max=50"`
+}
+
+// CheckoutResult adalah rincian harga baris yang baru dibuat.
+// TotalPrice = Subtotal - DiscountAmount, ditambah TaxAmount jika pajaknya exclusive.
+// Semua nominal dalam mata uang produk.
+type CheckoutResult struct {
+	TransactionID  int               `json:"transaction_id"`
+	Subtotal       money.Money       `json:"subtotal"`
+	DiscountAmount money.Money       `json:"discount_amount"`
+	Discounts      []AppliedDiscount `json:"discounts"`
+
+	TaxRateID    *int        `json:"tax_rate_id,omitempty"`
+	TaxName      string      `json:"tax_name,omitempty"`
+	TaxRateBP    int         `json:"tax_rate_bp"`
+	TaxInclusive bool        `json:"tax_inclusive"`
+	TaxAmount    money.Money `json:"tax_amount"`
+
+	TotalPrice money.Money `json:"total_price"`
 }
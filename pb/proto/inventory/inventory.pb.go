@@ -24,7 +24,8 @@ const (
 // Definisikan Pesan (Bentuk datanya gimana?)
 type GetStockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // ID Produk
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                   // ID Produk
+	LocationId    int32                  `protobuf:"varint,2,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"` // Opsional: stok di lokasi tertentu (0 = total semua lokasi)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,11 +67,19 @@ func (x *GetStockRequest) GetId() int32 {
 	return 0
 }
 
+func (x *GetStockRequest) GetLocationId() int32 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
 type GetStockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"`
+	Version       int32                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"` // Versi produk, dipakai untuk UpdateProduct
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -126,19 +135,788 @@ func (x *GetStockResponse) GetStock() int32 {
 	return 0
 }
 
+func (x *GetStockResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// Nominal uang dalam minor unit, contoh Rp15.000,00 = {currency: "IDR", amount: 1500000}
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"` // Kode ISO 4217
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Money) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type UpdateProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+	Price         int32  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`                            // Major unit di mata uang produk, dipakai jika price_money kosong
+	Version       int32  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`                        // Harus sama dengan versi terakhir, kalau beda -> FAILED_PRECONDITION
+	PriceMoney    *Money `protobuf:"bytes,5,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"` // Mata uang harus sama dengan mata uang produk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductRequest) Reset() {
+	*x = UpdateProductRequest{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductRequest) ProtoMessage() {}
+
+func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateProductRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+func (x *UpdateProductRequest) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+type UpdateProductResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+	Price         int32  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"` // Major unit (pecahan dibuang), pakai price_money
+	Stock         int32  `protobuf:"varint,4,opt,name=stock,proto3" json:"stock,omitempty"`
+	Version       int32  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"` // Versi baru setelah update
+	PriceMoney    *Money `protobuf:"bytes,6,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductResponse) Reset() {
+	*x = UpdateProductResponse{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductResponse) ProtoMessage() {}
+
+func (x *UpdateProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateProductResponse) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateProductResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+func (x *UpdateProductResponse) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *UpdateProductResponse) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *UpdateProductResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateProductResponse) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+type GetProductByBarcodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Barcode       string                 `protobuf:"bytes,1,opt,name=barcode,proto3" json:"barcode,omitempty"` // EAN-13, 13 digit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductByBarcodeRequest) Reset() {
+	*x = GetProductByBarcodeRequest{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductByBarcodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductByBarcodeRequest) ProtoMessage() {}
+
+func (x *GetProductByBarcodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductByBarcodeRequest.ProtoReflect.Descriptor instead.
+func (*GetProductByBarcodeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetProductByBarcodeRequest) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+type ProductDetail struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+	Price         int32  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"` // Major unit (pecahan dibuang), pakai price_money
+	Stock         int32  `protobuf:"varint,4,opt,name=stock,proto3" json:"stock,omitempty"` // Stok tersedia
+	Sku           string `protobuf:"bytes,5,opt,name=sku,proto3" json:"sku,omitempty"`      // Kosong jika belum diisi
+	Barcode       string `protobuf:"bytes,6,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	CategoryId    int32  `protobuf:"varint,7,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"` // 0 = tanpa kategori
+	Version       int32  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	PriceMoney    *Money `protobuf:"bytes,9,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductDetail) Reset() {
+	*x = ProductDetail{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductDetail) ProtoMessage() {}
+
+func (x *ProductDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductDetail.ProtoReflect.Descriptor instead.
+func (*ProductDetail) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProductDetail) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductDetail) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in proto/inventory/inventory.proto.
+func (x *ProductDetail) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ProductDetail) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *ProductDetail) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductDetail) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+func (x *ProductDetail) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *ProductDetail) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ProductDetail) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+type ListTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`          // 0 = semua user
+	ProductId     int32                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"` // 0 = semua produk
+	From          string                 `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`                             // RFC 3339, kosong = tanpa batas
+	To            string                 `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`                                 // RFC 3339 (eksklusif), kosong = tanpa batas
+	MinTotal      *Money                 `protobuf:"bytes,5,opt,name=min_total,json=minTotal,proto3" json:"min_total,omitempty"`
+	MaxTotal      *Money                 `protobuf:"bytes,6,opt,name=max_total,json=maxTotal,proto3" json:"max_total,omitempty"`
+	Cursor        string                 `protobuf:"bytes,7,opt,name=cursor,proto3" json:"cursor,omitempty"` // next_cursor dari halaman sebelumnya
+	Limit         int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`  // Default 20, max 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTransactionsRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetMinTotal() *Money {
+	if x != nil {
+		return x.MinTotal
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetMaxTotal() *Money {
+	if x != nil {
+		return x.MaxTotal
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Transaction struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId         int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId      int32                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName    string                 `protobuf:"bytes,4,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Quantity       int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`           // Dalam satuan dasar
+	UnitId         int32                  `protobuf:"varint,6,opt,name=unit_id,json=unitId,proto3" json:"unit_id,omitempty"` // 0 = satuan dasar
+	UnitName       string                 `protobuf:"bytes,7,opt,name=unit_name,json=unitName,proto3" json:"unit_name,omitempty"`
+	UnitQuantity   int32                  `protobuf:"varint,8,opt,name=unit_quantity,json=unitQuantity,proto3" json:"unit_quantity,omitempty"`
+	LocationId     int32                  `protobuf:"varint,9,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+	Subtotal       *Money                 `protobuf:"bytes,10,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	DiscountAmount *Money                 `protobuf:"bytes,11,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	TaxRateBp      int32                  `protobuf:"varint,12,opt,name=tax_rate_bp,json=taxRateBp,proto3" json:"tax_rate_bp,omitempty"`
+	TaxInclusive   bool                   `protobuf:"varint,13,opt,name=tax_inclusive,json=taxInclusive,proto3" json:"tax_inclusive,omitempty"`
+	TaxAmount      *Money                 `protobuf:"bytes,14,opt,name=tax_amount,json=taxAmount,proto3" json:"tax_amount,omitempty"`
+	TotalPrice     *Money                 `protobuf:"bytes,15,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	CreatedAt      string                 `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Transaction) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Transaction) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Transaction) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Transaction) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Transaction) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Transaction) GetUnitId() int32 {
+	if x != nil {
+		return x.UnitId
+	}
+	return 0
+}
+
+func (x *Transaction) GetUnitName() string {
+	if x != nil {
+		return x.UnitName
+	}
+	return ""
+}
+
+func (x *Transaction) GetUnitQuantity() int32 {
+	if x != nil {
+		return x.UnitQuantity
+	}
+	return 0
+}
+
+func (x *Transaction) GetLocationId() int32 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+func (x *Transaction) GetSubtotal() *Money {
+	if x != nil {
+		return x.Subtotal
+	}
+	return nil
+}
+
+func (x *Transaction) GetDiscountAmount() *Money {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return nil
+}
+
+func (x *Transaction) GetTaxRateBp() int32 {
+	if x != nil {
+		return x.TaxRateBp
+	}
+	return 0
+}
+
+func (x *Transaction) GetTaxInclusive() bool {
+	if x != nil {
+		return x.TaxInclusive
+	}
+	return false
+}
+
+func (x *Transaction) GetTaxAmount() *Money {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return nil
+}
+
+func (x *Transaction) GetTotalPrice() *Money {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return nil
+}
+
+func (x *Transaction) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Kosong = halaman terakhir
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_proto_inventory_inventory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_inventory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_inventory_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListTransactionsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
 var File_proto_inventory_inventory_proto protoreflect.FileDescriptor
 
 const file_proto_inventory_inventory_proto_rawDesc = "" +
 	"\n" +
-	"\x1fproto/inventory/inventory.proto\x12\tinventory\"!\n" +
+	"\x1fproto/inventory/inventory.proto\x12\tinventory\"B\n" +
 	"\x0fGetStockRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x05R\x02id\"L\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1f\n" +
+	"\vlocation_id\x18\x02 \x01(\x05R\n" +
+	"locationId\"f\n" +
 	"\x10GetStockResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05stock\x18\x03 \x01(\x05R\x05stock2W\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\";\n" +
+	"\x05Money\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\"\xa1\x01\n" +
+	"\x14UpdateProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\x05price\x18\x03 \x01(\x05B\x02\x18\x01R\x05price\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\x121\n" +
+	"\vprice_money\x18\x05 \x01(\v2\x10.inventory.MoneyR\n" +
+	"priceMoney\"\xb8\x01\n" +
+	"\x15UpdateProductResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\x05price\x18\x03 \x01(\x05B\x02\x18\x01R\x05price\x12\x14\n" +
+	"\x05stock\x18\x04 \x01(\x05R\x05stock\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversion\x121\n" +
+	"\vprice_money\x18\x06 \x01(\v2\x10.inventory.MoneyR\n" +
+	"priceMoney\"6\n" +
+	"\x1aGetProductByBarcodeRequest\x12\x18\n" +
+	"\abarcode\x18\x01 \x01(\tR\abarcode\"\xfd\x01\n" +
+	"\rProductDetail\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\x05price\x18\x03 \x01(\x05B\x02\x18\x01R\x05price\x12\x14\n" +
+	"\x05stock\x18\x04 \x01(\x05R\x05stock\x12\x10\n" +
+	"\x03sku\x18\x05 \x01(\tR\x03sku\x12\x18\n" +
+	"\abarcode\x18\x06 \x01(\tR\abarcode\x12\x1f\n" +
+	"\vcategory_id\x18\a \x01(\x05R\n" +
+	"categoryId\x12\x18\n" +
+	"\aversion\x18\b \x01(\x05R\aversion\x121\n" +
+	"\vprice_money\x18\t \x01(\v2\x10.inventory.MoneyR\n" +
+	"priceMoney\"\x81\x02\n" +
+	"\x17ListTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x05R\tproductId\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\x12-\n" +
+	"\tmin_total\x18\x05 \x01(\v2\x10.inventory.MoneyR\bminTotal\x12-\n" +
+	"\tmax_total\x18\x06 \x01(\v2\x10.inventory.MoneyR\bmaxTotal\x12\x16\n" +
+	"\x06cursor\x18\a \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limit\"\xc1\x04\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\x05R\tproductId\x12!\n" +
+	"\fproduct_name\x18\x04 \x01(\tR\vproductName\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x17\n" +
+	"\aunit_id\x18\x06 \x01(\x05R\x06unitId\x12\x1b\n" +
+	"\tunit_name\x18\a \x01(\tR\bunitName\x12#\n" +
+	"\runit_quantity\x18\b \x01(\x05R\funitQuantity\x12\x1f\n" +
+	"\vlocation_id\x18\t \x01(\x05R\n" +
+	"locationId\x12,\n" +
+	"\bsubtotal\x18\n" +
+	" \x01(\v2\x10.inventory.MoneyR\bsubtotal\x129\n" +
+	"\x0fdiscount_amount\x18\v \x01(\v2\x10.inventory.MoneyR\x0ediscountAmount\x12\x1e\n" +
+	"\vtax_rate_bp\x18\f \x01(\x05R\ttaxRateBp\x12#\n" +
+	"\rtax_inclusive\x18\r \x01(\bR\ftaxInclusive\x12/\n" +
+	"\n" +
+	"tax_amount\x18\x0e \x01(\v2\x10.inventory.MoneyR\ttaxAmount\x121\n" +
+	"\vtotal_price\x18\x0f \x01(\v2\x10.inventory.MoneyR\n" +
+	"totalPrice\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\tR\tcreatedAt\"w\n" +
+	"\x18ListTransactionsResponse\x12:\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x16.inventory.TransactionR\ftransactions\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor2\xe0\x02\n" +
 	"\x10InventoryService\x12C\n" +
-	"\bGetStock\x12\x1a.inventory.GetStockRequest\x1a\x1b.inventory.GetStockResponseB\x1cZ\x1aphase3-api-architecture/pbb\x06proto3"
+	"\bGetStock\x12\x1a.inventory.GetStockRequest\x1a\x1b.inventory.GetStockResponse\x12R\n" +
+	"\rUpdateProduct\x12\x1f.inventory.UpdateProductRequest\x1a .inventory.UpdateProductResponse\x12V\n" +
+	"\x13GetProductByBarcode\x12%.inventory.GetProductByBarcodeRequest\x1a\x18.inventory.ProductDetail\x12[\n" +
+	"\x10ListTransactions\x12\".inventory.ListTransactionsRequest\x1a#.inventory.ListTransactionsResponseB\x1cZ\x1aphase3-api-architecture/pbb\x06proto3"
 
 var (
 	file_proto_inventory_inventory_proto_rawDescOnce sync.Once
@@ -152,19 +930,43 @@ func file_proto_inventory_inventory_proto_rawDescGZIP() []byte {
 	return file_proto_inventory_inventory_proto_rawDescData
 }
 
-var file_proto_inventory_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_inventory_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_proto_inventory_inventory_proto_goTypes = []any{
-	(*GetStockRequest)(nil),  // 0: inventory.GetStockRequest
-	(*GetStockResponse)(nil), // 1: inventory.GetStockResponse
+	(*GetStockRequest)(nil),            // 0: inventory.GetStockRequest
+	(*GetStockResponse)(nil),           // 1: inventory.GetStockResponse
+	(*Money)(nil),                      // 2: inventory.Money
+	(*UpdateProductRequest)(nil),       // 3: inventory.UpdateProductRequest
+	(*UpdateProductResponse)(nil),      // 4: inventory.UpdateProductResponse
+	(*GetProductByBarcodeRequest)(nil), // 5: inventory.GetProductByBarcodeRequest
+	(*ProductDetail)(nil),              // 6: inventory.ProductDetail
+	(*ListTransactionsRequest)(nil),    // 7: inventory.ListTransactionsRequest
+	(*Transaction)(nil),                // 8: inventory.Transaction
+	(*ListTransactionsResponse)(nil),   // 9: inventory.ListTransactionsResponse
 }
 var file_proto_inventory_inventory_proto_depIdxs = []int32{
-	0, // 0: inventory.InventoryService.GetStock:input_type -> inventory.GetStockRequest
-	1, // 1: inventory.InventoryService.GetStock:output_type -> inventory.GetStockResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: inventory.UpdateProductRequest.price_money:type_name -> inventory.Money
+	2,  // 1: inventory.UpdateProductResponse.price_money:type_name -> inventory.Money
+	2,  // 2: inventory.ProductDetail.price_money:type_name -> inventory.Money
+	2,  // 3: inventory.ListTransactionsRequest.min_total:type_name -> inventory.Money
+	2,  // 4: inventory.ListTransactionsRequest.max_total:type_name -> inventory.Money
+	2,  // 5: inventory.Transaction.subtotal:type_name -> inventory.Money
+	2,  // 6: inventory.Transaction.discount_amount:type_name -> inventory.Money
+	2,  // 7: inventory.Transaction.tax_amount:type_name -> inventory.Money
+	2,  // 8: inventory.Transaction.total_price:type_name -> inventory.Money
+	8,  // 9: inventory.ListTransactionsResponse.transactions:type_name -> inventory.Transaction
+	0,  // 10: inventory.InventoryService.GetStock:input_type -> inventory.GetStockRequest
+	3,  // 11: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateProductRequest
+	5,  // 12: inventory.InventoryService.GetProductByBarcode:input_type -> inventory.GetProductByBarcodeRequest
+	7,  // 13: inventory.InventoryService.ListTransactions:input_type -> inventory.ListTransactionsRequest
+	1,  // 14: inventory.InventoryService.GetStock:output_type -> inventory.GetStockResponse
+	4,  // 15: inventory.InventoryService.UpdateProduct:output_type -> inventory.UpdateProductResponse
+	6,  // 16: inventory.InventoryService.GetProductByBarcode:output_type -> inventory.ProductDetail
+	9,  // 17: inventory.InventoryService.ListTransactions:output_type -> inventory.ListTransactionsResponse
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_inventory_inventory_proto_init() }
@@ -178,7 +980,7 @@ func file_proto_inventory_inventory_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_inventory_inventory_proto_rawDesc), len(file_proto_inventory_inventory_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,190 @@
+// Package export menulis data tabular (header + baris) ke CSV, XLSX atau NDJSON secara streaming.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"phase3-api-architecture/pkg/xlsx"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatNDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("format harus csv, xlsx atau ndjson")
+
+// Decimal: angka desimal presisi tetap (nominal uang). Di XLSX jadi sel angka, di NDJSON tetap string.
+type Decimal string
+
+// Writer menulis baris satu per satu; Flush dipanggil berkala, Close sekali di akhir
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter membuat writer sesuai format. sheet hanya dipakai XLSX.
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return &xlsxWriter{w: w, sheet: sheet}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ContentType untuk header HTTP, format sudah divalidasi NewWriter
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/x-ndjson"
+}
+
+// ValidFormat: format yang didukung
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX || format == FormatNDJSON
+}
+
+// text: nilai sel sebagai teks untuk CSV
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case Decimal:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	row []string
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	c.row = c.row[:0]
+	for _, v := range values {
+		c.row = append(c.row, text(v))
+	}
+	return c.w.Write(c.row)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+// xlsxWriter: workbook baru dibuat saat header ditulis (nama sheet sudah diketahui dari awal)
+type xlsxWriter struct {
+	w     io.Writer
+	sheet string
+	x     *xlsx.Writer
+}
+
+func (x *xlsxWriter) WriteHeader(columns []string) error {
+	var err error
+	if x.x, err = xlsx.NewWriter(x.w, x.sheet); err != nil {
+		return err
+	}
+	row := make([]any, len(columns))
+	for i, c := range columns {
+		row[i] = c
+	}
+	return x.x.WriteRow(row)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		if d, ok := v.(Decimal); ok {
+			values[i] = xlsx.Number(d)
+		}
+	}
+	return x.x.WriteRow(values)
+}
+
+func (x *xlsxWriter) Flush() error {
+	return x.x.Flush()
+}
+
+func (x *xlsxWriter) Close() error {
+	return x.x.Close()
+}
+
+// ndjsonWriter: satu objek JSON per baris, key = nama kolom
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (n *ndjsonWriter) WriteHeader(columns []string) error {
+	n.columns = columns
+	return nil
+}
+
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	n.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(n.columns[i])
+		n.w.Write(key)
+		n.w.WriteByte(':')
+
+		var val []byte
+		var err error
+		if d, ok := v.(Decimal); ok {
+			val, err = json.Marshal(string(d))
+		} else {
+			val, err = json.Marshal(v)
+		}
+		if err != nil {
+			return err
+		}
+		n.w.Write(val)
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.w.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,31 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAll(t *testing.T, format string) string {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, "Produk")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader([]string{"sku", "price", "stock", "deleted_at"}))
+	require.NoError(t, w.WriteRow([]any{"BRS-5", Decimal("65000.50"), 10, nil}))
+	require.NoError(t, w.WriteRow([]any{"A,B", Decimal("1.00"), 0, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}))
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestWriters(t *testing.T) {
+	assert.Equal(t, "sku,price,stock,deleted_at\nBRS-5,65000.50,10,\n\"A,B\",1.00,0,2026-01-02T03:04:05Z\n", writeAll(t, FormatCSV))
+	assert.Equal(t, `{"sku":"BRS-5","price":"65000.50","stock":10,"deleted_at":null}`+"\n"+
+		`{"sku":"A,B","price":"1.00","stock":0,"deleted_at":"2026-01-02T03:04:05Z"}`+"\n", writeAll(t, FormatNDJSON))
+	assert.NotEmpty(t, writeAll(t, FormatXLSX))
+
+	_, err := NewWriter("pdf", &bytes.Buffer{}, "")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
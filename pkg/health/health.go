@@ -0,0 +1,155 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc mengecek satu dependency, return error kalau dependency bermasalah
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker menjalankan semua check secara paralel dengan timeout per check,
+// lalu menyimpan hasilnya selama cacheTTL supaya probe K8s tidak membanjiri DB
+type Checker struct {
+	checks   []namedCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	last   Report
+	expiry time.Time
+}
+
+func NewChecker(timeout, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Register menambahkan dependency yang ikut dicek oleh /readyz
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, fn: fn})
+}
+
+// Check mengembalikan report dari cache jika masih berlaku.
+// Lock ditahan selama pengecekan, jadi probe yang datang bersamaan cukup menunggu satu hasil.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expiry) {
+		return c.last
+	}
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	// Hasil di-cache untuk probe lain, jadi jangan ikut batal kalau client putus duluan
+	ctx = context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	var resMu sync.Mutex
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk namedCheck) {
+			defer wg.Done()
+			result := c.run(ctx, chk.fn)
+
+			resMu.Lock()
+			report.Checks[chk.name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+			}
+			resMu.Unlock()
+		}(chk)
+	}
+	wg.Wait()
+
+	c.last = report
+	c.expiry = time.Now().Add(c.cacheTTL)
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, fn CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx)
+	}()
+
+	// Beberapa client (contoh: sarama) tidak menghormati context,
+	// jadi timeout tetap dipaksa dari sini
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler hanya memastikan proses masih bisa melayani HTTP.
+// Jangan cek dependency di sini, kalau DB mati pod tidak perlu di-restart.
+func LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+}
+
+// ReadinessHandler mengembalikan 503 jika ada dependency yang down,
+// sehingga pod dicabut dari load balancer sampai pulih
+func (c *Checker) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	code := http.StatusOK
+	if report.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_CachesResult(t *testing.T) {
+	var calls int32
+	checker := NewChecker(time.Second, time.Minute)
+	checker.Register("db", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	checker.Check(context.Background())
+	report := checker.Check(context.Background())
+
+	// Check kedua harus diambil dari cache
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, StatusUp, report.Status)
+}
+
+func TestChecker_TimeoutMarksDown(t *testing.T) {
+	checker := NewChecker(10*time.Millisecond, 0)
+	checker.Register("lambat", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	checker.Register("sehat", func(ctx context.Context) error { return nil })
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusDown, report.Checks["lambat"].Status)
+	assert.Equal(t, StatusUp, report.Checks["sehat"].Status)
+}
+
+func TestReadinessHandler_ServiceUnavailable(t *testing.T) {
+	checker := NewChecker(time.Second, 0)
+	checker.Register("redis", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	w := httptest.NewRecorder()
+	checker.ReadinessHandler(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "connection refused")
+}
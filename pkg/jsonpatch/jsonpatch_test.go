@@ -0,0 +1,50 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	doc := `{"name":"Kopi","price":15000,"supplier_id":3,"tags":{"a":1,"b":2}}`
+	patch := `{"price":0,"supplier_id":null,"tags":{"b":null,"c":3}}`
+
+	out, err := MergePatch([]byte(doc), []byte(patch))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Kopi","price":0,"tags":{"a":1,"c":3}}`, string(out))
+}
+
+func TestApply(t *testing.T) {
+	doc := `{"name":"Kopi","price":15000,"stock":5,"list":[1,2]}`
+	patch := `[
+		{"op":"test","path":"/price","value":15000},
+		{"op":"replace","path":"/stock","value":0},
+		{"op":"remove","path":"/name"},
+		{"op":"add","path":"/list/-","value":3},
+		{"op":"move","from":"/list/0","path":"/first"},
+		{"op":"copy","from":"/price","path":"/old_price"}
+	]`
+
+	out, err := Apply([]byte(doc), []byte(patch))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price":15000,"old_price":15000,"stock":0,"list":[2,3],"first":1}`, string(out))
+}
+
+func TestApply_Errors(t *testing.T) {
+	doc := []byte(`{"price":15000}`)
+
+	_, err := Apply(doc, []byte(`[{"op":"test","path":"/price","value":1}]`))
+	assert.ErrorIs(t, err, ErrTestFailed)
+
+	_, err = Apply(doc, []byte(`[{"op":"replace","path":"/stock","value":1}]`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+
+	_, err = Apply(doc, []byte(`[{"op":"upsert","path":"/price","value":1}]`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+
+	_, err = Apply(doc, []byte(`{"price":1}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
@@ -0,0 +1,289 @@
+// Package money menyimpan nominal uang sebagai integer minor unit (contoh: sen) beserta kode mata uangnya,
+// dengan aritmatika yang mengecek overflow dan konversi kurs berbasis math/big (tanpa float).
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency dipakai jika input tidak menyebut mata uang
+const DefaultCurrency = "IDR"
+
+var (
+	ErrOverflow         = errors.New("nominal uang terlalu besar")
+	ErrCurrencyMismatch = errors.New("mata uang tidak sama")
+	ErrUnknownCurrency  = errors.New("mata uang tidak didukung")
+	ErrInvalidAmount    = errors.New("format nominal uang tidak valid")
+)
+
+// exponents: jumlah digit minor unit per mata uang (ISO 4217)
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// Exponent mengembalikan jumlah digit minor unit mata uang
+func Exponent(currency string) (int, error) {
+	exp, ok := exponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exp, nil
+}
+
+// Supported mengecek apakah kode mata uang dikenal
+func Supported(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Money: Amount dalam minor unit, contoh Rp15.000,00 = {1500000, "IDR"}
+// Tag json/swaggertype hanya untuk dokumentasi, encoding sebenarnya lewat MarshalJSON (amount string desimal major unit).
+type Money struct {
+	Amount   int64  `json:"amount" swaggertype:"string" example:"15000.00"`
+	Currency string `json:"currency" example:"IDR"`
+}
+
+// New membuat Money dari minor unit
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// FromMajor membuat Money dari nominal bulat major unit, contoh FromMajor(15000, "IDR") = Rp15.000,00
+func FromMajor(units int64, currency string) (Money, error) {
+	exp, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(units, currency).Mul(pow10(exp).Int64())
+}
+
+// Parse membaca nominal desimal dalam major unit ("15000", "15000.5", "-2.25").
+// Digit desimal melebihi exponent mata uang ditolak supaya tidak ada pembulatan diam-diam.
+func Parse(s, currency string) (Money, error) {
+	exp, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if whole == "" || (hasDot && frac == "") || len(frac) > exp || !digits(whole) || !digits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	var amount int64
+	for _, c := range whole + frac {
+		if amount > (math.MaxInt64-int64(c-'0'))/10 {
+			return Money{}, ErrOverflow
+		}
+		amount = amount*10 + int64(c-'0')
+	}
+	if neg {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal menulis nominal dalam major unit, contoh "15000.00"
+func (m Money) Decimal() string {
+	exp, err := Exponent(m.Currency)
+	if err != nil {
+		exp = 0
+	}
+
+	abs := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+	if len(abs) <= exp {
+		abs = strings.Repeat("0", exp-len(abs)+1) + abs
+	}
+	s := abs
+	if exp > 0 {
+		s = abs[:len(abs)-exp] + "." + abs[len(abs)-exp:]
+	}
+	if m.Amount < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Major mengembalikan bagian bulat major unit (pecahan dibuang), untuk field lama yang belum mengenal minor unit
+func (m Money) Major() int64 {
+	exp, err := Exponent(m.Currency)
+	if err != nil {
+		return m.Amount
+	}
+	return m.Amount / pow10(exp).Int64()
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add menjumlahkan dua nominal dengan mata uang yang sama
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub mengurangi dua nominal dengan mata uang yang sama
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul mengalikan nominal dengan jumlah barang
+func (m Money) Mul(n int64) (Money, error) {
+	r := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(n))
+	if !r.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: r.Int64(), Currency: m.Currency}, nil
+}
+
+// MulDiv menghitung m x num / den dibulatkan ke bawah (ke arah nol), contoh potongan persen: m.MulDiv(15, 100)
+func (m Money) MulDiv(num, den int64) (Money, error) {
+	if den == 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	r := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	r.Quo(r, big.NewInt(den))
+	if !r.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: r.Int64(), Currency: m.Currency}, nil
+}
+
+// Min mengembalikan nominal yang lebih kecil (mata uang dianggap sama)
+func (m Money) Min(o Money) Money {
+	if o.Amount < m.Amount {
+		return o
+	}
+	return m
+}
+
+// Convert mengubah ke mata uang lain dengan kurs rate (1 unit m.Currency = rate unit currency),
+// dibulatkan half-up ke minor unit mata uang tujuan.
+func (m Money) Convert(rate *big.Rat, currency string) (Money, error) {
+	fromExp, err := Exponent(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toExp, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// amount / 10^fromExp * rate * 10^toExp
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(toExp), pow10(fromExp)))
+
+	r := roundHalfUp(v)
+	if !r.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: r.Int64(), Currency: currency}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundHalfUp membulatkan ke integer terdekat, .5 menjauh dari nol
+func roundHalfUp(v *big.Rat) *big.Int {
+	num := new(big.Int).Abs(v.Num())
+	q, rem := new(big.Int).QuoRem(num, v.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+// jsonMoney: amount ditulis sebagai string desimal major unit supaya tidak lewat float di client
+type jsonMoney struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON menerima {"amount": "15000.00", "currency": "IDR"} (amount boleh string / number),
+// atau angka / string saja (format lama) yang dianggap DefaultCurrency jika currency kosong.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw jsonMoney
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case trimmed == "null":
+		return nil
+	case strings.HasPrefix(trimmed, "{"):
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+		}
+	default:
+		if err := json.Unmarshal(data, &raw.Amount); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+		}
+	}
+
+	currency := strings.ToUpper(raw.Currency)
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	parsed, err := Parse(raw.Amount.String(), currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,97 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAndDecimal(t *testing.T) {
+	m, err := Parse("15000.5", "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 1500050, Currency: "IDR"}, m)
+	assert.Equal(t, "15000.50", m.Decimal())
+
+	m, err = Parse("-0.07", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.07", m.Decimal())
+
+	// Lebih dari 2 digit desimal ditolak, JPY tidak punya minor unit
+	_, err = Parse("1.005", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("1.5", "JPY")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("1", "XXX")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+	_, err = Parse("99999999999999999999", "IDR")
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestMajor(t *testing.T) {
+	m, err := FromMajor(15000, "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, New(1500000, "IDR"), m)
+	assert.Equal(t, int64(15000), New(1500099, "IDR").Major())
+	assert.Equal(t, int64(500), New(500, "JPY").Major())
+
+	_, err = FromMajor(math.MaxInt64/10, "IDR")
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestArithmetic(t *testing.T) {
+	a := New(1000, "IDR")
+
+	sum, err := a.Add(New(250, "IDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1250), sum.Amount)
+
+	_, err = a.Add(New(1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, "IDR").Add(New(1, "IDR"))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MaxInt64/2, "IDR").Mul(3)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	// 15% dari 999 dibulatkan ke bawah, perkalian antara tidak overflow
+	pct, err := New(999, "IDR").MulDiv(15, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(149), pct.Amount)
+	pct, err = New(math.MaxInt64, "IDR").MulDiv(50, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64/2), pct.Amount)
+}
+
+func TestConvert(t *testing.T) {
+	// Rp15.000 x 0.0000625 = USD 0.9375 -> 0.94
+	rate, _ := new(big.Rat).SetString("0.0000625")
+	usd, err := New(1500000, "IDR").Convert(rate, "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 94, Currency: "USD"}, usd)
+
+	// Ke JPY (tanpa minor unit)
+	rate, _ = new(big.Rat).SetString("0.0095")
+	jpy, err := New(1500000, "IDR").Convert(rate, "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 143, Currency: "JPY"}, jpy)
+}
+
+func TestJSON(t *testing.T) {
+	out, err := json.Marshal(New(1500000, "IDR"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"15000.00","currency":"IDR"}`, string(out))
+
+	var m Money
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"12.5","currency":"usd"}`), &m))
+	assert.Equal(t, New(1250, "USD"), m)
+
+	// Format lama: angka saja = IDR
+	assert.NoError(t, json.Unmarshal([]byte(`15000`), &m))
+	assert.Equal(t, New(1500000, "IDR"), m)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"abc"}`), &m))
+}
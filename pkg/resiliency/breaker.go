@@ -1,6 +1,7 @@
 package resiliency
 
 import (
+	"context"
 	"errors"
 	"log"
 	"time"
@@ -33,3 +34,13 @@ func NewDatabaseBreaker(name string) *gobreaker.CircuitBreaker {
 	}
 	return gobreaker.NewCircuitBreaker(settings)
 }
+
+// BreakerCheck dipakai health check: dianggap tidak sehat selama sirkuit terbuka
+func BreakerCheck(cb *gobreaker.CircuitBreaker) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if cb.State() == gobreaker.StateOpen {
+			return ErrServiceUnavailbale
+		}
+		return nil
+	}
+}
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// Aksi _bulk yang dipakai worker
+const (
+	BulkIndex  = "index"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// BulkItem: satu aksi _bulk. Body = dokumen (index) atau {"doc"/"script"} (update), nil untuk delete.
+type BulkItem struct {
+	Action string
+	Index  string
+	ID     string // kosong = ES yang membuat ID (hanya untuk index)
+	Body   interface{}
+}
+
+type encodedItem struct {
+	action string
+	doc    string // index/_id, kosong kalau ID dibuat ES
+	lines  []byte // baris aksi + baris body (NDJSON)
+}
+
+// Bulk mengumpulkan aksi lalu mengirimnya sekaligus lewat _bulk. Item yang gagal karena
+// sementara (429 / 5xx) dikirim ulang dengan backoff, bersama semua aksi sesudahnya untuk
+// dokumen yang sama supaya urutan per _id tetap; yang gagal permanen (mis. mapping error)
+// dicatat lalu dibuang supaya tidak memblokir antrean. Tidak aman dipakai paralel.
+type Bulk struct {
+	es *elasticsearch.Client
+
+	MaxActions   int // flush kalau jumlah aksi mencapai ini
+	MaxBytes     int // atau ukuran body mencapai ini
+	MaxRetries   int
+	RetryBackoff time.Duration // backoff awal, dobel tiap percobaan
+
+	items []encodedItem
+	size  int
+}
+
+func NewBulk(es *elasticsearch.Client, maxActions, maxBytes int) *Bulk {
+	return &Bulk{
+		es:           es,
+		MaxActions:   maxActions,
+		MaxBytes:     maxBytes,
+		MaxRetries:   3,
+		RetryBackoff: 200 * time.Millisecond,
+	}
+}
+
+// Add menambah aksi ke buffer (belum dikirim)
+func (b *Bulk) Add(item BulkItem) error {
+	meta := map[string]interface{}{"_index": item.Index}
+	if item.ID != "" {
+		meta["_id"] = item.ID
+	}
+	if item.Action == BulkUpdate {
+		meta["retry_on_conflict"] = 3
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(map[string]interface{}{item.Action: meta}); err != nil {
+		return err
+	}
+	if item.Action != BulkDelete {
+		if err := enc.Encode(item.Body); err != nil {
+			return err
+		}
+	}
+	var doc string
+	if item.ID != "" {
+		doc = item.Index + "/" + item.ID
+	}
+	b.items = append(b.items, encodedItem{action: item.Action, doc: doc, lines: buf.Bytes()})
+	b.size += buf.Len()
+	return nil
+}
+
+// Len: jumlah aksi di buffer
+func (b *Bulk) Len() int {
+	return len(b.items)
+}
+
+// Full: buffer sudah mencapai batas jumlah atau ukuran
+func (b *Bulk) Full() bool {
+	return len(b.items) >= b.MaxActions || b.size >= b.MaxBytes
+}
+
+// Flush mengirim semua aksi di buffer. nil berarti semua aksi sudah diterima ES (atau gagal
+// permanen dan dibuang); error berarti masih ada yang gagal setelah MaxRetries dan tetap di buffer.
+func (b *Bulk) Flush(ctx context.Context) error {
+	backoff := b.RetryBackoff
+	for attempt := 0; len(b.items) > 0; attempt++ {
+		retry, err := b.send(ctx, b.items)
+		if err != nil {
+			retry = b.items // request-nya sendiri gagal, kirim ulang semua
+		}
+		b.items = retry
+		b.size = 0
+		for _, it := range retry {
+			b.size += len(it.lines)
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt >= b.MaxRetries {
+			if err == nil {
+				err = fmt.Errorf("%d aksi masih gagal", len(retry))
+			}
+			return fmt.Errorf("bulk gagal setelah %d percobaan: %w", attempt+1, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+	return nil
+}
+
+// send mengirim satu request _bulk dan mengembalikan item yang perlu dicoba lagi
+func (b *Bulk) send(ctx context.Context, items []encodedItem) ([]encodedItem, error) {
+	var body bytes.Buffer
+	for _, it := range items {
+		body.Write(it.lines)
+	}
+
+	res, err := b.es.Bulk(bytes.NewReader(body.Bytes()), b.es.Bulk.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("bulk: %s", res.Status())
+	}
+
+	var out struct {
+		Errors bool                          `json:"errors"`
+		Items  []map[string]bulkItemResponse `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if !out.Errors {
+		return nil, nil
+	}
+	if len(out.Items) != len(items) {
+		return nil, fmt.Errorf("bulk: %d hasil untuk %d aksi", len(out.Items), len(items))
+	}
+
+	var retry []encodedItem
+	// Dokumen yang punya aksi di antrean retry. Aksi berikutnya untuk dokumen itu ikut dikirim
+	// ulang walaupun sudah berhasil; kalau tidak, index yang kena 429 lalu delete yang sukses
+	// akan mengindex ulang produk yang sudah dihapus.
+	retried := map[string]bool{}
+	for i, result := range out.Items {
+		if doc := items[i].doc; doc != "" && retried[doc] {
+			retry = append(retry, items[i])
+			continue
+		}
+		r := result[items[i].action]
+		switch {
+		case r.Status < 300:
+		case r.Status == 404 && items[i].action != BulkIndex:
+			// Hapus / update dokumen yang memang belum ada, anggap beres
+		case r.Status == 429 || r.Status >= 500:
+			retry = append(retry, items[i])
+			if items[i].doc != "" {
+				retried[items[i].doc] = true
+			}
+		default:
+			log.Printf("[ES-BULK] %s %s/%s gagal permanen (%d): %s", items[i].action, r.Index, r.ID, r.Status, r.Error)
+		}
+	}
+	return retry, nil
+}
+
+type bulkItemResponse struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkFlushRetriesTransientFailures(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esInfo(w, r) {
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(raw))
+		if len(bodies) == 1 {
+			io.WriteString(w, `{"errors":true,"items":[
+				{"index":{"_index":"products-v2","_id":"1","status":201}},
+				{"index":{"_index":"products-v2","_id":"2","status":429,"error":{"type":"es_rejected_execution_exception"}}},
+				{"index":{"_index":"products-v2","_id":"3","status":400,"error":{"type":"mapper_parsing_exception"}}},
+				{"delete":{"_index":"products-v2","_id":"4","status":404}}
+			]}`)
+			return
+		}
+		io.WriteString(w, `{"errors":false,"items":[{"index":{"_index":"products-v2","_id":"2","status":200}}]}`)
+	}))
+	defer srv.Close()
+
+	es, err := NewClient(srv.URL)
+	assert.NoError(t, err)
+	bulk := NewBulk(es, 10, 1<<20)
+	bulk.RetryBackoff = time.Millisecond
+
+	for _, id := range []string{"1", "2", "3"} {
+		assert.NoError(t, bulk.Add(BulkItem{Action: BulkIndex, Index: ProductIndex, ID: id, Body: map[string]string{"name": "Kopi " + id}}))
+	}
+	assert.NoError(t, bulk.Add(BulkItem{Action: BulkDelete, Index: ProductIndex, ID: "4"}))
+	assert.Equal(t, 4, bulk.Len())
+	assert.False(t, bulk.Full())
+
+	assert.NoError(t, bulk.Flush(context.Background()))
+	assert.Equal(t, 0, bulk.Len())
+	assert.Len(t, bodies, 2)
+	// Hanya item 429 yang dikirim ulang
+	assert.Equal(t, 2, strings.Count(bodies[1], "\n"))
+	assert.Contains(t, bodies[1], `"_id":"2"`)
+}
+
+func TestBulkFlushRetryKeepsOrderPerDocument(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esInfo(w, r) {
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(raw))
+		if len(bodies) == 1 {
+			io.WriteString(w, `{"errors":true,"items":[
+				{"index":{"_index":"products-v2","_id":"1","status":429}},
+				{"index":{"_index":"products-v2","_id":"2","status":201}},
+				{"delete":{"_index":"products-v2","_id":"1","status":200}}
+			]}`)
+			return
+		}
+		io.WriteString(w, `{"errors":false,"items":[]}`)
+	}))
+	defer srv.Close()
+
+	es, _ := NewClient(srv.URL)
+	bulk := NewBulk(es, 10, 1<<20)
+	bulk.RetryBackoff = time.Millisecond
+	bulk.Add(BulkItem{Action: BulkIndex, Index: ProductIndex, ID: "1", Body: map[string]string{"name": "Kopi"}})
+	bulk.Add(BulkItem{Action: BulkIndex, Index: ProductIndex, ID: "2", Body: map[string]string{"name": "Teh"}})
+	bulk.Add(BulkItem{Action: BulkDelete, Index: ProductIndex, ID: "1"})
+
+	assert.NoError(t, bulk.Flush(context.Background()))
+	assert.Len(t, bodies, 2)
+	// Delete produk 1 ikut dikirim ulang setelah index-nya, produk 2 tidak
+	retried := bodies[1]
+	assert.NotContains(t, retried, `"_id":"2"`)
+	assert.Less(t, strings.Index(retried, `{"index"`), strings.Index(retried, `{"delete"`))
+	assert.Equal(t, 3, strings.Count(retried, "\n"))
+}
+
+func TestBulkFlushGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esInfo(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	es, _ := NewClient(srv.URL)
+	bulk := NewBulk(es, 10, 1<<20)
+	bulk.RetryBackoff = time.Millisecond
+	bulk.MaxRetries = 2
+	bulk.Add(BulkItem{Action: BulkIndex, Index: ProductIndex, ID: "1", Body: map[string]string{}})
+
+	assert.Error(t, bulk.Flush(context.Background()))
+	// Item tetap di buffer, offset tidak boleh ditandai
+	assert.Equal(t, 1, bulk.Len())
+}
+
+// esInfo menjawab product check client (GET /) seperti ES 7.17
+func esInfo(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/" {
+		return false
+	}
+	io.WriteString(w, `{"version":{"number":"7.17.10","build_flavor":"default"},"tagline":"You Know, for Search"}`)
+	return true
+}
@@ -25,3 +25,11 @@ func InitES(address string) *elasticsearch.Client {
 	log.Println("✅ Terhubung ke Elasticsearch!")
 	return es
 }
+
+// NewClient membuat client tanpa cek koneksi, untuk pemakai yang ES-nya opsional (API).
+// Kalau ES mati, request akan error dan pemanggil bisa fallback.
+func NewClient(address string) (*elasticsearch.Client, error) {
+	return elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{address},
+	})
+}
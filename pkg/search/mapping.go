@@ -0,0 +1,226 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// ProductIndex adalah alias index produk yang diisi worker dari topic 'product-events'.
+// Alias menunjuk ke index berversi (products-v2, ...) supaya mapping / analyzer bisa diganti
+// lewat reindex tanpa mengubah pembaca & penulis.
+const ProductIndex = "products"
+
+// ProductIndexVersion dinaikkan setiap mapping, analyzer atau sinonim berubah; worker akan
+// membuat index versi baru, reindex dari versi lama lalu memindahkan alias.
+// Versi 1 = index lama "products" (bukan alias, mapping dinamis).
+const ProductIndexVersion = 2
+
+// ProductIndexName: nama index untuk versi tertentu
+func ProductIndexName(version int) string {
+	return fmt.Sprintf("%s-v%d", ProductIndex, version)
+}
+
+// ProductSynonyms: sinonim nama produk (Indonesia / Inggris / ejaan lain), format Solr.
+// Dipakai saat search saja, jadi mengubahnya cukup naikkan ProductIndexVersion.
+var ProductSynonyms = []string{
+	"minyak, oil",
+	"gula, sugar",
+	"susu, milk",
+	"kopi, coffee",
+	"teh, tea",
+	"beras, rice",
+	"telur, telor, egg",
+	"mie, mi, noodle",
+	"air mineral, mineral water",
+	"sabun, soap",
+	"tepung, flour",
+	"garam, salt",
+	"kecap, soy sauce",
+	"roti, bread",
+}
+
+// productAnalysis: analyzer nama produk. Index: lowercase, buang aksen, stopword & stemming
+// bahasa Indonesia ("minuman" -> "minum"). Search: sama ditambah sinonim (synonym_graph
+// hanya boleh di search analyzer).
+func productAnalysis() map[string]interface{} {
+	return map[string]interface{}{
+		"filter": map[string]interface{}{
+			"indonesian_stop":    map[string]interface{}{"type": "stop", "stopwords": "_indonesian_"},
+			"indonesian_stemmer": map[string]interface{}{"type": "stemmer", "language": "indonesian"},
+			"product_synonyms":   map[string]interface{}{"type": "synonym_graph", "synonyms": ProductSynonyms},
+		},
+		"analyzer": map[string]interface{}{
+			"product_name": map[string]interface{}{
+				"tokenizer": "standard",
+				"filter":    []string{"lowercase", "asciifolding", "indonesian_stop", "indonesian_stemmer"},
+			},
+			"product_name_search": map[string]interface{}{
+				"tokenizer": "standard",
+				"filter":    []string{"lowercase", "asciifolding", "product_synonyms", "indonesian_stop", "indonesian_stemmer"},
+			},
+		},
+	}
+}
+
+// productProperties: sku/barcode keyword supaya bisa exact match, category_id untuk filter kategori.
+// price = major unit (dibulatkan ke bawah), nominal persis di price_minor + currency.
+// suggest = field completion untuk autocomplete, bobotnya dari sales_count (lihat SuggestWeight).
+const productProperties = `{
+	"id":          {"type": "integer"},
+	"name":        {"type": "text", "analyzer": "product_name", "search_analyzer": "product_name_search",
+	                "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+	"price":       {"type": "long"},
+	"price_minor": {"type": "long"},
+	"currency":    {"type": "keyword"},
+	"stock":       {"type": "integer"},
+	"reserved":    {"type": "integer"},
+	"base_unit":   {"type": "keyword"},
+	"sku":         {"type": "keyword"},
+	"barcode":     {"type": "keyword"},
+	"category_id": {"type": "integer"},
+	"supplier_id": {"type": "integer"},
+	"version":     {"type": "integer"},
+	"sales_count": {"type": "integer"},
+	"suggest":     {"type": "completion"}
+}`
+
+// productIndexTemplate: template untuk semua index products-v*. dynamic=false: field lain di
+// dokumen tetap tersimpan di _source tapi tidak diindex (tidak ada tipe hasil tebakan ES).
+func productIndexTemplate() map[string]interface{} {
+	return map[string]interface{}{
+		"index_patterns": []string{ProductIndex + "-v*"},
+		"version":        ProductIndexVersion,
+		"template": map[string]interface{}{
+			"settings": map[string]interface{}{"analysis": productAnalysis()},
+			"mappings": map[string]interface{}{
+				"dynamic":    false,
+				"_meta":      map[string]interface{}{"version": ProductIndexVersion},
+				"properties": json.RawMessage(productProperties),
+			},
+		},
+	}
+}
+
+// EnsureProductIndex dipanggil saat worker start:
+//  1. membuat / memperbarui index template produk,
+//  2. membuat index versi sekarang kalau belum ada,
+//  3. reindex dari index lama (alias versi sebelumnya atau index "products" lama); field
+//     suggest dibangun ulang saat reindex karena dokumen lama belum tentu punya,
+//  4. memindahkan alias ke index baru. Index versi lama dibiarkan untuk rollback.
+//
+// Reindex terjadi sebelum worker mulai consume, jadi tidak ada tulisan yang tertinggal.
+// Tidak aman dijalankan paralel: pemanggil harus memegang lock (worker memakai advisory lock Postgres).
+func EnsureProductIndex(ctx context.Context, es *elasticsearch.Client) error {
+	body, _ := json.Marshal(productIndexTemplate())
+	res, err := es.Indices.PutIndexTemplate(ProductIndex, bytes.NewReader(body), es.Indices.PutIndexTemplate.WithContext(ctx))
+	if err := checkResponse(res, err, "index template"); err != nil {
+		return err
+	}
+
+	target := ProductIndexName(ProductIndexVersion)
+	current, legacy, err := productIndexTargets(ctx, es)
+	if err != nil {
+		return err
+	}
+	if len(current) == 1 && current[0] == target {
+		return nil
+	}
+
+	res, err = es.Indices.Exists([]string{target}, es.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode == 404 {
+		res, err = es.Indices.Create(target, es.Indices.Create.WithContext(ctx))
+		if err := checkResponse(res, err, "create "+target); err != nil {
+			return err
+		}
+	}
+
+	actions := []map[string]interface{}{}
+	for _, old := range current {
+		log.Printf("[ES] Reindex %s -> %s", old, target)
+		reindex, _ := json.Marshal(map[string]interface{}{
+			"source": map[string]interface{}{"index": old},
+			"dest":   map[string]interface{}{"index": target},
+			"script": map[string]interface{}{"source": suggestScript, "lang": "painless"},
+		})
+		res, err = es.Reindex(bytes.NewReader(reindex),
+			es.Reindex.WithContext(ctx),
+			es.Reindex.WithWaitForCompletion(true),
+			es.Reindex.WithRefresh(true),
+		)
+		if err := checkResponse(res, err, "reindex "+old); err != nil {
+			return err
+		}
+		if legacy {
+			// Index lama bernama sama dengan alias, harus dihapus (atomik bersama add alias)
+			actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": old}})
+		} else {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": old, "alias": ProductIndex}})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": target, "alias": ProductIndex, "is_write_index": true},
+	})
+
+	body, _ = json.Marshal(map[string]interface{}{"actions": actions})
+	res, err = es.Indices.UpdateAliases(bytes.NewReader(body), es.Indices.UpdateAliases.WithContext(ctx))
+	if err := checkResponse(res, err, "alias "+ProductIndex); err != nil {
+		return err
+	}
+	log.Printf("[ES] Alias %s -> %s (versi %d)", ProductIndex, target, ProductIndexVersion)
+	return nil
+}
+
+// productIndexTargets: index yang sekarang ditunjuk alias ProductIndex. legacy = true kalau
+// "products" masih index biasa (versi 1, sebelum ada alias).
+func productIndexTargets(ctx context.Context, es *elasticsearch.Client) (indices []string, legacy bool, err error) {
+	res, err := es.Indices.GetAlias(es.Indices.GetAlias.WithName(ProductIndex), es.Indices.GetAlias.WithContext(ctx))
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 404 {
+		if res.IsError() {
+			return nil, false, fmt.Errorf("get alias %s: %s", ProductIndex, res.String())
+		}
+		var aliases map[string]json.RawMessage
+		if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+			return nil, false, err
+		}
+		for index := range aliases {
+			indices = append(indices, index)
+		}
+		return indices, false, nil
+	}
+
+	res, err = es.Indices.Exists([]string{ProductIndex}, es.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return nil, false, err
+	}
+	res.Body.Close()
+	if res.StatusCode == 200 {
+		return []string{ProductIndex}, true, nil
+	}
+	return nil, false, nil
+}
+
+func checkResponse(res *esapi.Response, err error, what string) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("gagal menyiapkan %s: %s", what, res.String())
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductIndexTemplate(t *testing.T) {
+	body, err := json.Marshal(productIndexTemplate())
+	assert.NoError(t, err)
+
+	var tmpl struct {
+		IndexPatterns []string `json:"index_patterns"`
+		Version       int      `json:"version"`
+		Template      struct {
+			Mappings struct {
+				Dynamic    bool `json:"dynamic"`
+				Properties map[string]struct {
+					Type           string `json:"type"`
+					Analyzer       string `json:"analyzer"`
+					SearchAnalyzer string `json:"search_analyzer"`
+				} `json:"properties"`
+			} `json:"mappings"`
+		} `json:"template"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &tmpl))
+	assert.Equal(t, []string{"products-v*"}, tmpl.IndexPatterns)
+	assert.Equal(t, ProductIndexVersion, tmpl.Version)
+	assert.False(t, tmpl.Template.Mappings.Dynamic)
+
+	props := tmpl.Template.Mappings.Properties
+	assert.Equal(t, "long", props["price_minor"].Type)
+	assert.Equal(t, "completion", props["suggest"].Type)
+	assert.Equal(t, "product_name", props["name"].Analyzer)
+	assert.Equal(t, "product_name_search", props["name"].SearchAnalyzer)
+
+	assert.Equal(t, "products-v2", ProductIndexName(2))
+}
+
+func TestEnsureProductIndexMigratesLegacyIndex(t *testing.T) {
+	var reindex, aliases string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esInfo(w, r) {
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/_alias/"+ProductIndex:
+			w.WriteHeader(http.StatusNotFound) // belum ada alias
+		case r.Method == http.MethodHead && r.URL.Path == "/"+ProductIndex:
+			w.WriteHeader(http.StatusOK) // index lama bernama "products"
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/_reindex":
+			reindex = string(raw)
+			io.WriteString(w, `{}`)
+		case r.URL.Path == "/_aliases":
+			aliases = string(raw)
+			io.WriteString(w, `{"acknowledged":true}`)
+		default:
+			io.WriteString(w, `{"acknowledged":true}`)
+		}
+	}))
+	defer srv.Close()
+
+	es, _ := NewClient(srv.URL)
+	assert.NoError(t, EnsureProductIndex(context.Background(), es))
+
+	// Reindex membangun field suggest untuk dokumen lama
+	assert.Contains(t, reindex, `"dest":{"index":"products-v2"}`)
+	assert.Contains(t, reindex, `ctx._source.suggest`)
+	assert.Contains(t, aliases, `"remove_index":{"index":"products"}`)
+}
@@ -0,0 +1,158 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+)
+
+// Suggestion: satu hasil autocomplete dari field completion "suggest"
+type Suggestion struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	SKU        string  `json:"sku"`
+	PriceMinor int64   `json:"price_minor"`
+	Currency   string  `json:"currency"`
+	Score      float64 `json:"-"`
+}
+
+// SuggestInputs: input completion untuk satu produk. Completion hanya cocok dari awal input,
+// jadi tiap kata di nama juga jadi input ("Kopi Susu Gula" -> "Susu Gula", "Gula") supaya
+// ketik "susu" tetap ketemu. SKU ikut supaya kasir bisa ketik kode.
+func SuggestInputs(name string, sku *string) []string {
+	words := strings.Fields(name)
+	inputs := make([]string, 0, len(words)+1)
+	for i := range words {
+		inputs = append(inputs, strings.Join(words[i:], " "))
+	}
+	if sku != nil && *sku != "" {
+		inputs = append(inputs, *sku)
+	}
+	return inputs
+}
+
+// SuggestWeight: bobot completion dari jumlah terjual, produk laris muncul lebih dulu.
+// Minimal 1 dan tidak boleh melebihi int32 (batas ES).
+func SuggestWeight(sold int) int {
+	if sold < 0 {
+		sold = 0
+	}
+	if sold >= math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return sold + 1
+}
+
+// suggestScript: versi painless dari SuggestInputs + SuggestWeight, untuk membangun field suggest
+// dari dokumen yang sudah ada (backfill & reindex). Bobot dari sales_count, atau 1.
+const suggestScript = `
+	def words = [];
+	if (ctx._source.name != null) {
+		for (String w : ctx._source.name.splitOnToken(' ')) {
+			if (!w.isEmpty()) { words.add(w); }
+		}
+	}
+	def inputs = [];
+	for (int i = 0; i < words.size(); i++) {
+		inputs.add(String.join(' ', words.subList(i, words.size())));
+	}
+	if (ctx._source.sku != null && ctx._source.sku != '') { inputs.add(ctx._source.sku); }
+	long weight = 1;
+	if (ctx._source.sales_count != null && ctx._source.sales_count > 0) { weight = ctx._source.sales_count + 1L; }
+	if (weight > 2147483647L) { weight = 2147483647L; }
+	ctx._source.suggest = ['input': inputs, 'weight': (int) weight];
+`
+
+// BackfillSuggest mengisi field suggest untuk dokumen produk yang belum punya (diindex sebelum
+// ada autocomplete). Tanpa ini completion tidak pernah menemukan produk lama sampai produknya
+// diubah. Aman dijalankan berulang: dokumen yang sudah punya suggest dilewati.
+func BackfillSuggest(ctx context.Context, es *elasticsearch.Client) (int, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":  map[string]interface{}{"bool": map[string]interface{}{"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "suggest"}}}},
+		"script": map[string]interface{}{"source": suggestScript, "lang": "painless"},
+	})
+	res, err := es.UpdateByQuery([]string{ProductIndex},
+		es.UpdateByQuery.WithContext(ctx),
+		es.UpdateByQuery.WithBody(bytes.NewReader(body)),
+		es.UpdateByQuery.WithConflicts("proceed"), // dokumen yang sedang ditulis worker sudah dapat suggest
+		es.UpdateByQuery.WithWaitForCompletion(true),
+		es.UpdateByQuery.WithRefresh(true),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("backfill suggest %s: %s", ProductIndex, res.String())
+	}
+	var out struct {
+		Updated int `json:"updated"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return 0, err
+	}
+	return out.Updated, nil
+}
+
+// Suggest mencari saran nama produk lewat completion suggester dengan fuzzy (toleran typo)
+func Suggest(ctx context.Context, es *elasticsearch.Client, prefix string, size int) ([]Suggestion, error) {
+	query := map[string]interface{}{
+		"_source": []string{"id", "name", "sku", "price_minor", "currency"},
+		"suggest": map[string]interface{}{
+			"product": map[string]interface{}{
+				"prefix": prefix,
+				"completion": map[string]interface{}{
+					"field":           "suggest",
+					"size":            size,
+					"skip_duplicates": true,
+					"fuzzy":           map[string]interface{}{"fuzziness": "AUTO"},
+				},
+			},
+		},
+	}
+	body, _ := json.Marshal(query)
+
+	res, err := es.Search(
+		es.Search.WithContext(ctx),
+		es.Search.WithIndex(ProductIndex),
+		es.Search.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("suggest %s: %s", ProductIndex, res.Status())
+	}
+	return decodeSuggestResponse(res.Body)
+}
+
+func decodeSuggestResponse(r io.Reader) ([]Suggestion, error) {
+	var body struct {
+		Suggest map[string][]struct {
+			Options []struct {
+				Score  float64    `json:"_score"`
+				Source Suggestion `json:"_source"`
+			} `json:"options"`
+		} `json:"suggest"`
+	}
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	suggestions := []Suggestion{}
+	for _, entry := range body.Suggest["product"] {
+		for _, opt := range entry.Options {
+			s := opt.Source
+			s.Score = opt.Score
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestInputs(t *testing.T) {
+	sku := "KP-001"
+	assert.Equal(t, []string{"Kopi Susu Gula", "Susu Gula", "Gula", "KP-001"}, SuggestInputs("Kopi  Susu Gula", &sku))
+	assert.Equal(t, []string{"Teh"}, SuggestInputs("Teh", nil))
+}
+
+func TestSuggestWeight(t *testing.T) {
+	assert.Equal(t, 1, SuggestWeight(0))
+	assert.Equal(t, 1, SuggestWeight(-3))
+	assert.Equal(t, 43, SuggestWeight(42))
+	assert.Equal(t, math.MaxInt32, SuggestWeight(math.MaxInt32))
+}
+
+func TestDecodeSuggestResponse(t *testing.T) {
+	body := `{"took":2,"suggest":{"product":[{"text":"kopu","offset":0,"length":4,"options":[
+		{"text":"Kopi Susu","_id":"7","_score":12.0,"_source":{"id":7,"name":"Kopi Susu","sku":"KP-001","price_minor":1500000,"currency":"IDR"}},
+		{"text":"Kopi Hitam","_id":"3","_score":4.0,"_source":{"id":3,"name":"Kopi Hitam","price_minor":1200000,"currency":"IDR"}}
+	]}]}}`
+
+	got, err := decodeSuggestResponse(strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, Suggestion{ID: 7, Name: "Kopi Susu", SKU: "KP-001", PriceMinor: 1500000, Currency: "IDR", Score: 12}, got[0])
+	assert.Equal(t, "", got[1].SKU)
+}
+
+func TestBackfillSuggestOnlyMissingDocuments(t *testing.T) {
+	var path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esInfo(w, r) {
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		path, body = r.URL.Path, string(raw)
+		io.WriteString(w, `{"updated": 12, "failures": []}`)
+	}))
+	defer srv.Close()
+
+	es, _ := NewClient(srv.URL)
+	n, err := BackfillSuggest(context.Background(), es)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+	assert.Equal(t, "/"+ProductIndex+"/_update_by_query", path)
+	assert.Contains(t, body, `"must_not":{"exists":{"field":"suggest"}}`)
+}
@@ -1,13 +1,16 @@
 package stream
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 
 	"github.com/IBM/sarama"
 )
 
 type KafkaProducer struct {
+	client   sarama.Client
 	producer sarama.SyncProducer
 }
 
@@ -17,12 +20,27 @@ func NewKafkaProducer(brokers []string) *KafkaProducer {
 	config.Producer.RequiredAcks = sarama.WaitForAll // tunggu sampai kafka benar benar simpan data (durability)
 	config.Producer.Retry.Max = 5                    // retry jika network kumat
 
-	producer, err := sarama.NewSyncProducer(brokers, config)
+	// client dipisah supaya bisa dipakai untuk health check (cek metadata broker)
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		log.Fatalf("Failed to connect Kafka client: %v", err)
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
 	if err != nil {
 		log.Fatalf("Failed to start Kafka producer: %v", err)
 	}
 
-	return &KafkaProducer{producer: producer}
+	return &KafkaProducer{client: client, producer: producer}
+}
+
+// Ping memastikan minimal satu broker bisa dihubungi
+func (k *KafkaProducer) Ping(ctx context.Context) error {
+	if k.client.Closed() {
+		return errors.New("kafka client closed")
+	}
+	// RefreshMetadata tanpa topic = minta metadata cluster ke broker
+	return k.client.RefreshMetadata()
 }
 
 // mengirim event ke topic tertentu
@@ -48,6 +66,40 @@ func (k *KafkaProducer) SendMessage(topic string, key string, message interface{
 	return nil
 }
 
+// Message adalah satu event untuk SendMessages
+type Message struct {
+	Key   string
+	Value interface{}
+}
+
+// SendMessages mengirim banyak event ke satu topic dalam satu batch (dipakai import massal)
+func (k *KafkaProducer) SendMessages(topic string, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	batch := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, m := range messages {
+		val, err := json.Marshal(m.Value)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, &sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(m.Key),
+			Value: sarama.ByteEncoder(val),
+		})
+	}
+
+	if err := k.producer.SendMessages(batch); err != nil {
+		return err
+	}
+
+	log.Printf("[KAFKA] %d messages sent to topic %s", len(batch), topic)
+	return nil
+}
+
 func (k *KafkaProducer) Close() {
 	k.producer.Close()
+	k.client.Close()
 }
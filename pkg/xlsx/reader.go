@@ -0,0 +1,244 @@
+// Package xlsx membaca nilai sel di sheet pertama file .xlsx (Office Open XML) baris per baris.
+// Cukup untuk import data: format, rumus & tanggal tidak diinterpretasi, sel dikembalikan apa adanya.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFile = errors.New("file bukan xlsx yang valid")
+
+// Reader membaca baris sheet pertama secara streaming. Shared strings dimuat penuh di awal.
+type Reader struct {
+	sheet   io.ReadCloser
+	dec     *xml.Decoder
+	strings []string
+
+	next    int      // nomor baris (1-based) yang akan dikembalikan Read berikutnya
+	pending []string // baris yang sudah dibaca dari XML tapi belum dikembalikan (ada baris kosong sebelumnya)
+	pendRow int
+	done    bool
+}
+
+// Open membuka file xlsx dari r (zip butuh akses acak, jadi file harus sudah ada di disk / memori)
+func Open(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrInvalidFile
+	}
+
+	x := &Reader{next: 1}
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if x.strings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	if x.sheet, err = sheetFile.Open(); err != nil {
+		return nil, err
+	}
+	x.dec = xml.NewDecoder(x.sheet)
+	return x, nil
+}
+
+// Read mengembalikan baris berikutnya. Baris kosong di tengah sheet dikembalikan sebagai slice kosong
+// supaya nomor baris tetap sama dengan di Excel. io.EOF setelah baris terakhir.
+func (x *Reader) Read() ([]string, error) {
+	if x.pending == nil && !x.done {
+		row, cells, err := x.readRow()
+		if err == io.EOF {
+			x.done = true
+		} else if err != nil {
+			return nil, err
+		} else {
+			x.pendRow, x.pending = row, cells
+		}
+	}
+	if x.pending == nil {
+		return nil, io.EOF
+	}
+
+	if x.next < x.pendRow {
+		x.next++
+		return []string{}, nil
+	}
+	cells := x.pending
+	x.pending = nil
+	x.next++
+	return cells, nil
+}
+
+func (x *Reader) Close() error {
+	return x.sheet.Close()
+}
+
+// readRow membaca elemen <row> berikutnya dari sheet XML
+func (x *Reader) readRow() (int, []string, error) {
+	for {
+		tok, err := x.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return 0, nil, io.EOF
+			}
+			return 0, nil, ErrInvalidFile
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline struct {
+					Text string `xml:"t"`
+					Runs []struct {
+						Text string `xml:"t"`
+					} `xml:"r"`
+				} `xml:"is"`
+			} `xml:"c"`
+		}
+		if err := x.dec.DecodeElement(&row, &start); err != nil {
+			return 0, nil, ErrInvalidFile
+		}
+		if row.R == 0 {
+			row.R = x.next // atribut r opsional, berarti baris berurutan
+		}
+
+		cells := []string{}
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if col = columnIndex(c.Ref); col < 0 {
+					return 0, nil, ErrInvalidFile
+				}
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(x.strings) {
+					return 0, nil, fmt.Errorf("%w: shared string %q", ErrInvalidFile, c.Value)
+				}
+				cells[col] = x.strings[idx]
+			case "inlineStr":
+				text := c.Inline.Text
+				for _, r := range c.Inline.Runs {
+					text += r.Text
+				}
+				cells[col] = text
+			default: // n, str (hasil rumus), b, e
+				cells[col] = c.Value
+			}
+		}
+		return row.R, cells, nil
+	}
+}
+
+// columnIndex: "B12" -> 1 (0-based)
+func columnIndex(ref string) int {
+	n := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		n = n*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return -1
+	}
+	return n - 1
+}
+
+// firstSheetPath mencari file XML sheet pertama lewat workbook.xml & relasinya
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var wb struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeFile(files["xl/workbook.xml"], &wb); err != nil || len(wb.Sheets) == 0 {
+		return "", ErrInvalidFile
+	}
+	if err := decodeFile(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return "", ErrInvalidFile
+	}
+
+	for _, rel := range rels.Rels {
+		if rel.ID != wb.Sheets[0].ID {
+			continue
+		}
+		// Target relatif terhadap folder xl/, kadang absolut ("/xl/worksheets/sheet1.xml")
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", ErrInvalidFile
+}
+
+// readSharedStrings memuat tabel string; teks rich text (beberapa <r>) digabung, teks phonetic diabaikan
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodeFile(f, &sst); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		text := si.Text
+		for _, r := range si.Runs {
+			text += r.Text
+		}
+		out[i] = text
+	}
+	return out, nil
+}
+
+func decodeFile(f *zip.File, v interface{}) error {
+	if f == nil {
+		return ErrInvalidFile
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,64 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildXLSX membuat xlsx minimal: workbook dengan satu sheet + shared strings
+func buildXLSX(t *testing.T, sheet string) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+			xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Produk" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>sku</t></si><si><t>name</t></si><si><r><t>Beras </t></r><r><t>5kg</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": sheet,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	require.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReader(t *testing.T) {
+	file := buildXLSX(t, `<worksheet><sheetData>
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+		<row r="3"><c r="A3" t="inlineStr"><is><t>BRS-5</t></is></c><c r="B3" t="s"><v>2</v></c><c r="D3"><v>65000</v></c></row>
+	</sheetData></worksheet>`)
+
+	r, err := Open(file, file.Size())
+	require.NoError(t, err)
+	defer r.Close()
+
+	rows := [][]string{}
+	for {
+		cells, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, cells)
+	}
+	assert.Equal(t, [][]string{
+		{"sku", "name"},
+		{}, // baris 2 kosong tetap dikembalikan
+		{"BRS-5", "Beras 5kg", "", "65000"},
+	}, rows)
+}
+
+func TestOpenInvalid(t *testing.T) {
+	_, err := Open(bytes.NewReader([]byte("sku,name")), 8)
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -0,0 +1,155 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number: angka desimal yang ditulis sebagai sel number (contoh nominal uang "15000.50"), bukan teks
+type Number string
+
+// Writer menulis satu sheet xlsx secara streaming (memori tetap kecil berapa pun jumlah barisnya).
+// String ditulis inline, jadi tidak perlu tabel shared strings.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+)
+
+// NewWriter menulis bagian statis workbook lalu membuka sheet untuk ditulis baris per baris
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheetName))
+
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, name.String())},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow menulis satu baris. Tipe sel: string (teks), int / int64 / float64 / Number (angka),
+// bool, time.Time (teks RFC 3339), nil (sel kosong).
+func (x *Writer) WriteRow(values []any) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			x.writeString(ref, v)
+		case Number:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case int:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case float64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(x.sheet, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case time.Time:
+			x.writeString(ref, v.Format(time.RFC3339))
+		default:
+			x.writeString(ref, fmt.Sprint(v))
+		}
+	}
+	_, x.err = x.sheet.WriteString("</row>")
+	return x.err
+}
+
+func (x *Writer) writeString(ref, s string) {
+	fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+	xml.EscapeText(x.sheet, []byte(s))
+	x.sheet.WriteString("</t></is></c>")
+}
+
+// Flush mengirim baris yang masih di buffer ke writer tujuan
+func (x *Writer) Flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.err = x.sheet.Flush(); x.err != nil {
+		return x.err
+	}
+	x.err = x.zw.Flush()
+	return x.err
+}
+
+// Close menutup sheet & zip. Wajib dipanggil, tanpa ini file tidak bisa dibuka.
+func (x *Writer) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	x.sheet.WriteString("</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnName: 0 -> "A", 26 -> "AA"
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,34 @@
+package xlsx
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Produk & Stok")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow([]any{"sku", "name", "price", "stock"}))
+	require.NoError(t, w.WriteRow([]any{"BRS-5", "Beras <5kg>", Number("65000.50"), 10, nil, true}))
+	require.NoError(t, w.Close())
+
+	r, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	defer r.Close()
+
+	header, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sku", "name", "price", "stock"}, header)
+	row, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BRS-5", "Beras <5kg>", "65000.50", "10", "", "1"}, row)
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	assert.Equal(t, "AA", columnName(26))
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"phase3-api-architecture/models"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("kategori tidak ditemukan")
+	ErrCategoryExists   = errors.New("kategori dengan nama yang sama sudah ada di parent tersebut")
+)
+
+type CategoryRepository struct {
+	DB *sql.DB
+}
+
+// categoryTreeCTE menyusun path lengkap setiap kategori ("Minuman > Kopi").
+// Urutan path juga dipakai supaya sub-kategori tampil tepat di bawah parent-nya.
+const categoryTreeCTE = `
+	WITH RECURSIVE tree AS (
+		SELECT id, name, parent_id, tax_rate_id, created_at, name::TEXT AS path
+		FROM categories WHERE parent_id IS NULL
+		UNION ALL
+		SELECT c.id, c.name, c.parent_id, c.tax_rate_id, c.created_at, t.path || ' > ' || c.name
+		FROM categories c JOIN tree t ON c.parent_id = t.id
+	)`
+
+// categorySubtreeQuery: id kategori $N beserta semua turunannya, dipakai filter produk.
+// Placeholder %d diisi nomor argumen oleh pemanggil.
+const categorySubtreeQuery = `
+	WITH RECURSIVE sub AS (
+		SELECT id FROM categories WHERE id = $%d
+		UNION ALL
+		SELECT c.id FROM categories c JOIN sub s ON c.parent_id = s.id
+	) SELECT id FROM sub`
+
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	query := categoryTreeCTE + " SELECT id, name, parent_id, tax_rate_id, path, created_at FROM tree ORDER BY path"
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.ParentID, &c.TaxRateID, &c.Path, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// Create menambah kategori. Kategori tidak bisa dipindah parent-nya, jadi tidak mungkin ada siklus.
+func (r *CategoryRepository) Create(ctx context.Context, c *models.Category) error {
+	query := "INSERT INTO categories (name, parent_id, tax_rate_id) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := r.DB.QueryRowContext(ctx, query, c.Name, c.ParentID, c.TaxRateID).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err, "fk_category_parent") {
+			return ErrCategoryNotFound
+		}
+		if isForeignKeyViolation(err, "fk_category_tax_rate") {
+			return ErrTaxRateNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrCategoryExists
+		}
+		return err
+	}
+
+	c.Path = c.Name
+	if c.ParentID != nil {
+		query := categoryTreeCTE + " SELECT path FROM tree WHERE id = $1"
+		if err := r.DB.QueryRowContext(ctx, query, c.ID).Scan(&c.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
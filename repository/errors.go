@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Kode error Postgres yang perlu diterjemahkan jadi error domain
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+var (
+	ErrProductNotFound  = errors.New("produk tidak ditemukan")
+	ErrVersionMismatch  = errors.New("produk sudah diubah oleh orang lain, muat ulang data terbaru")
+	ErrDuplicateSKU     = errors.New("SKU sudah dipakai produk lain")
+	ErrDuplicateBarcode = errors.New("barcode sudah dipakai produk lain")
+)
+
+// isForeignKeyViolation mengecek error FK; jika constraints diisi, nama constraint harus salah satunya
+func isForeignKeyViolation(err error, constraints ...string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pgForeignKeyViolation {
+		return false
+	}
+	if len(constraints) == 0 {
+		return true
+	}
+	for _, c := range constraints {
+		if pqErr.Constraint == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isUniqueViolation sama seperti isForeignKeyViolation tapi untuk unique constraint
+func isUniqueViolation(err error, constraints ...string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pgUniqueViolation {
+		return false
+	}
+	if len(constraints) == 0 {
+		return true
+	}
+	for _, c := range constraints {
+		if pqErr.Constraint == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"strings"
+)
+
+var ErrInvalidExchangeRate = errors.New("kurs tidak valid")
+
+// ExchangeRateRepository mengelola tabel kurs manual (diisi admin, tidak ada sinkron otomatis)
+type ExchangeRateRepository struct {
+	DB *sql.DB
+}
+
+func (r *ExchangeRateRepository) GetAll(ctx context.Context) ([]models.ExchangeRate, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT base, quote, rate::TEXT, updated_by, updated_at FROM exchange_rates ORDER BY base, quote")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var e models.ExchangeRate
+		if err := rows.Scan(&e.Base, &e.Quote, &e.Rate, &e.UpdatedBy, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, e)
+	}
+	return rates, rows.Err()
+}
+
+// Upsert menyimpan kurs base -> quote. Rate berupa string desimal > 0, dicek dengan big.Rat supaya tidak lewat float.
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, userID int, e *models.ExchangeRate) error {
+	e.Base, e.Quote = strings.ToUpper(e.Base), strings.ToUpper(e.Quote)
+	if !money.Supported(e.Base) || !money.Supported(e.Quote) {
+		return fmt.Errorf("%w: %s/%s", money.ErrUnknownCurrency, e.Base, e.Quote)
+	}
+	if e.Base == e.Quote {
+		return fmt.Errorf("%w: base dan quote tidak boleh sama", ErrInvalidExchangeRate)
+	}
+	// Kolom NUMERIC(24, 12): maksimal 12 digit bulat & 12 digit desimal
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(e.Rate))
+	if !ok || rate.Sign() <= 0 {
+		return fmt.Errorf("%w: rate harus angka desimal > 0", ErrInvalidExchangeRate)
+	}
+	stored := rate.FloatString(12)
+	if len(strings.Split(stored, ".")[0]) > 12 || strings.Trim(stored, "0.") == "" {
+		return fmt.Errorf("%w: rate di luar rentang yang didukung", ErrInvalidExchangeRate)
+	}
+
+	query := `
+		INSERT INTO exchange_rates (base, quote, rate, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (base, quote) DO UPDATE SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING rate::TEXT, updated_by, updated_at`
+	return r.DB.QueryRowContext(ctx, query, e.Base, e.Quote, stored, userID).Scan(&e.Rate, &e.UpdatedBy, &e.UpdatedAt)
+}
+
+// convertPrice mengonversi harga ke semua mata uang yang punya kurs dari mata uang harga tersebut.
+// Konversi yang overflow dilewati saja (hanya untuk tampilan, checkout tetap memakai mata uang produk).
+func convertPrice(ctx context.Context, q queryer, price money.Money) ([]money.Money, error) {
+	rows, err := q.QueryContext(ctx, "SELECT quote, rate::TEXT FROM exchange_rates WHERE base = $1 ORDER BY quote", price.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var converted []money.Money
+	for rows.Next() {
+		var quote, rateText string
+		if err := rows.Scan(&quote, &rateText); err != nil {
+			return nil, err
+		}
+		rate, ok := new(big.Rat).SetString(rateText)
+		if !ok {
+			continue
+		}
+		if m, err := price.Convert(rate, quote); err == nil {
+			converted = append(converted, m)
+		}
+	}
+	return converted, rows.Err()
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/export"
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+var (
+	ErrExportNotFound = errors.New("export tidak ditemukan")
+	ErrExportNotReady = errors.New("file export belum siap atau sudah kedaluwarsa")
+	ErrUnknownExport  = errors.New("export harus products atau transactions")
+)
+
+const (
+	exportFetchSize  = 1000
+	exportStaleAfter = 5 * time.Minute
+	exportRetention  = 24 * time.Hour
+)
+
+// ExportRepository membaca data export lewat server-side cursor Postgres (memori tetap kecil),
+// dan menjalankan export besar di background dengan hasil file di Dir.
+type ExportRepository struct {
+	DB  *sql.DB
+	Dir string // Folder file hasil export, harus bisa diakses semua replica API
+
+	wake chan struct{}
+}
+
+func NewExportRepository(db *sql.DB, dir string) *ExportRepository {
+	return &ExportRepository{DB: db, Dir: dir, wake: make(chan struct{}, 1)}
+}
+
+// exportSource: query, nama kolom & cara scan satu baris untuk satu jenis export
+type exportSource struct {
+	query   string
+	args    []interface{}
+	columns []string
+	scan    func(rows *sql.Rows) ([]any, error)
+}
+
+// nullable: pointer nil jadi sel kosong
+func nullable[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func exportQuery(kind string, f models.ExportFilter) (exportSource, error) {
+	switch kind {
+	case models.ExportProducts:
+		where := &sqlWhere{}
+		if !f.IncludeDeleted {
+			where.add("p.deleted_at IS NULL")
+		}
+		if f.Search != "" {
+			where.add("p.name ILIKE ?", "%"+f.Search+"%")
+		}
+		if f.CategoryID != 0 {
+			where.add("p.category_id IN ("+fmt.Sprintf(categorySubtreeQuery, len(where.args)+1)+")", f.CategoryID)
+		}
+		return exportSource{
+			query: `
+				SELECT p.id, p.sku, p.barcode, p.name, p.category_id, p.base_unit, p.currency, p.price, p.stock,
+				       p.reorder_point, p.reorder_quantity, p.supplier_id, p.tax_rate_id, p.version, p.deleted_at
+				FROM products p` + where.String() + " ORDER BY p.id",
+			args: where.args,
+			columns: []string{"id", "sku", "barcode", "name", "category_id", "base_unit", "currency", "price", "stock",
+				"reorder_point", "reorder_quantity", "supplier_id", "tax_rate_id", "version", "deleted_at"},
+			scan: func(rows *sql.Rows) ([]any, error) {
+				var p models.Product
+				err := rows.Scan(&p.ID, &p.SKU, &p.Barcode, &p.Name, &p.CategoryID, &p.BaseUnit, &p.Price.Currency, &p.Price.Amount, &p.Stock,
+					&p.ReorderPoint, &p.ReorderQuantity, &p.SupplierID, &p.TaxRateID, &p.Version, &p.DeletedAt)
+				return []any{p.ID, nullable(p.SKU), nullable(p.Barcode), p.Name, nullable(p.CategoryID), p.BaseUnit, p.Price.Currency,
+					export.Decimal(p.Price.Decimal()), p.Stock, p.ReorderPoint, p.ReorderQuantity, nullable(p.SupplierID),
+					nullable(p.TaxRateID), p.Version, nullable(p.DeletedAt)}, err
+			},
+		}, nil
+
+	case models.ExportTransactions:
+		where := transactionFilterWhere(f.Transactions)
+		return exportSource{
+			query: `
+				SELECT t.id, t.created_at, t.user_id, usr.email, t.product_id, p.sku, p.name, t.quantity,
+				       COALESCE(t.unit_quantity, t.quantity), COALESCE(u.name, p.base_unit), COALESCE(t.location_id, 0), t.currency,
+				       COALESCE(t.subtotal, t.total_price), t.discount_amount, t.tax_rate_bp, t.tax_inclusive, t.tax_amount, t.total_price
+				FROM transactions t
+				JOIN products p ON p.id = t.product_id
+				JOIN users usr ON usr.id = t.user_id
+				LEFT JOIN product_units u ON u.id = t.unit_id` + where.String() + " ORDER BY t.created_at, t.id",
+			args: where.args,
+			columns: []string{"id", "created_at", "user_id", "user_email", "product_id", "sku", "product_name", "quantity",
+				"unit_quantity", "unit_name", "location_id", "currency", "subtotal", "discount_amount", "tax_rate_bp", "tax_inclusive",
+				"tax_amount", "total_price"},
+			scan: func(rows *sql.Rows) ([]any, error) {
+				var t models.Transaction
+				var email, currency string
+				var sku *string
+				err := rows.Scan(&t.ID, &t.CreatedAt, &t.UserID, &email, &t.ProductID, &sku, &t.ProductName, &t.Quantity,
+					&t.UnitQuantity, &t.UnitName, &t.LocationID, &currency,
+					&t.Subtotal.Amount, &t.DiscountAmount.Amount, &t.TaxRateBP, &t.TaxInclusive, &t.TaxAmount.Amount, &t.TotalPrice.Amount)
+				decimal := func(amount int64) export.Decimal {
+					return export.Decimal(money.New(amount, currency).Decimal())
+				}
+				return []any{t.ID, t.CreatedAt, t.UserID, email, t.ProductID, nullable(sku), t.ProductName, t.Quantity,
+					t.UnitQuantity, t.UnitName, t.LocationID, currency,
+					decimal(t.Subtotal.Amount), decimal(t.DiscountAmount.Amount), t.TaxRateBP, t.TaxInclusive,
+					decimal(t.TaxAmount.Amount), decimal(t.TotalPrice.Amount)}, err
+			},
+		}, nil
+	}
+	return exportSource{}, ErrUnknownExport
+}
+
+// Stream menulis seluruh hasil export ke w (termasuk Close). Data dibaca per exportFetchSize baris
+// lewat cursor di transaksi read-only, jadi snapshot konsisten dan memori tidak bergantung jumlah baris.
+// onBatch (opsional) dipanggil setelah setiap batch dengan jumlah baris yang sudah ditulis.
+func (r *ExportRepository) Stream(ctx context.Context, kind string, f models.ExportFilter, w export.Writer, onBatch func(rows int)) (int, error) {
+	src, err := exportQuery(kind, f)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+src.query, src.args...); err != nil {
+		return 0, err
+	}
+	if err := w.WriteHeader(src.columns); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH FORWARD %d FROM export_cursor", exportFetchSize))
+		if err != nil {
+			return total, err
+		}
+		fetched := 0
+		for rows.Next() {
+			values, err := src.scan(rows)
+			if err == nil {
+				err = w.WriteRow(values)
+			}
+			if err != nil {
+				rows.Close()
+				return total, err
+			}
+			fetched++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+
+		total += fetched
+		if err := w.Flush(); err != nil {
+			return total, err
+		}
+		if onBatch != nil {
+			onBatch(total)
+		}
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+	return total, w.Close()
+}
+
+const exportJobColumns = `id, kind, format, filters, status, row_count, size_bytes, COALESCE(file_path, ''), COALESCE(error, ''),
+	created_by, created_at, started_at, finished_at, expires_at`
+
+func scanExportJob(row rowScanner, j *models.ExportJob) error {
+	var filters []byte
+	err := row.Scan(&j.ID, &j.Kind, &j.Format, &filters, &j.Status, &j.Rows, &j.SizeBytes, &j.FilePath, &j.Error,
+		&j.CreatedBy, &j.CreatedAt, &j.StartedAt, &j.FinishedAt, &j.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if j.Status == models.ExportCompleted {
+		j.DownloadURL = fmt.Sprintf("/admin/exports/%d/download", j.ID)
+	}
+	return json.Unmarshal(filters, &j.Filter)
+}
+
+// CreateJob mengantrekan export background lalu membangunkan RunExporter
+func (r *ExportRepository) CreateJob(ctx context.Context, userID int, kind, format string, f models.ExportFilter) (models.ExportJob, error) {
+	var j models.ExportJob
+	if _, err := exportQuery(kind, f); err != nil {
+		return j, err
+	}
+	filters, err := json.Marshal(f)
+	if err != nil {
+		return j, err
+	}
+
+	query := `
+		INSERT INTO export_jobs (kind, format, filters, created_by) VALUES ($1, $2, $3, $4)
+		RETURNING ` + exportJobColumns
+	if err := scanExportJob(r.DB.QueryRowContext(ctx, query, kind, format, filters, userID), &j); err != nil {
+		return j, err
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return j, nil
+}
+
+func (r *ExportRepository) GetJob(ctx context.Context, id int) (models.ExportJob, error) {
+	var j models.ExportJob
+	err := scanExportJob(r.DB.QueryRowContext(ctx, "SELECT "+exportJobColumns+" FROM export_jobs WHERE id = $1", id), &j)
+	if err == sql.ErrNoRows {
+		return j, ErrExportNotFound
+	}
+	return j, err
+}
+
+// OpenResult membuka file hasil export yang sudah selesai
+func (r *ExportRepository) OpenResult(ctx context.Context, id int) (models.ExportJob, *os.File, error) {
+	j, err := r.GetJob(ctx, id)
+	if err != nil {
+		return j, nil, err
+	}
+	if j.Status != models.ExportCompleted {
+		return j, nil, ErrExportNotReady
+	}
+	f, err := os.Open(j.FilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil, ErrExportNotReady
+	}
+	return j, f, err
+}
+
+// RunExporter menjalankan export yang antre dan menghapus file yang sudah kedaluwarsa sampai ctx dibatalkan
+func (r *ExportRepository) RunExporter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.purgeExpired(ctx); err != nil {
+				log.Printf("[EXPORT] Gagal menghapus file kedaluwarsa: %v", err)
+			}
+		case <-r.wake:
+		}
+
+		for {
+			j, err := r.claim(ctx)
+			if err != nil {
+				log.Printf("[EXPORT] Gagal mengambil antrean export: %v", err)
+				break
+			}
+			if j.ID == 0 {
+				break
+			}
+			if err := r.run(ctx, j); err != nil {
+				log.Printf("[EXPORT] Export %d gagal: %v", j.ID, err)
+				if ctx.Err() != nil {
+					return
+				}
+				r.DB.ExecContext(ctx, "UPDATE export_jobs SET status = 'failed', error = $2, finished_at = NOW() WHERE id = $1", j.ID, err.Error())
+			}
+		}
+	}
+}
+
+func (r *ExportRepository) claim(ctx context.Context) (models.ExportJob, error) {
+	var j models.ExportJob
+	query := `
+		UPDATE export_jobs SET status = 'processing', started_at = NOW(), heartbeat_at = NOW(), row_count = 0
+		WHERE id = (
+			SELECT id FROM export_jobs
+			WHERE status = 'pending' OR (status = 'processing' AND heartbeat_at < NOW() - $1 * INTERVAL '1 second')
+			ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING ` + exportJobColumns
+	err := scanExportJob(r.DB.QueryRowContext(ctx, query, exportStaleAfter.Seconds()), &j)
+	if err == sql.ErrNoRows {
+		return j, nil
+	}
+	return j, err
+}
+
+// run menulis export ke file sementara lalu rename, jadi file yang bisa diunduh selalu utuh
+func (r *ExportRepository) run(ctx context.Context, j models.ExportJob) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(r.Dir, fmt.Sprintf("export-%d.%s", j.ID, j.Format))
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path + ".tmp")
+	defer f.Close()
+
+	w, err := export.NewWriter(j.Format, f, j.Kind)
+	if err != nil {
+		return err
+	}
+	n, err := r.Stream(ctx, j.Kind, j.Filter, w, func(rows int) {
+		r.DB.ExecContext(ctx, "UPDATE export_jobs SET row_count = $2, heartbeat_at = NOW() WHERE id = $1", j.ID, rows)
+	})
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	info, err := os.Stat(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `
+		UPDATE export_jobs SET status = 'completed', row_count = $2, size_bytes = $3, file_path = $4,
+		       finished_at = NOW(), expires_at = NOW() + $5 * INTERVAL '1 second'
+		WHERE id = $1`, j.ID, n, info.Size(), path, exportRetention.Seconds())
+	if err == nil {
+		log.Printf("[EXPORT] Export %d selesai (%d baris)", j.ID, n)
+	}
+	return err
+}
+
+// purgeExpired menghapus file export yang sudah lewat masa simpan
+func (r *ExportRepository) purgeExpired(ctx context.Context) error {
+	rows, err := r.DB.QueryContext(ctx, `
+		UPDATE export_jobs SET status = 'expired'
+		WHERE status = 'completed' AND expires_at < NOW()
+		RETURNING file_path`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[EXPORT] Gagal menghapus %s: %v", path, err)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeQuery: jawaban untuk query yang teksnya memuat match. rows kosong = sql.ErrNoRows di QueryRow.
+type fakeQuery struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// openFakeTx membuka tx di atas driver database/sql minimal, untuk menguji fungsi repository yang
+// butuh *sql.Tx tanpa Postgres. Query yang tidak terdaftar dianggap error.
+func openFakeTx(t *testing.T, queries ...fakeQuery) *sql.Tx {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{queries: queries})
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+type fakeConnector struct{ queries []fakeQuery }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("pakai sql.OpenDB") }
+
+type fakeConn struct{ queries []fakeQuery }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare tidak didukung")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	for _, q := range c.queries {
+		if strings.Contains(query, q.match) {
+			return &fakeRows{columns: q.columns, rows: q.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("query tidak terduga: %s", query)
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"phase3-api-architecture/models"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrLocationNotFound     = errors.New("lokasi tidak ditemukan")
+	ErrTransferNotFound     = errors.New("transfer tidak ditemukan")
+	ErrTransferNotInTransit = errors.New("transfer sudah diterima sebelumnya")
+)
+
+type LocationRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client
+}
+
+func (r *LocationRepository) GetAll(ctx context.Context) ([]models.Location, error) {
+	query := "SELECT id, code, name, type, is_default, created_at FROM locations ORDER BY id"
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []models.Location{}
+	for rows.Next() {
+		var l models.Location
+		if err := rows.Scan(&l.ID, &l.Code, &l.Name, &l.Type, &l.IsDefault, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+func (r *LocationRepository) Create(ctx context.Context, l *models.Location) error {
+	// Lokasi default hanya dari migration, lokasi baru selalu non-default
+	query := "INSERT INTO locations (code, name, type) VALUES ($1, $2, $3) RETURNING id, created_at"
+	return r.DB.QueryRowContext(ctx, query, l.Code, l.Name, l.Type).Scan(&l.ID, &l.CreatedAt)
+}
+
+// GetStockLevels mengembalikan stok produk per lokasi, termasuk yang sedang dalam perjalanan
+func (r *LocationRepository) GetStockLevels(ctx context.Context, productID int) ([]models.StockLevel, error) {
+	query := `
+		SELECT l.id, l.code, l.name, COALESCE(sl.quantity, 0),
+		       COALESCE((
+		           SELECT SUM(ti.quantity) FROM stock_transfer_items ti
+		           JOIN stock_transfers t ON t.id = ti.transfer_id
+		           WHERE t.to_location_id = l.id AND t.status = 'in_transit' AND ti.product_id = $1
+		       ), 0) AS in_transit
+		FROM locations l
+		LEFT JOIN stock_levels sl ON sl.location_id = l.id AND sl.product_id = $1
+		ORDER BY l.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := []models.StockLevel{}
+	for rows.Next() {
+		var sl models.StockLevel
+		if err := rows.Scan(&sl.LocationID, &sl.LocationCode, &sl.LocationName, &sl.Quantity, &sl.InTransit); err != nil {
+			return nil, err
+		}
+		levels = append(levels, sl)
+	}
+	return levels, rows.Err()
+}
+
+// GetStockLevel mengembalikan stok satu produk di satu lokasi (0 jika belum pernah ada stok)
+func (r *LocationRepository) GetStockLevel(ctx context.Context, locationID, productID int) (int, error) {
+	var qty int
+	query := "SELECT COALESCE((SELECT quantity FROM stock_levels WHERE location_id = $1 AND product_id = $2), 0)"
+	err := r.DB.QueryRowContext(ctx, query, locationID, productID).Scan(&qty)
+	return qty, err
+}
+
+// CreateTransfer mengurangi stok lokasi asal dan mencatat barang sebagai in_transit.
+// Stok baru bertambah di lokasi tujuan setelah ReceiveTransfer.
+func (r *LocationRepository) CreateTransfer(ctx context.Context, userID int, req models.TransferRequest) (models.StockTransfer, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer tx.Rollback()
+
+	t := models.StockTransfer{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Status:         models.TransferInTransit,
+		CreatedBy:      userID,
+		Items:          req.Items,
+	}
+
+	queryInsert := `
+		INSERT INTO stock_transfers (from_location_id, to_location_id, status, created_by)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, queryInsert, t.FromLocationID, t.ToLocationID, t.Status, userID).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return models.StockTransfer{}, ErrLocationNotFound
+		}
+		return models.StockTransfer{}, err
+	}
+
+	// Urutkan per product_id supaya urutan lock konsisten (hindari deadlock antar transfer)
+	items := append([]models.StockTransferItem(nil), req.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	for _, item := range items {
+		// Barang yang sudah di-hold reservasi tidak boleh ikut dikirim
+		available, err := lockAvailableStock(ctx, tx, item.ProductID)
+		if err != nil {
+			return models.StockTransfer{}, err
+		}
+		if available < item.Quantity {
+			return models.StockTransfer{}, ErrInsufficientStock
+		}
+
+		// Lot ikut berpindah (FEFO), lot kedaluwarsa tidak ikut dikirim
+		consume := lotConsumption{refColumn: "transfer_id", refID: t.ID}
+		if err := consumeLots(ctx, tx, t.FromLocationID, item.ProductID, item.Quantity, consume); err != nil {
+			return models.StockTransfer{}, err
+		}
+		if err := adjustLocationStock(ctx, tx, t.FromLocationID, item.ProductID, -item.Quantity); err != nil {
+			return models.StockTransfer{}, err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO stock_transfer_items (transfer_id, product_id, quantity) VALUES ($1, $2, $3)",
+			t.ID, item.ProductID, item.Quantity)
+		if err != nil {
+			return models.StockTransfer{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	invalidateProductCache(ctx, r.Redis, ids...)
+	return t, nil
+}
+
+// ReceiveTransfer menambahkan barang in_transit ke stok lokasi tujuan
+func (r *LocationRepository) ReceiveTransfer(ctx context.Context, id int) (models.StockTransfer, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer tx.Rollback()
+
+	// Kunci dokumen transfer supaya tidak bisa diterima dua kali
+	t, err := getTransfer(ctx, tx, id, true)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	if t.Status != models.TransferInTransit {
+		return models.StockTransfer{}, ErrTransferNotInTransit
+	}
+
+	for _, item := range t.Items {
+		if err := adjustLocationStock(ctx, tx, t.ToLocationID, item.ProductID, item.Quantity); err != nil {
+			return models.StockTransfer{}, err
+		}
+	}
+	if err := receiveTransferLots(ctx, tx, t.ID, t.ToLocationID); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	query := "UPDATE stock_transfers SET status = $1, received_at = NOW() WHERE id = $2 RETURNING received_at"
+	if err := tx.QueryRowContext(ctx, query, models.TransferReceived, id).Scan(&t.ReceivedAt); err != nil {
+		return models.StockTransfer{}, err
+	}
+	t.Status = models.TransferReceived
+
+	if err := tx.Commit(); err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	ids := make([]int, 0, len(t.Items))
+	for _, item := range t.Items {
+		ids = append(ids, item.ProductID)
+	}
+	invalidateProductCache(ctx, r.Redis, ids...)
+	return t, nil
+}
+
+func (r *LocationRepository) GetTransfer(ctx context.Context, id int) (models.StockTransfer, error) {
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	defer tx.Rollback()
+
+	return getTransfer(ctx, tx, id, false)
+}
+
+func getTransfer(ctx context.Context, tx *sql.Tx, id int, forUpdate bool) (models.StockTransfer, error) {
+	var t models.StockTransfer
+	query := `
+		SELECT id, from_location_id, to_location_id, status, created_by, created_at, received_at
+		FROM stock_transfers WHERE id = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var receivedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, query, id).
+		Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Status, &t.CreatedBy, &t.CreatedAt, &receivedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return t, ErrTransferNotFound
+		}
+		return t, err
+	}
+	if receivedAt.Valid {
+		t.ReceivedAt = &receivedAt.Time
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, quantity FROM stock_transfer_items WHERE transfer_id = $1 ORDER BY product_id", id)
+	if err != nil {
+		return t, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return t, err
+		}
+		t.Items = append(t.Items, item)
+	}
+	return t, rows.Err()
+}
+
+// defaultLocationID mengembalikan lokasi yang dipakai jika client tidak memilih lokasi
+func defaultLocationID(ctx context.Context, tx *sql.Tx) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM locations WHERE is_default").Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrLocationNotFound
+	}
+	return id, err
+}
+
+// adjustLocationStock menambah/mengurangi stok di satu lokasi sekaligus total products.stock,
+// supaya Product.Stock tetap sama dengan jumlah stok semua lokasi.
+func adjustLocationStock(ctx context.Context, tx *sql.Tx, locationID, productID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+
+	if delta < 0 {
+		query := `
+			UPDATE stock_levels SET quantity = quantity + $1, updated_at = NOW()
+			WHERE location_id = $2 AND product_id = $3 AND quantity + $1 >= 0`
+		res, err := tx.ExecContext(ctx, query, delta, locationID, productID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrInsufficientStock
+		}
+	} else {
+		query := `
+			INSERT INTO stock_levels (location_id, product_id, quantity) VALUES ($1, $2, $3)
+			ON CONFLICT (location_id, product_id)
+			DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity, updated_at = NOW()`
+		if _, err := tx.ExecContext(ctx, query, locationID, productID, delta); err != nil {
+			if isForeignKeyViolation(err, "fk_stock_location") {
+				return ErrLocationNotFound
+			}
+			if isForeignKeyViolation(err, "fk_stock_product") {
+				return ErrProductNotFound
+			}
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock + $1 WHERE id = $2", delta, productID)
+	return err
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+)
+
+var (
+	ErrLotNotFound  = errors.New("lot tidak ditemukan di lokasi tersebut")
+	ErrExpiredStock = errors.New("sisa stok sudah kedaluwarsa, lakukan koreksi stok dulu")
+	ErrLotPositive  = errors.New("lot_id hanya bisa dipakai untuk koreksi negatif")
+)
+
+// LotRepository dipakai untuk laporan lot & job expiry di worker
+type LotRepository struct {
+	DB *sql.DB
+}
+
+// lotStatusExpr menghitung status expiry lot, $1 = batas hari near expiry
+const lotStatusExpr = `
+	CASE
+		WHEN l.expiry_date < CURRENT_DATE THEN 'expired'
+		WHEN l.expiry_date <= CURRENT_DATE + $1::INT THEN 'near_expiry'
+		ELSE 'ok'
+	END`
+
+const lotColumns = `l.id, l.product_id, p.name, l.location_id, COALESCE(l.lot_number, ''), l.expiry_date,
+	l.received_at, l.quantity_received, l.quantity_remaining, l.goods_receipt_id, ` + lotStatusExpr
+
+func scanLots(rows *sql.Rows) ([]models.StockLot, error) {
+	defer rows.Close()
+
+	lots := []models.StockLot{}
+	for rows.Next() {
+		var l models.StockLot
+		err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.LocationID, &l.LotNumber, &l.ExpiryDate,
+			&l.ReceivedAt, &l.QuantityReceived, &l.QuantityRemaining, &l.GoodsReceiptID, &l.Status)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	return lots, rows.Err()
+}
+
+// GetByProduct mengembalikan lot yang masih ada sisanya, dalam urutan FEFO
+func (r *LotRepository) GetByProduct(ctx context.Context, productID, nearExpiryDays int) ([]models.StockLot, error) {
+	query := `
+		SELECT ` + lotColumns + `
+		FROM stock_lots l JOIN products p ON p.id = l.product_id
+		WHERE l.product_id = $2 AND l.quantity_remaining > 0
+		ORDER BY l.location_id, l.expiry_date NULLS LAST, l.received_at, l.id`
+	rows, err := r.DB.QueryContext(ctx, query, nearExpiryDays, productID)
+	if err != nil {
+		return nil, err
+	}
+	return scanLots(rows)
+}
+
+// GetExpiring mengembalikan lot yang sudah atau akan kedaluwarsa dalam nearExpiryDays hari.
+// locationID 0 = semua lokasi.
+func (r *LotRepository) GetExpiring(ctx context.Context, nearExpiryDays, locationID int) ([]models.StockLot, error) {
+	query := `
+		SELECT ` + lotColumns + `
+		FROM stock_lots l JOIN products p ON p.id = l.product_id
+		WHERE l.quantity_remaining > 0 AND l.expiry_date <= CURRENT_DATE + $1::INT
+		  AND ($2 = 0 OR l.location_id = $2)
+		ORDER BY l.expiry_date, l.product_id, l.location_id`
+	rows, err := r.DB.QueryContext(ctx, query, nearExpiryDays, locationID)
+	if err != nil {
+		return nil, err
+	}
+	return scanLots(rows)
+}
+
+// MarkExpiryAlerts menandai lot yang status expiry-nya berubah sejak alert terakhir
+// (ok -> near_expiry -> expired) lalu memanggil send dengan lot-lot itu, masih di dalam transaksi.
+// Tanda baru di-commit hanya kalau send berhasil; kalau gagal semuanya di-rollback dan lot
+// di-alert lagi di scan berikutnya, jadi setiap status di-alert sekali (paling tidak sekali).
+func (r *LotRepository) MarkExpiryAlerts(ctx context.Context, nearExpiryDays int, send func([]models.StockLot) error) ([]models.StockLot, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH due AS (
+			SELECT l.id, l.product_id, ` + lotStatusExpr + ` AS status
+			FROM stock_lots l
+			WHERE l.quantity_remaining > 0 AND l.expiry_date <= CURRENT_DATE + $1::INT
+		)
+		UPDATE stock_lots l SET alerted_status = due.status
+		FROM due JOIN products p ON p.id = due.product_id
+		WHERE l.id = due.id AND l.alerted_status IS DISTINCT FROM due.status
+		RETURNING ` + lotColumns
+	rows, err := tx.QueryContext(ctx, query, nearExpiryDays)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := scanLots(rows)
+	if err != nil || len(lots) == 0 {
+		return lots, err
+	}
+
+	if err := send(lots); err != nil {
+		return nil, err
+	}
+	return lots, tx.Commit()
+}
+
+// addLot mencatat barang masuk sebagai lot baru dan mengembalikan ID-nya.
+// expiryDate format YYYY-MM-DD, kosong = tidak kedaluwarsa.
+func addLot(ctx context.Context, tx *sql.Tx, productID, locationID, receiptID, quantity int, lotNumber, expiryDate string) (int, error) {
+	var id int
+	query := `
+		INSERT INTO stock_lots (product_id, location_id, lot_number, expiry_date, quantity_received, quantity_remaining, goods_receipt_id)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::DATE, $5, $5, $6) RETURNING id`
+	err := tx.QueryRowContext(ctx, query, productID, locationID, lotNumber, expiryDate, quantity, receiptID).Scan(&id)
+	return id, err
+}
+
+// lotConsumption mengatur dari mana stok keluar diambil
+type lotConsumption struct {
+	refColumn    string // kolom referensi di stock_lot_allocations: transaction_id / transfer_id / adjustment_id
+	refID        int
+	allowExpired bool // true untuk koreksi stok (buang barang kedaluwarsa)
+	lotID        int  // opsional: hanya dari lot ini
+}
+
+type lotRow struct {
+	id        int
+	remaining int
+	expired   bool
+}
+
+// consumeLots mengurangi sisa lot secara FEFO untuk stok yang keluar dari satu lokasi.
+// Harus dipanggil SEBELUM adjustLocationStock karena memakai stok lokasi saat ini untuk
+// menghitung stok untracked (tanpa lot), yang dipakai setelah semua lot yang belum kedaluwarsa habis.
+func consumeLots(ctx context.Context, tx *sql.Tx, locationID, productID, quantity int, opt lotConsumption) error {
+	var level int
+	err := tx.QueryRowContext(ctx, "SELECT quantity FROM stock_levels WHERE location_id = $1 AND product_id = $2 FOR UPDATE",
+		locationID, productID).Scan(&level)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	query := `
+		SELECT id, quantity_remaining, COALESCE(expiry_date < CURRENT_DATE, FALSE)
+		FROM stock_lots
+		WHERE product_id = $1 AND location_id = $2 AND quantity_remaining > 0 AND ($3 = 0 OR id = $3)
+		ORDER BY expiry_date NULLS LAST, received_at, id
+		FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, productID, locationID, opt.lotID)
+	if err != nil {
+		return err
+	}
+	var lots []lotRow
+	tracked := 0
+	for rows.Next() {
+		var l lotRow
+		if err := rows.Scan(&l.id, &l.remaining, &l.expired); err != nil {
+			rows.Close()
+			return err
+		}
+		lots = append(lots, l)
+		tracked += l.remaining
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if opt.lotID != 0 && len(lots) == 0 {
+		return ErrLotNotFound
+	}
+
+	need := quantity
+	take := map[int]int{}
+	order := []int{}
+	hasExpired := false
+	for _, l := range lots {
+		if need == 0 {
+			break
+		}
+		if l.expired && !opt.allowExpired {
+			hasExpired = true
+			continue
+		}
+		n := min(need, l.remaining)
+		take[l.id] = n
+		order = append(order, l.id)
+		need -= n
+	}
+
+	// Sisa diambil dari stok untracked (tidak berlaku jika lot dipilih manual)
+	if need > 0 && opt.lotID == 0 {
+		untracked := max(level-tracked, 0)
+		need -= min(need, untracked)
+	}
+	if need > 0 {
+		if hasExpired {
+			return ErrExpiredStock
+		}
+		return ErrInsufficientStock
+	}
+
+	for _, id := range order {
+		if _, err := tx.ExecContext(ctx, "UPDATE stock_lots SET quantity_remaining = quantity_remaining - $1 WHERE id = $2", take[id], id); err != nil {
+			return err
+		}
+		query := fmt.Sprintf("INSERT INTO stock_lot_allocations (lot_id, quantity, %s) VALUES ($1, $2, $3)", opt.refColumn)
+		if _, err := tx.ExecContext(ctx, query, id, take[id], opt.refID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiveTransferLots membuat ulang lot yang terpakai transfer di lokasi tujuan
+// (nomor lot, expiry & tanggal terima asal dipertahankan supaya urutan FEFO tetap benar)
+func receiveTransferLots(ctx context.Context, tx *sql.Tx, transferID, toLocationID int) error {
+	query := `
+		INSERT INTO stock_lots (product_id, location_id, lot_number, expiry_date, received_at,
+		                        quantity_received, quantity_remaining, goods_receipt_id, source_lot_id)
+		SELECT l.product_id, $2, l.lot_number, l.expiry_date, l.received_at, a.quantity, a.quantity, l.goods_receipt_id, l.id
+		FROM stock_lot_allocations a JOIN stock_lots l ON l.id = a.lot_id
+		WHERE a.transfer_id = $1`
+	_, err := tx.ExecContext(ctx, query, transferID, toLocationID)
+	return err
+}
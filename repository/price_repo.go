@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrPriceNotFound     = errors.New("jadwal harga tidak ditemukan")
+	ErrPriceNotScheduled = errors.New("harga sudah berlaku, tidak bisa dibatalkan")
+	ErrPriceNotFuture    = errors.New("effective_from harus di masa depan")
+)
+
+// PriceRepository mengelola histori & jadwal harga dasar produk.
+// Harga satuan jual (product_units) tidak ikut dijadwalkan.
+type PriceRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client // Untuk hapus cache produk setelah harga terjadwal diterapkan
+}
+
+// priceStatusExpr: status dihitung dari waktu sekarang, bukan disimpan
+const priceStatusExpr = `
+	CASE
+		WHEN effective_from > NOW() THEN 'scheduled'
+		WHEN effective_to IS NULL OR effective_to > NOW() THEN 'current'
+		ELSE 'history'
+	END`
+
+// GetByProduct mengembalikan histori + jadwal harga, terbaru dulu
+func (r *PriceRepository) GetByProduct(ctx context.Context, productID int) ([]models.ProductPrice, error) {
+	query := `
+		SELECT id, product_id, price, currency, effective_from, effective_to, applied_at, created_by, created_at, ` + priceStatusExpr + `
+		FROM product_prices WHERE product_id = $1
+		ORDER BY effective_from DESC, id DESC`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []models.ProductPrice{}
+	for rows.Next() {
+		var p models.ProductPrice
+		err := rows.Scan(&p.ID, &p.ProductID, &p.Price.Amount, &p.Price.Currency, &p.EffectiveFrom, &p.EffectiveTo, &p.AppliedAt, &p.CreatedBy, &p.CreatedAt, &p.Status)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, rows.Err()
+}
+
+// Schedule mencatat harga yang baru berlaku di req.EffectiveFrom.
+// products.price baru berubah saat job worker menerapkannya.
+func (r *PriceRepository) Schedule(ctx context.Context, userID, productID int, req models.SchedulePriceRequest) (models.ProductPrice, error) {
+	if !req.EffectiveFrom.After(time.Now()) {
+		return models.ProductPrice{}, ErrPriceNotFuture
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ProductPrice{}, err
+	}
+	defer tx.Rollback()
+
+	// Lock produk supaya jadwal & perubahan harga langsung tidak saling balapan
+	var currency string
+	err = tx.QueryRowContext(ctx, "SELECT currency FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&currency)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ProductPrice{}, ErrProductNotFound
+		}
+		return models.ProductPrice{}, err
+	}
+	if req.Price.Currency != currency {
+		return models.ProductPrice{}, fmt.Errorf("%w: produk memakai %s", money.ErrCurrencyMismatch, currency)
+	}
+
+	p := models.ProductPrice{
+		ProductID:     productID,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom.UTC(),
+		CreatedBy:     &userID,
+		Status:        models.PriceStatusScheduled,
+	}
+	query := `
+		INSERT INTO product_prices (product_id, price, currency, effective_from, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	if err := tx.QueryRowContext(ctx, query, productID, p.Price.Amount, p.Price.Currency, p.EffectiveFrom, userID).Scan(&p.ID, &p.CreatedAt); err != nil {
+		return models.ProductPrice{}, err
+	}
+	if err := syncPriceWindows(ctx, tx, productID); err != nil {
+		return models.ProductPrice{}, err
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT effective_to FROM product_prices WHERE id = $1", p.ID).Scan(&p.EffectiveTo); err != nil {
+		return models.ProductPrice{}, err
+	}
+
+	return p, tx.Commit()
+}
+
+// Cancel menghapus harga terjadwal yang belum berlaku
+func (r *PriceRepository) Cancel(ctx context.Context, productID, priceID int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var scheduled bool
+	query := "SELECT applied_at IS NULL AND effective_from > NOW() FROM product_prices WHERE id = $1 AND product_id = $2 FOR UPDATE"
+	if err := tx.QueryRowContext(ctx, query, priceID, productID).Scan(&scheduled); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPriceNotFound
+		}
+		return err
+	}
+	if !scheduled {
+		return ErrPriceNotScheduled
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_prices WHERE id = $1", priceID); err != nil {
+		return err
+	}
+	if err := syncPriceWindows(ctx, tx, productID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ApplyDue menerapkan harga terjadwal yang sudah jatuh tempo ke products.price (dipanggil job worker).
+// Mengembalikan produk yang harganya berubah beserta perubahannya, untuk dikirim sebagai product-events.
+// Cache detail & list produk dihapus seperti PatchProduct, termasuk kalau produk berikutnya gagal.
+func (r *PriceRepository) ApplyDue(ctx context.Context) ([]models.ProductPatchResponse, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT DISTINCT product_id FROM product_prices
+		WHERE applied_at IS NULL AND effective_from <= NOW() ORDER BY product_id`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	applied := []models.ProductPatchResponse{}
+	defer func() {
+		if len(applied) == 0 || r.Redis == nil {
+			return
+		}
+		changed := make([]int, 0, len(applied))
+		for _, res := range applied {
+			changed = append(changed, res.Product.ID)
+		}
+		invalidateProductCache(ctx, r.Redis, changed...)
+	}()
+
+	for _, id := range ids {
+		res, changed, err := r.applyDue(ctx, id)
+		if err != nil {
+			return applied, err
+		}
+		if changed {
+			applied = append(applied, res)
+		}
+	}
+	return applied, nil
+}
+
+// applyDue: satu transaksi per produk supaya lock produk tidak ditahan lama
+func (r *PriceRepository) applyDue(ctx context.Context, productID int) (models.ProductPatchResponse, bool, error) {
+	var res models.ProductPatchResponse
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return res, false, err
+	}
+	defer tx.Rollback()
+
+	var current money.Money
+	var deleted bool
+	err = tx.QueryRowContext(ctx, "SELECT price, currency, deleted_at IS NOT NULL FROM products WHERE id = $1 FOR UPDATE", productID).
+		Scan(&current.Amount, &current.Currency, &deleted)
+	if err != nil {
+		return res, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE product_prices SET applied_at = NOW()
+		WHERE product_id = $1 AND applied_at IS NULL AND effective_from <= NOW()`, productID)
+	if err != nil {
+		return res, false, err
+	}
+
+	// Harga yang menang = baris terbaru yang sudah berlaku (bisa saja perubahan langsung yang lebih baru)
+	price, err := effectivePrice(ctx, tx, productID)
+	if err != nil {
+		return res, false, err
+	}
+
+	// Produk di trash tetap ditandai applied, tapi tidak perlu diubah / dikirim eventnya
+	if deleted || price == current {
+		return res, false, tx.Commit()
+	}
+
+	query := "UPDATE products SET price = $1, version = version + 1, updated_at = NOW() WHERE id = $2"
+	if _, err := tx.ExecContext(ctx, query, price.Amount, productID); err != nil {
+		return res, false, err
+	}
+
+	query = "SELECT " + productColumns + ", " + availableStockColumns + " FROM products p WHERE p.id = $1"
+	if err := scanProduct(tx.QueryRowContext(ctx, query, productID), &res.Product); err != nil {
+		return res, false, err
+	}
+	res.Changes = []models.FieldChange{{Field: "price", Old: current, New: price}}
+
+	if err := tx.Commit(); err != nil {
+		return res, false, err
+	}
+	return res, true, nil
+}
+
+// effectivePrice mengembalikan harga dasar yang berlaku saat ini (waktu query dijalankan, bukan awal transaksi).
+// Jatuh ke products.price jika produk belum punya histori. Mata uangnya selalu mata uang produk.
+func effectivePrice(ctx context.Context, tx *sql.Tx, productID int) (money.Money, error) {
+	var price money.Money
+	query := `
+		SELECT COALESCE(
+			(SELECT price FROM product_prices
+			 WHERE product_id = $1 AND effective_from <= clock_timestamp()
+			 ORDER BY effective_from DESC, id DESC LIMIT 1),
+			p.price), p.currency
+		FROM products p WHERE p.id = $1`
+	err := tx.QueryRowContext(ctx, query, productID).Scan(&price.Amount, &price.Currency)
+	return price, err
+}
+
+// recordPrice mencatat perubahan harga langsung (create/update/patch) ke histori.
+// Tidak mencatat apa-apa jika harganya sama dengan yang sedang berlaku.
+func recordPrice(ctx context.Context, tx *sql.Tx, productID int, price money.Money, userID *int) error {
+	current, err := effectivePrice(ctx, tx, productID)
+	if err != nil {
+		return err
+	}
+	if current == price {
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM product_prices WHERE product_id = $1)", productID).Scan(&exists)
+		if err != nil || exists {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO product_prices (product_id, price, currency, effective_from, applied_at, created_by)
+		VALUES ($1, $2, $3, NOW(), NOW(), $4)`
+	if _, err := tx.ExecContext(ctx, query, productID, price.Amount, price.Currency, userID); err != nil {
+		return err
+	}
+	return syncPriceWindows(ctx, tx, productID)
+}
+
+// syncPriceWindows mengisi ulang effective_to = effective_from harga berikutnya
+func syncPriceWindows(ctx context.Context, tx *sql.Tx, productID int) error {
+	query := `
+		UPDATE product_prices pp SET effective_to = w.next_from
+		FROM (
+			SELECT id, LEAD(effective_from) OVER (ORDER BY effective_from, id) AS next_from
+			FROM product_prices WHERE product_id = $1
+		) w
+		WHERE pp.id = w.id AND pp.effective_to IS DISTINCT FROM w.next_from`
+	_, err := tx.ExecContext(ctx, query, productID)
+	return err
+}
@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"phase3-api-architecture/internal/event"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/pkg/stream"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrImportNotFound = errors.New("import tidak ditemukan")
+	ErrProductInTrash = errors.New("produk dengan SKU ini ada di trash, restore dulu")
+)
+
+const (
+	importChunkSize  = 200
+	importStaleAfter = 5 * time.Minute // import 'processing' tanpa heartbeat selama ini dianggap macet
+)
+
+// ProductImportRepository menyimpan file import ke staging lalu memprosesnya di background
+// (RunImporter): upsert berdasarkan SKU per chunk, event 'product-events' dikirim per chunk.
+type ProductImportRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client
+	Kafka *stream.KafkaProducer
+
+	wake chan struct{}
+}
+
+func NewProductImportRepository(db *sql.DB, rdb *redis.Client, kafka *stream.KafkaProducer) *ProductImportRepository {
+	return &ProductImportRepository{DB: db, Redis: rdb, Kafka: kafka, wake: make(chan struct{}, 1)}
+}
+
+const productImportColumns = `id, filename, format, status, total_rows, processed_rows, created_count, updated_count, failed_count,
+	COALESCE(error, ''), created_by, created_at, started_at, finished_at`
+
+func scanProductImport(row rowScanner, imp *models.ProductImport) error {
+	return row.Scan(&imp.ID, &imp.Filename, &imp.Format, &imp.Status, &imp.TotalRows, &imp.ProcessedRows, &imp.Created, &imp.Updated, &imp.Failed,
+		&imp.Error, &imp.CreatedBy, &imp.CreatedAt, &imp.StartedAt, &imp.FinishedAt)
+}
+
+// Create mencatat import baru dengan status uploading (baris belum masuk staging)
+func (r *ProductImportRepository) Create(ctx context.Context, userID int, filename, format string) (models.ProductImport, error) {
+	var imp models.ProductImport
+	query := `
+		INSERT INTO product_imports (filename, format, created_by) VALUES ($1, $2, $3)
+		RETURNING ` + productImportColumns
+	err := scanProductImport(r.DB.QueryRowContext(ctx, query, filename, format, userID), &imp)
+	return imp, err
+}
+
+// StageRows menyimpan satu batch baris file ke staging (COPY, satu transaksi)
+func (r *ProductImportRepository) StageRows(ctx context.Context, importID int, rows []models.ProductImportRow) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("product_import_rows", "import_id", "row_number", "sku", "data", "status", "error"))
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		var data, rowErr interface{}
+		status := "pending"
+		if row.Product != nil {
+			b, err := json.Marshal(row.Product)
+			if err != nil {
+				return err
+			}
+			data = string(b)
+		} else {
+			status, rowErr = "failed", row.Error
+		}
+		if _, err := stmt.ExecContext(ctx, importID, row.Row, row.SKU, data, status, rowErr); err != nil {
+			return err
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Enqueue menandai import siap diproses (semua baris sudah di staging) lalu membangunkan RunImporter
+func (r *ProductImportRepository) Enqueue(ctx context.Context, id int) (models.ProductImport, error) {
+	var imp models.ProductImport
+	query := `
+		UPDATE product_imports i SET status = 'pending', total_rows = s.total, processed_rows = s.failed, failed_count = s.failed
+		FROM (SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE status = 'failed') AS failed
+		      FROM product_import_rows WHERE import_id = $1) s
+		WHERE i.id = $1 AND i.status = 'uploading'
+		RETURNING ` + productImportColumns
+	if err := scanProductImport(r.DB.QueryRowContext(ctx, query, id), &imp); err != nil {
+		if err == sql.ErrNoRows {
+			return imp, ErrImportNotFound
+		}
+		return imp, err
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default: // importer sudah dibangunkan
+	}
+	return imp, nil
+}
+
+// Fail menandai import gagal total dengan pesan error
+func (r *ProductImportRepository) Fail(ctx context.Context, id int, message string) error {
+	_, err := r.DB.ExecContext(ctx,
+		"UPDATE product_imports SET status = 'failed', error = $2, finished_at = NOW() WHERE id = $1", id, message)
+	return err
+}
+
+// GetByID mengembalikan status import beserta laporan error per baris
+func (r *ProductImportRepository) GetByID(ctx context.Context, id int) (models.ProductImport, error) {
+	var imp models.ProductImport
+	err := scanProductImport(r.DB.QueryRowContext(ctx, "SELECT "+productImportColumns+" FROM product_imports WHERE id = $1", id), &imp)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return imp, ErrImportNotFound
+		}
+		return imp, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT row_number, COALESCE(sku, ''), COALESCE(error, '')
+		FROM product_import_rows WHERE import_id = $1 AND status = 'failed'
+		ORDER BY row_number`, id)
+	if err != nil {
+		return imp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.ProductImportError
+		if err := rows.Scan(&e.Row, &e.SKU, &e.Error); err != nil {
+			return imp, err
+		}
+		imp.Errors = append(imp.Errors, e)
+	}
+	return imp, rows.Err()
+}
+
+// RunImporter memproses import yang antre sampai ctx dibatalkan. Dibangunkan langsung oleh Enqueue,
+// interval dipakai untuk import dari replica lain / import yang macet di tengah jalan.
+func (r *ProductImportRepository) RunImporter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+
+		for {
+			id, err := r.claim(ctx)
+			if err != nil {
+				log.Printf("[PRODUCT-IMPORT] Gagal mengambil antrean import: %v", err)
+				break
+			}
+			if id == 0 {
+				break
+			}
+			if err := r.process(ctx, id); err != nil {
+				log.Printf("[PRODUCT-IMPORT] Import %d gagal: %v", id, err)
+				if ctx.Err() != nil {
+					return // shutdown, import diambil alih lagi setelah heartbeat kedaluwarsa
+				}
+				r.Fail(ctx, id, err.Error())
+			}
+		}
+	}
+}
+
+// claim mengambil satu import yang antre (atau macet) untuk diproses replica ini
+func (r *ProductImportRepository) claim(ctx context.Context) (int, error) {
+	var id int
+	query := `
+		UPDATE product_imports SET status = 'processing', started_at = COALESCE(started_at, NOW()), heartbeat_at = NOW()
+		WHERE id = (
+			SELECT id FROM product_imports
+			WHERE status = 'pending' OR (status = 'processing' AND heartbeat_at < NOW() - $1 * INTERVAL '1 second')
+			ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING id`
+	err := r.DB.QueryRowContext(ctx, query, importStaleAfter.Seconds()).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// process menjalankan chunk demi chunk sampai tidak ada baris pending
+func (r *ProductImportRepository) process(ctx context.Context, id int) error {
+	for {
+		n, err := r.processChunk(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	_, err := r.DB.ExecContext(ctx,
+		"UPDATE product_imports SET status = 'completed', finished_at = NOW() WHERE id = $1 AND status = 'processing'", id)
+	if err == nil {
+		log.Printf("[PRODUCT-IMPORT] Import %d selesai", id)
+	}
+	return err
+}
+
+// processChunk meng-upsert maksimal importChunkSize baris pending dalam satu transaksi.
+// Setiap baris memakai savepoint, jadi baris yang gagal (SKU / barcode bentrok, referensi tidak ada)
+// dicatat errornya tanpa membatalkan baris lain di chunk yang sama.
+func (r *ProductImportRepository) processChunk(ctx context.Context, id int) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT row_number, data FROM product_import_rows
+		WHERE import_id = $1 AND status = 'pending'
+		ORDER BY row_number LIMIT $2
+		FOR UPDATE`, id, importChunkSize)
+	if err != nil {
+		return 0, err
+	}
+	type stagedRow struct {
+		row     int
+		product models.Product
+	}
+	staged := []stagedRow{}
+	for rows.Next() {
+		var s stagedRow
+		var data []byte
+		if err := rows.Scan(&s.row, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := json.Unmarshal(data, &s.product); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		staged = append(staged, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(staged) == 0 {
+		return 0, nil
+	}
+
+	var created, updated, failed int
+	events := []stream.Message{}
+	productIDs := []int{}
+	for _, s := range staged {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+			return 0, err
+		}
+
+		p := s.product
+		isNew, rowErr := upsertProductBySKU(ctx, tx, &p)
+		if rowErr != nil {
+			if !isImportRowError(rowErr) {
+				return 0, rowErr
+			}
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); err != nil {
+				return 0, err
+			}
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+				return 0, err
+			}
+			failed++
+			_, err = tx.ExecContext(ctx, `
+				UPDATE product_import_rows SET status = 'failed', error = $3
+				WHERE import_id = $1 AND row_number = $2`, id, s.row, rowErr.Error())
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		// Savepoint dilepas tiap baris supaya tidak menumpuk sampai akhir chunk
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+			return 0, err
+		}
+
+		status, action := "updated", event.ActionUpdate
+		if isNew {
+			status, action = "created", event.ActionCreate
+			created++
+		} else {
+			updated++
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE product_import_rows SET status = $3, product_id = $4
+			WHERE import_id = $1 AND row_number = $2`, id, s.row, status, p.ID)
+		if err != nil {
+			return 0, err
+		}
+		productIDs = append(productIDs, p.ID)
+		events = append(events, stream.Message{
+			Key:   fmt.Sprintf("%d", p.ID),
+			Value: event.ProductEvent{Action: action, Product: p},
+		})
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE product_imports SET processed_rows = processed_rows + $2, created_count = created_count + $3,
+		       updated_count = updated_count + $4, failed_count = failed_count + $5, heartbeat_at = NOW()
+		WHERE id = $1`, id, len(staged), created, updated, failed)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Cache (detail + semua list) & index Elasticsearch diperbarui sekali per chunk
+	if len(productIDs) > 0 {
+		invalidateProductCache(ctx, r.Redis, productIDs...)
+	}
+	if err := r.Kafka.SendMessages("product-events", events); err != nil {
+		log.Printf("[WARNING] Gagal kirim event import %d ke Kafka: %v", id, err)
+	}
+	return len(staged), nil
+}
+
+// upsertProductBySKU membuat produk baru jika SKU belum ada, atau mengubah produk dengan SKU tersebut
+// seperti PUT /products/{id}: stok & satuan dasar produk yang sudah ada tidak ikut diubah.
+func upsertProductBySKU(ctx context.Context, tx *sql.Tx, p *models.Product) (bool, error) {
+	var currency string
+	var deletedAt *time.Time
+	err := tx.QueryRowContext(ctx, "SELECT id, currency, deleted_at FROM products WHERE sku = $1 FOR UPDATE", p.SKU).
+		Scan(&p.ID, &currency, &deletedAt)
+	if err == sql.ErrNoRows {
+		return true, insertProduct(ctx, tx, p)
+	}
+	if err != nil {
+		return false, err
+	}
+	if deletedAt != nil {
+		return false, ErrProductInTrash
+	}
+	if p.Price.Currency != currency {
+		return false, fmt.Errorf("%w: produk memakai %s", money.ErrCurrencyMismatch, currency)
+	}
+
+	query := `
+		UPDATE products SET name=$1, price=$2, barcode=$3, category_id=$4, tax_rate_id=$5,
+		       reorder_point=$6, reorder_quantity=$7, supplier_id=$8,
+		       version = version + 1, updated_at = NOW()
+		WHERE id=$9
+		RETURNING stock, version, base_unit, parent_id, variant_name`
+	err = tx.QueryRowContext(ctx, query, p.Name, p.Price.Amount, p.Barcode, p.CategoryID, p.TaxRateID,
+		p.ReorderPoint, p.ReorderQuantity, p.SupplierID, p.ID).Scan(&p.Stock, &p.Version, &p.BaseUnit, &p.ParentID, &p.VariantName)
+	if err != nil {
+		return false, productWriteError(err)
+	}
+	return false, recordPrice(ctx, tx, p.ID, p.Price, nil)
+}
+
+// isImportRowError: error karena isi baris (dicatat di laporan), selain itu import dihentikan
+func isImportRowError(err error) bool {
+	for _, target := range []error{ErrCategoryNotFound, ErrTaxRateNotFound, ErrSupplierNotFound,
+		ErrDuplicateSKU, ErrDuplicateBarcode, ErrProductInTrash, money.ErrCurrencyMismatch} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
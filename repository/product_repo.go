@@ -3,17 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/url"
 	"phase3-api-architecture/internal/event"
 	"phase3-api-architecture/internal/worker"
 	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
 	"phase3-api-architecture/pkg/resiliency"
 	"phase3-api-architecture/pkg/stream"
+	"phase3-api-architecture/utils"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
 	"github.com/sony/gobreaker"
 )
@@ -24,8 +34,26 @@ type ProductRepository struct {
 	// Tambahan v4
 	Breaker *gobreaker.CircuitBreaker
 	Kafka   *stream.KafkaProducer
+
+	// Backend pencarian list produk (SearchBackendLike / SearchBackendPostgres) dan
+	// batas minimal word_similarity untuk SearchBackendPostgres
+	SearchBackend   string
+	SearchThreshold float64
+
+	// Opsional: autocomplete lewat ES, nil = langsung pakai Postgres
+	ES             *elasticsearch.Client
+	SuggestBreaker *gobreaker.CircuitBreaker
 }
 
+// Backend pencarian produk, dipilih lewat env SEARCH_BACKEND
+const (
+	SearchBackendLike = "like" // default: ILIKE, tanpa ranking
+	// Full-text (tsvector) + trigram dengan ranking, untuk deployment tanpa Elasticsearch
+	SearchBackendPostgres = "postgres"
+)
+
+const DefaultSearchThreshold = 0.3
+
 // tambahkan contructor (agar breaker ter-inisialisasi)
 func NewProductRepository(db *sql.DB, rdb *redis.Client, kafka *stream.KafkaProducer) *ProductRepository {
 	return &ProductRepository{
@@ -33,55 +61,394 @@ func NewProductRepository(db *sql.DB, rdb *redis.Client, kafka *stream.KafkaProd
 		Redis:   rdb,
 		Breaker: resiliency.NewDatabaseBreaker("product-db-query"),
 		Kafka:   kafka,
+
+		SearchBackend:   SearchBackendLike,
+		SearchThreshold: DefaultSearchThreshold,
+		SuggestBreaker:  resiliency.NewDatabaseBreaker("product-suggest-es"),
+	}
+}
+
+// productColumns dipakai semua query baca produk, urutannya harus sama dengan scanProduct.
+// Dua kolom terakhir (stock, reserved) diisi oleh pemanggil karena bisa global atau per lokasi.
+const productColumns = "p.id, p.name, p.price, p.currency, p.base_unit, p.sku, p.barcode, p.category_id, p.tax_rate_id, p.reorder_point, p.reorder_quantity, p.supplier_id, p.parent_id, p.variant_name, p.version, p.deleted_at"
+
+// availableStockColumns = stok tersedia (on-hand dikurangi hold aktif) dan jumlah yang di-hold
+const availableStockColumns = "p.stock - " + activeHoldsExpr + ", " + activeHoldsExpr
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// extra: kolom tambahan setelah kolom produk (mis. nilai urutan untuk cursor)
+func scanProduct(row rowScanner, p *models.Product, extra ...interface{}) error {
+	dest := []interface{}{&p.ID, &p.Name, &p.Price.Amount, &p.Price.Currency, &p.BaseUnit, &p.SKU, &p.Barcode, &p.CategoryID, &p.TaxRateID, &p.ReorderPoint, &p.ReorderQuantity, &p.SupplierID, &p.ParentID, &p.VariantName, &p.Version, &p.DeletedAt, &p.Stock, &p.Reserved}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// productWriteError menerjemahkan pelanggaran constraint saat insert/update produk
+func productWriteError(err error) error {
+	switch {
+	case isForeignKeyViolation(err, "fk_product_category"):
+		return ErrCategoryNotFound
+	case isForeignKeyViolation(err, "fk_product_tax_rate"):
+		return ErrTaxRateNotFound
+	case isForeignKeyViolation(err, "fk_product_parent"):
+		return ErrParentNotFound
+	case isForeignKeyViolation(err):
+		return ErrSupplierNotFound
+	case isUniqueViolation(err, "uq_products_sku"):
+		return ErrDuplicateSKU
+	case isUniqueViolation(err, "uq_products_barcode"):
+		return ErrDuplicateBarcode
+	case isUniqueViolation(err, "uq_product_variants_name"):
+		return ErrDuplicateVariant
+	}
+	return err
+}
+
+var ErrInvalidSort = errors.New("sort harus name, price, stock, created_at atau relevance (hanya saat search), order harus asc atau desc")
+
+var productSorts = []string{"id", "name", "price", "stock", "created_at", "relevance"}
+
+// productSortExpr: kolom urutan yang boleh dipakai list produk. Semuanya NOT NULL dan
+// selalu ditambah p.id sebagai tiebreaker, jadi urutannya stabil untuk keyset pagination.
+func productSortExpr(sort string, q productQuery) (string, bool) {
+	switch sort {
+	case "relevance":
+		return q.rank, q.rank != ""
+	case "id":
+		return "p.id", true
+	case "name":
+		return "p.name", true
+	case "price":
+		return "p.price", true
+	case "stock":
+		// Urut stok fisik (bukan stok tersedia) supaya bisa pakai index
+		return q.stockSort, true
+	case "created_at":
+		return "p.created_at", true
+	}
+	return "", false
+}
+
+// productCursor: isi next_cursor, posisi baris terakhir di halaman sebelumnya
+type productCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeProductCursor(c productCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(cursor string) (productCursor, error) {
+	var c productCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.ID < 1 {
+		return c, ErrInvalidCursor
+	}
+	if !slices.Contains(productSorts, c.Sort) {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// sortKey mengubah nilai kolom urutan hasil scan jadi teks untuk cursor
+func sortKey(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// cursorValue: kebalikan sortKey, dikembalikan ke tipe kolomnya
+func (c productCursor) cursorValue() (interface{}, error) {
+	switch c.Sort {
+	case "name":
+		return c.Value, nil
+	case "created_at":
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case "relevance":
+		f, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(c.Value, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return n, nil
+}
+
+// productQuery: potongan SQL list produk hasil productFilterWhere
+type productQuery struct {
+	from, columns string
+	where         *sqlWhere
+	stockSort     string // kolom untuk sort=stock
+	rank          string // skor relevansi pencarian, kosong kalau tidak ada
+}
+
+// productFilterWhere: FROM, kolom & WHERE list produk sesuai filter (tanpa cursor).
+// stock yang dikembalikan = stok tersedia (on-hand dikurangi hold aktif), atau stok fisik
+// di lokasi kalau LocationID diisi (hold bersifat global, tidak dikurangi). Filter stok
+// memakai angka yang sama dengan yang ditampilkan. Produk di trash tidak ikut.
+func productFilterWhere(f models.ProductFilter, searchBackend string) productQuery {
+	q := productQuery{
+		from:      " FROM products p",
+		columns:   productColumns + ", " + availableStockColumns,
+		where:     &sqlWhere{},
+		stockSort: "p.stock",
+	}
+	where := q.where
+	stockExpr := "(p.stock - " + activeHoldsExpr + ")"
+	where.add("p.deleted_at IS NULL")
+
+	if f.LocationID != 0 {
+		q.from += " JOIN stock_levels sl ON sl.product_id = p.id"
+		q.columns = productColumns + ", sl.quantity, 0"
+		q.stockSort, stockExpr = "sl.quantity", "sl.quantity"
+		where.add("sl.location_id = ?", f.LocationID)
+	}
+	switch {
+	case f.Search == "":
+	case searchBackend == SearchBackendPostgres:
+		// Cocok kata utuh (nama / sku / barcode) atau mirip sebagian nama (typo, ketik setengah).
+		// <% memakai pg_trgm.word_similarity_threshold yang di-set per query.
+		term := where.param(f.Search)
+		where.add(fmt.Sprintf("(p.search_vector @@ plainto_tsquery('simple', %[1]s) OR %[1]s <%% p.name)", term))
+		q.rank = fmt.Sprintf("(ts_rank(p.search_vector, plainto_tsquery('simple', %[1]s)) + word_similarity(%[1]s, p.name))::float8", term)
+	default:
+		where.add("p.name ILIKE ?", "%"+f.Search+"%")
+	}
+	// Filter kategori ikut menampilkan produk di sub-kategorinya
+	if f.CategoryID != 0 {
+		where.add("p.category_id IN ("+fmt.Sprintf(categorySubtreeQuery, len(where.args)+1)+")", f.CategoryID)
+	}
+	if len(f.IDs) > 0 {
+		where.add("p.id = ANY(?)", pq.Array(f.IDs))
+	}
+	if f.MinPrice != nil {
+		where.add("p.currency = ? AND p.price >= ?", f.MinPrice.Currency, f.MinPrice.Amount)
+	}
+	if f.MaxPrice != nil {
+		where.add("p.currency = ? AND p.price <= ?", f.MaxPrice.Currency, f.MaxPrice.Amount)
 	}
+	if f.MinStock != nil {
+		where.add(stockExpr+" >= ?", *f.MinStock)
+	}
+	if f.MaxStock != nil {
+		where.add(stockExpr+" <= ?", *f.MaxStock)
+	}
+	if f.CreatedFrom != nil {
+		where.add("p.created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		where.add("p.created_at < ?", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		where.add("p.updated_at >= ?", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		where.add("p.updated_at < ?", *f.UpdatedTo)
+	}
+	return q
 }
 
-func (r *ProductRepository) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, error) {
-	// Check cache
-	// Contoh Key: products:page:1:limit:10:search:phone
-	cacheKey := fmt.Sprintf("products:page:%d:limit:%d:search:%s", filter.Page, filter.Limit, filter.Search)
+// productListVersionKey: versi cache list produk, bagian dari setiap key list. Menaikkan versi
+// membuang semua list lama sekaligus tanpa SCAN; key versi lama habis sendiri oleh TTL.
+const productListVersionKey = "products:list:version"
+
+// invalidateProductCache menghapus cache detail produk ids dan semua cache list produk.
+// Dipanggil setelah commit di setiap jalur tulis produk.
+func invalidateProductCache(ctx context.Context, rdb *redis.Client, ids ...int) {
+	pipe := rdb.Pipeline()
+	for _, id := range ids {
+		pipe.Del(ctx, fmt.Sprintf("product:%d", id))
+	}
+	pipe.Incr(ctx, productListVersionKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[WARNING] Gagal invalidasi cache produk: %v", err)
+	}
+}
+
+// productListCacheKey: key cache list produk dari filter yang sudah dinormalisasi. url.Values
+// mengurutkan key dan nilai kosong tidak ditulis, jadi query setara selalu dapat key yang sama.
+func productListCacheKey(f models.ProductFilter, version int64) string {
+	v := url.Values{}
+	v.Set("limit", strconv.Itoa(f.Limit))
+	if f.Cursor != "" {
+		// Sort / order sudah ada di dalam cursor
+		v.Set("cursor", f.Cursor)
+	} else {
+		v.Set("page", strconv.Itoa(f.Page))
+		v.Set("sort", f.Sort)
+		v.Set("order", f.Order)
+	}
+	if f.Search != "" {
+		// ILIKE tidak membedakan huruf besar kecil
+		v.Set("search", strings.ToLower(f.Search))
+	}
+	setInt := func(key string, n *int) {
+		if n != nil {
+			v.Set(key, strconv.Itoa(*n))
+		}
+	}
+	setMoney := func(key string, m *money.Money) {
+		if m != nil {
+			v.Set(key, m.Currency+":"+strconv.FormatInt(m.Amount, 10))
+		}
+	}
+	setTime := func(key string, t *time.Time) {
+		if t != nil {
+			v.Set(key, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	if f.LocationID != 0 {
+		setInt("location", &f.LocationID)
+	}
+	if f.CategoryID != 0 {
+		setInt("category", &f.CategoryID)
+	}
+	for _, id := range f.IDs {
+		v.Add("id", strconv.Itoa(id))
+	}
+	setMoney("price_min", f.MinPrice)
+	setMoney("price_max", f.MaxPrice)
+	setInt("stock_min", f.MinStock)
+	setInt("stock_max", f.MaxStock)
+	setTime("created_from", f.CreatedFrom)
+	setTime("created_to", f.CreatedTo)
+	setTime("updated_from", f.UpdatedFrom)
+	setTime("updated_to", f.UpdatedTo)
+	return fmt.Sprintf("products:list:v%d:", version) + v.Encode()
+}
+
+// GetAll: list produk dengan keyset pagination. Tanpa cursor pakai Page (OFFSET) seperti
+// dulu, tapi urutannya sekarang tetap jadi halaman tidak loncat / dobel.
+func (r *ProductRepository) GetAll(ctx context.Context, filter models.ProductFilter) (models.ProductPage, error) {
+	var page models.ProductPage
+
+	if filter.Cursor != "" {
+		c, err := decodeProductCursor(filter.Cursor)
+		if err != nil {
+			return page, err
+		}
+		filter.Sort, filter.Order = c.Sort, "asc"
+		if c.Desc {
+			filter.Order = "desc"
+		}
+	}
+	q := productFilterWhere(filter, r.SearchBackend)
+	// Hasil pencarian default urut relevansi tertinggi
+	if filter.Sort == "" && q.rank != "" {
+		filter.Sort = "relevance"
+		if filter.Order == "" {
+			filter.Order = "desc"
+		}
+	}
+	if filter.Sort == "" {
+		filter.Sort = "id"
+	}
+	if filter.Order == "" {
+		filter.Order = "asc"
+	}
+	sortExpr, ok := productSortExpr(filter.Sort, q)
+	if !ok || (filter.Order != "asc" && filter.Order != "desc") {
+		return page, ErrInvalidSort
+	}
+	offset := filter.GetOffset()
+
+	// Check cache, key dari versi list + filter yang sudah dinormalisasi (versi kosong = 0)
+	version, _ := r.Redis.Get(ctx, productListVersionKey).Int64()
+	cacheKey := productListCacheKey(filter, version)
 	cachedData, err := r.Redis.Get(ctx, cacheKey).Result()
 	if err == nil {
-		var products []models.Product
-		json.Unmarshal([]byte(cachedData), &products)
-		return products, nil
+		json.Unmarshal([]byte(cachedData), &page)
+		return page, nil
 	}
 
 	result, err := r.Breaker.Execute(func() (interface{}, error) {
-		// Build query dengan filter
-		query := "SELECT id, name, price, stock FROM products WHERE 1=1"
-		var args []interface{}
-		argCounter := 1
+		from, columns, where := q.from, q.columns, q.where
 
-		// Tambahkan filter pencarian jika ada
-		if filter.Search != "" {
-			query += fmt.Sprintf(" AND name ILIKE $%d", argCounter)
-			args = append(args, "%"+filter.Search+"%")
-			argCounter++
+		// Count & list dalam satu transaksi supaya setting threshold berlaku untuk keduanya
+		tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, err
 		}
+		defer tx.Rollback()
 
-		// Tambahkan pagination
-		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
-		args = append(args, filter.Limit, filter.GetOffset())
+		if q.rank != "" {
+			threshold := strconv.FormatFloat(r.SearchThreshold, 'f', -1, 64)
+			if _, err := tx.ExecContext(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", threshold); err != nil {
+				return nil, err
+			}
+		}
 
-		// Ambil data dari database
-		rows, err := r.DB.QueryContext(ctx, query, args...)
+		p := models.ProductPage{Sort: filter.Sort, Order: filter.Order}
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*)"+from+where.String(), where.args...).Scan(&p.Total); err != nil {
+			return nil, err
+		}
+
+		op, dir := ">", "ASC"
+		if filter.Order == "desc" {
+			op, dir = "<", "DESC"
+		}
+		if filter.Cursor != "" {
+			c, _ := decodeProductCursor(filter.Cursor)
+			v, err := c.cursorValue()
+			if err != nil {
+				return nil, err
+			}
+			where.add(fmt.Sprintf("(%s, p.id) %s (?, ?)", sortExpr, op), v, c.ID)
+		}
+
+		// Ambil satu baris lebih untuk tahu masih ada halaman berikutnya
+		query := "SELECT " + columns + ", " + sortExpr + from + where.String() +
+			fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT %d", sortExpr, dir, dir, filter.Limit+1)
+		if filter.Cursor == "" && offset > 0 {
+			query += fmt.Sprintf(" OFFSET %d", offset)
+		}
+
+		rows, err := tx.QueryContext(ctx, query, where.args...)
 		if err != nil {
 			return nil, err
 		}
 		defer rows.Close()
 
-		// Scan hasil query
-		var products []models.Product
+		p.Items = []models.Product{}
+		var lastKey interface{}
 		for rows.Next() {
-			var p models.Product
-			if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock); err != nil {
+			var prod models.Product
+			var key interface{}
+			if err := scanProduct(rows, &prod, &key); err != nil {
 				return nil, err
 			}
-			products = append(products, p)
+			if len(p.Items) == filter.Limit {
+				p.NextCursor = encodeProductCursor(productCursor{
+					Sort: filter.Sort, Desc: filter.Order == "desc", Value: sortKey(lastKey), ID: p.Items[len(p.Items)-1].ID,
+				})
+				break
+			}
+			p.Items = append(p.Items, prod)
+			lastKey = key
 		}
-
-		return products, nil
+		return p, rows.Err()
 	})
 
 	// handle error dari breaker
@@ -90,19 +457,19 @@ func (r *ProductRepository) GetAll(ctx context.Context, filter models.ProductFil
 		if err == gobreaker.ErrOpenState {
 			// sirkuit putus! jangan pakai DB
 			fmt.Println("[DEBUG REPO] CIRCUIT IS OPEN! Returning ErrServiceUnavailable")
-			return nil, resiliency.ErrServiceUnavailbale
+			return page, resiliency.ErrServiceUnavailbale
 		}
-		return nil, err
+		return page, err
 	}
 
 	// casting result interface{} kembali ke tipe asli
-	products := result.([]models.Product)
+	page = result.(models.ProductPage)
 
 	// Simpan ke cache (5 menit)
-	dataJson, _ := json.Marshal(products)
+	dataJson, _ := json.Marshal(page)
 	r.Redis.Set(ctx, cacheKey, dataJson, 5*time.Minute)
 
-	return products, nil
+	return page, nil
 }
 
 func (r *ProductRepository) GetByID(ctx context.Context, id int) (models.Product, error) {
@@ -117,12 +484,19 @@ func (r *ProductRepository) GetByID(ctx context.Context, id int) (models.Product
 	}
 
 	// Ambil data dari database
-	query := "SELECT id, name, price, stock FROM products WHERE id = $1"
-	err = r.DB.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
+	query := "SELECT " + productColumns + ", " + availableStockColumns + " FROM products p WHERE p.id = $1 AND p.deleted_at IS NULL"
+	err = scanProduct(r.DB.QueryRowContext(ctx, query, id), &p)
 	if err != nil {
 		return p, err
 	}
 
+	if p.Units, err = getProductUnits(ctx, r.DB, id); err != nil {
+		return p, err
+	}
+	if p.ConvertedPrices, err = convertPrice(ctx, r.DB, p.Price); err != nil {
+		return p, err
+	}
+
 	// Simpan ke cache (10 menit)
 	dataJson, _ := json.Marshal(p)
 	r.Redis.Set(ctx, cacheKey, dataJson, 10*time.Minute)
@@ -130,16 +504,34 @@ func (r *ProductRepository) GetByID(ctx context.Context, id int) (models.Product
 	return p, nil
 }
 
+// GetByBarcode mencari produk aktif berdasarkan barcode EAN-13 (dipakai kasir saat scan).
+// Tidak di-cache terpisah: barcode bisa berubah lewat update dan cache per ID sudah cukup.
+func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (models.Product, error) {
+	var p models.Product
+	query := "SELECT " + productColumns + ", " + availableStockColumns + " FROM products p WHERE p.barcode = $1 AND p.deleted_at IS NULL"
+	err := scanProduct(r.DB.QueryRowContext(ctx, query, barcode), &p)
+	if err == sql.ErrNoRows {
+		return p, ErrProductNotFound
+	}
+	return p, err
+}
+
 func (r *ProductRepository) Create(ctx context.Context, p *models.Product) error {
-	// simpan ke db
-	query := "INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id"
-	err := r.DB.QueryRowContext(ctx, query, p.Name, p.Price, p.Stock).Scan(&p.ID)
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	if err := insertProduct(ctx, tx, p); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
 	// hapus cache
-	r.Redis.Del(ctx, "products:all")
+	invalidateProductCache(ctx, r.Redis)
 
 	// kirim event ke kafka
 	// kirim ke topic "product-events" agar worker elasticsearch menangkapnya
@@ -156,17 +548,81 @@ func (r *ProductRepository) Create(ctx context.Context, p *models.Product) error
 	return nil
 }
 
-func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error {
-	// 1. Update DB (Code Lama)
-	query := "UPDATE products SET name=$1, price=$2, stock=$3 WHERE id=$4"
-	_, err := r.DB.ExecContext(ctx, query, p.Name, p.Price, p.Stock, p.ID)
+// insertProduct menyimpan produk baru di dalam tx (dipakai Create & import massal).
+// Stok awal dicatat lewat stock_levels lokasi default, harga awal jadi baris pertama histori harga.
+func insertProduct(ctx context.Context, tx *sql.Tx, p *models.Product) error {
+	if p.ParentID != nil {
+		if err := checkVariantParent(ctx, tx, p); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO products (name, price, currency, stock, base_unit, sku, barcode, category_id, tax_rate_id, reorder_point, reorder_quantity, supplier_id,
+		                      parent_id, variant_name)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, version`
+	if p.BaseUnit == "" {
+		p.BaseUnit = "pcs"
+	}
+	err := tx.QueryRowContext(ctx, query, p.Name, p.Price.Amount, p.Price.Currency, p.BaseUnit, p.SKU, p.Barcode, p.CategoryID, p.TaxRateID,
+		p.ReorderPoint, p.ReorderQuantity, p.SupplierID, p.ParentID, p.VariantName).Scan(&p.ID, &p.Version)
+	if err != nil {
+		return productWriteError(err)
+	}
+
+	locationID, err := defaultLocationID(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := adjustLocationStock(ctx, tx, locationID, p.ID, p.Stock); err != nil {
+		return err
+	}
+	return recordPrice(ctx, tx, p.ID, p.Price, nil)
+}
+
+// Update menyimpan perubahan produk jika versinya masih sama dengan expectedVersion
+// (optimistic lock). expectedVersion 0 berarti tanpa cek versi.
+func (r *ProductRepository) Update(ctx context.Context, p *models.Product, expectedVersion int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	// 1. Update DB
+	// Stok tidak ikut di-overwrite: perubahan stok lewat goods receipt / stock adjustment (additive)
+	// supaya penjualan yang terjadi di antara read & write tidak hilang.
+	query := `
+		UPDATE products SET name=$1, price=$2, sku=$3, barcode=$4, category_id=$5,
+		       reorder_point=$6, reorder_quantity=$7, supplier_id=$8, tax_rate_id=$11,
+		       version = version + 1, updated_at = NOW()
+		WHERE id=$9 AND deleted_at IS NULL AND ($10 = 0 OR version = $10)
+		RETURNING stock, version, base_unit, currency, parent_id, variant_name`
+	var currency string
+	err = tx.QueryRowContext(ctx, query, p.Name, p.Price.Amount, p.SKU, p.Barcode, p.CategoryID,
+		p.ReorderPoint, p.ReorderQuantity, p.SupplierID, p.ID, expectedVersion, p.TaxRateID).
+		Scan(&p.Stock, &p.Version, &p.BaseUnit, &currency, &p.ParentID, &p.VariantName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return r.versionConflict(ctx, p.ID)
+		}
+		return productWriteError(err)
+	}
+	// Mata uang produk tidak bisa diganti (histori harga, satuan & transaksi memakai mata uang yang sama)
+	if p.Price.Currency != currency {
+		return fmt.Errorf("%w: produk memakai %s", money.ErrCurrencyMismatch, currency)
+	}
+
+	// Harga lama tetap tersimpan di histori
+	if err := recordPrice(ctx, tx, p.ID, p.Price, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
 	// 2. Hapus Cache (Code Lama)
-	r.Redis.Del(ctx, "products:all")
-	r.Redis.Del(ctx, fmt.Sprintf("product:%d", p.ID))
+	invalidateProductCache(ctx, r.Redis, p.ID)
 
 	// 3. [BARU] KIRIM EVENT KE KAFKA
 	evt := event.ProductEvent{
@@ -180,20 +636,157 @@ func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error
 	return nil
 }
 
-func (r *ProductRepository) Delete(ctx context.Context, id int) error {
-	// 1. Delete DB (Code Lama)
-	query := "DELETE FROM products WHERE id = $1"
-	_, err := r.DB.ExecContext(ctx, query, id)
+// Patch mengubah sebagian field produk. apply menerima kondisi terbaru (sudah di-lock)
+// dan mengembalikan hasil patch-nya; hanya kolom yang berubah yang ditulis.
+// Perubahan stok dicatat sebagai stock adjustment di lokasi utama.
+func (r *ProductRepository) Patch(ctx context.Context, userID, id, expectedVersion int, apply func(models.ProductFields) (models.ProductFields, error)) ([]models.FieldChange, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var cur models.ProductFields
+	var version int
+	query := `
+		SELECT name, price, currency, stock, reorder_point, reorder_quantity, supplier_id, sku, barcode, category_id, tax_rate_id, version
+		FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, id).
+		Scan(&cur.Name, &cur.Price.Amount, &cur.Price.Currency, &cur.Stock, &cur.ReorderPoint, &cur.ReorderQuantity, &cur.SupplierID,
+			&cur.SKU, &cur.Barcode, &cur.CategoryID, &cur.TaxRateID, &version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	if expectedVersion != 0 && version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+
+	next, err := apply(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := cur.Diff(next)
+	if len(changes) == 0 {
+		return changes, nil // tidak ada yang berubah, versi tidak naik
+	}
+
+	// Kolom biasa ditulis apa adanya, stok lewat adjustLocationStock supaya stock_levels ikut sinkron
+	sets := []string{"version = version + 1", "updated_at = NOW()"}
+	args := []interface{}{}
+	for _, c := range changes {
+		switch c.Field {
+		case "stock":
+			continue
+		case "price":
+			if next.Price.Currency != cur.Price.Currency {
+				return nil, fmt.Errorf("%w: produk memakai %s", money.ErrCurrencyMismatch, cur.Price.Currency)
+			}
+			args = append(args, next.Price.Amount)
+		default:
+			args = append(args, c.New)
+		}
+		sets = append(sets, fmt.Sprintf("%s = $%d", c.Field, len(args)))
+	}
+	args = append(args, id)
+	query = fmt.Sprintf("UPDATE products SET %s WHERE id = $%d", strings.Join(sets, ", "), len(args))
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, productWriteError(err)
+	}
+
+	if delta := next.Stock - cur.Stock; delta != 0 {
+		if err := r.patchStock(ctx, tx, userID, id, delta); err != nil {
+			return nil, err
+		}
+	}
+	if next.Price != cur.Price {
+		if err := recordPrice(ctx, tx, id, next.Price, &userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	invalidateProductCache(ctx, r.Redis, id)
+
+	// Payload pakai data terbaru (stok tersedia) seperti event update biasa
+	if p, err := r.GetByID(ctx, id); err == nil {
+		evt := event.ProductEvent{
+			Action:  event.ActionUpdate,
+			Product: p,
+			Changes: changes,
+		}
+		if err := r.Kafka.SendMessage("product-events", fmt.Sprintf("%d", id), evt); err != nil {
+			fmt.Printf("[WARNING] Gagal kirim event update ke Kafka: %v\n", err)
+		}
+	}
+
+	return changes, nil
+}
+
+// patchStock menyamakan stok fisik ke nilai baru lewat stock adjustment di lokasi utama
+func (r *ProductRepository) patchStock(ctx context.Context, tx *sql.Tx, userID, id, delta int) error {
+	if delta < 0 {
+		// Sama seperti koreksi stok: stok yang sedang di-hold tidak boleh ikut dikurangi
+		available, err := lockAvailableStock(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if available < -delta {
+			return ErrInsufficientStock
+		}
+	}
+
+	locationID, err := defaultLocationID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	var adjustmentID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO stock_adjustments (product_id, location_id, delta, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`, id, locationID, delta, "PATCH /products/{id}", userID).Scan(&adjustmentID)
+	if err != nil {
+		return err
+	}
+
+	if delta < 0 {
+		consume := lotConsumption{refColumn: "adjustment_id", refID: adjustmentID, allowExpired: true}
+		if err := consumeLots(ctx, tx, locationID, id, -delta, consume); err != nil {
+			return err
+		}
+	}
+	return adjustLocationStock(ctx, tx, locationID, id, delta)
+}
+
+// Delete memindahkan produk ke trash (soft delete) dengan cek versi yang sama seperti Update.
+// Data tidak benar-benar dihapus karena masih direferensikan transaksi, PO, dll.
+func (r *ProductRepository) Delete(ctx context.Context, id int, expectedVersion int) error {
+	// 1. Soft delete di DB
+	query := `
+		UPDATE products SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+	res, err := r.DB.ExecContext(ctx, query, id, expectedVersion)
 	if err != nil {
 		return err
 	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return r.versionConflict(ctx, id)
+	}
 
 	// 2. Hapus Cache (Code Lama)
-	r.Redis.Del(ctx, "products:all")
-	r.Redis.Del(ctx, fmt.Sprintf("product:%d", id))
+	invalidateProductCache(ctx, r.Redis, id)
 
 	// 3. [BARU] KIRIM EVENT KE KAFKA
 	// Payload produk kosong, cukup ID-nya saja yang penting untuk event delete
+	// (worker menghapus dokumennya dari index search)
 	evt := event.ProductEvent{
 		Action:  event.ActionDelete,
 		Product: models.Product{ID: id},
@@ -205,51 +798,291 @@ func (r *ProductRepository) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-func (r *ProductRepository) Checkout(ctx context.Context, userID int, userEmail string, req models.CheckoutRequest) error {
+// GetDeleted mengembalikan isi trash, yang terbaru dihapus lebih dulu
+func (r *ProductRepository) GetDeleted(ctx context.Context, filter models.ProductFilter) ([]models.Product, error) {
+	query := "SELECT " + productColumns + ", p.stock, 0 FROM products p WHERE p.deleted_at IS NOT NULL"
+	args := []interface{}{}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		query += " AND p.name ILIKE $1"
+	}
+	query += fmt.Sprintf(" ORDER BY p.deleted_at DESC, p.id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.GetOffset())
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		var p models.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// Restore mengeluarkan produk dari trash. ErrProductNotFound jika produk tidak ada di trash.
+func (r *ProductRepository) Restore(ctx context.Context, id int) (models.Product, error) {
+	query := `
+		UPDATE products SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL`
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.Product{}, err
+	} else if n == 0 {
+		return models.Product{}, ErrProductNotFound
+	}
+
+	invalidateProductCache(ctx, r.Redis, id)
+
+	p, err := r.GetByID(ctx, id)
+	if err != nil {
+		return p, err
+	}
+
+	// Index ulang ke search
+	evt := event.ProductEvent{
+		Action:  event.ActionRestore,
+		Product: p,
+	}
+	if err := r.Kafka.SendMessage("product-events", fmt.Sprintf("%d", id), evt); err != nil {
+		fmt.Printf("[WARNING] Gagal kirim event restore ke Kafka: %v\n", err)
+	}
+
+	return p, nil
+}
+
+// PurgeDeleted menghapus permanen produk yang sudah di trash lebih lama dari retention
+// dan tidak direferensikan data lain (transaksi, reservasi, PO, penerimaan, transfer, koreksi stok, lot, promosi).
+// Hanya butuh DB, dipanggil dari job worker.
+func (r *ProductRepository) PurgeDeleted(ctx context.Context, retention time.Duration) (int, error) {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT p.id FROM products p
+		WHERE p.deleted_at < NOW() - make_interval(secs => $1)
+		  AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM reservations rv WHERE rv.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM purchase_order_items poi WHERE poi.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM goods_receipt_items gri WHERE gri.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_transfer_items sti WHERE sti.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_adjustments sa WHERE sa.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_lots lt WHERE lt.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM promotions pr WHERE pr.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_levels sl WHERE sl.product_id = p.id AND sl.quantity <> 0)
+		FOR UPDATE OF p SKIP LOCKED`
+	rows, err := tx.QueryContext(ctx, query, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Baris stok kosong, satuan jual, histori harga & snapshot stok ikut dihapus
+	if _, err := tx.ExecContext(ctx, "DELETE FROM stock_levels WHERE product_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_units WHERE product_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_prices WHERE product_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM stock_snapshots WHERE product_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM products WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// versionConflict dipanggil saat UPDATE/DELETE bersyarat tidak mengenai baris apa pun:
+// bedakan produk memang tidak ada vs versinya sudah berubah
+func (r *ProductRepository) versionConflict(ctx context.Context, id int) error {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)"
+	if err := r.DB.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
 		return err
 	}
+	if !exists {
+		return ErrProductNotFound
+	}
+	return ErrVersionMismatch
+}
+
+func (r *ProductRepository) Checkout(ctx context.Context, userID int, userEmail string, req models.CheckoutRequest) (models.CheckoutResult, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
 
 	defer tx.Rollback()
 
-	queryUpdate := `
-		UPDATE products 
-		SET stock = stock - $1 
-		WHERE id = $2 AND stock >= $1 
-		RETURNING price`
+	// Varian punya stok, harga & satuan sendiri: sisa checkout memakai ID varian
+	if req.ProductID, err = variantProductID(ctx, tx, req.ProductID, req.VariantID); err != nil {
+		return models.CheckoutResult{}, err
+	}
 
-	var pricePerItem int
-	err = tx.QueryRowContext(ctx, queryUpdate, req.Quantity, req.ProductID).Scan(&pricePerItem)
+	// Kunci produk dulu supaya hold lain tidak ikut terjual
+	available, err := lockAvailableStock(ctx, tx, req.ProductID)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
 
+	// Harga per satuan yang dibeli, stok dihitung dalam satuan dasar
+	sale, err := resolveSaleUnit(ctx, tx, req.ProductID, req.UnitID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("stok tidak mencukupi atau produk tidak ditemukan")
+		return models.CheckoutResult{}, err
+	}
+	baseQuantity, err := sale.baseQuantity(req.Quantity)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
+
+	locationID := req.LocationID
+	if locationID == 0 {
+		if locationID, err = defaultLocationID(ctx, tx); err != nil {
+			return models.CheckoutResult{}, err
+		}
+	}
+
+	if req.ReservationID != 0 {
+		// Checkout dari hold: stok sudah disisihkan untuk user ini
+		if err := convertReservation(ctx, tx, req.ReservationID, userID, req.ProductID, baseQuantity); err != nil {
+			return models.CheckoutResult{}, err
+		}
+	} else if available < baseQuantity {
+		return models.CheckoutResult{}, ErrInsufficientStock
+	}
+
+	// Semua nominal dihitung dengan aritmatika Money: quantity besar ditolak (ErrOverflow), bukan wrap-around
+	subtotal, err := sale.price.Mul(int64(req.Quantity))
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
+
+	// Promosi & kupon dievaluasi di transaksi yang sama supaya kuota pemakaiannya konsisten
+	discounts, err := applyPromotions(ctx, tx, userID, req.ProductID, sale.price, subtotal, req.Quantity, req.CouponCode)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
+	result := models.CheckoutResult{Subtotal: subtotal, DiscountAmount: money.New(0, subtotal.Currency), Discounts: discounts}
+	for _, d := range discounts {
+		if result.DiscountAmount, err = result.DiscountAmount.Add(d.Amount); err != nil {
+			return models.CheckoutResult{}, err
+		}
+	}
+	if result.TotalPrice, err = result.Subtotal.Sub(result.DiscountAmount); err != nil {
+		return models.CheckoutResult{}, err
+	}
+	result.TaxAmount = money.New(0, subtotal.Currency)
+
+	// Pajak dihitung dari harga setelah diskon
+	rate, err := resolveTaxRate(ctx, tx, req.ProductID)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
+	if rate != nil {
+		result.TaxRateID = &rate.ID
+		result.TaxName = rate.Name
+		result.TaxRateBP = rate.RateBP
+		result.TaxInclusive = rate.Inclusive
+		result.TaxAmount.Amount = utils.ComputeTax(result.TotalPrice.Amount, rate.RateBP, rate.Inclusive, rate.Rounding)
+		if !rate.Inclusive {
+			if result.TotalPrice, err = result.TotalPrice.Add(result.TaxAmount); err != nil {
+				return models.CheckoutResult{}, err
+			}
 		}
-		return err
 	}
+	totalPrice := result.TotalPrice
 
-	totalPrice := pricePerItem * req.Quantity
+	// HPP disimpan per transaksi untuk laporan gross margin
+	costAmount, err := saleCost(ctx, tx, req.ProductID, baseQuantity, totalPrice.Currency)
+	if err != nil {
+		return models.CheckoutResult{}, err
+	}
 
 	queryInsert := `
-		INSERT INTO transactions (user_id, product_id, quantity, total_price) 
-		VALUES ($1, $2, $3, $4)`
+		INSERT INTO transactions (user_id, product_id, quantity, total_price, location_id, unit_id, unit_quantity,
+		                          subtotal, discount_amount, tax_rate_id, tax_rate_bp, tax_inclusive, tax_amount, currency, cost_amount) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`
 
-	_, err = tx.ExecContext(ctx, queryInsert, userID, req.ProductID, req.Quantity, totalPrice)
+	var transactionID int
+	err = tx.QueryRowContext(ctx, queryInsert, userID, req.ProductID, baseQuantity, totalPrice.Amount, locationID, sale.unitID, req.Quantity,
+		result.Subtotal.Amount, result.DiscountAmount.Amount, result.TaxRateID, result.TaxRateBP, result.TaxInclusive, result.TaxAmount.Amount,
+		totalPrice.Currency, costAmount).Scan(&transactionID)
 	if err != nil {
-		return err
+		return models.CheckoutResult{}, err
+	}
+	result.TransactionID = transactionID
+
+	if err := recordDiscounts(ctx, tx, transactionID, userID, discounts); err != nil {
+		return models.CheckoutResult{}, err
+	}
+
+	// Ambil dari lot yang paling dulu kedaluwarsa (FEFO), lot expired tidak boleh terjual
+	consume := lotConsumption{refColumn: "transaction_id", refID: transactionID}
+	if err := consumeLots(ctx, tx, locationID, req.ProductID, baseQuantity, consume); err != nil {
+		return models.CheckoutResult{}, err
+	}
+
+	// Kurangi stok di lokasi yang dipilih (products.stock ikut berkurang)
+	if err := adjustLocationStock(ctx, tx, locationID, req.ProductID, -baseQuantity); err != nil {
+		return models.CheckoutResult{}, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return err
+		return models.CheckoutResult{}, err
 	}
 
+	invalidateProductCache(ctx, r.Redis, req.ProductID)
+
 	task := worker.TaskSendInvoice{
 		UserID:     userID,
 		Email:      userEmail,
 		ProductID:  req.ProductID,
-		Quantity:   req.Quantity,
+		Quantity:   baseQuantity,
 		TotalPrice: totalPrice,
+
+		UnitName:     sale.name,
+		UnitQuantity: req.Quantity,
+
+		Subtotal:       result.Subtotal,
+		DiscountAmount: result.DiscountAmount,
+		TaxName:        result.TaxName,
+		TaxInclusive:   result.TaxInclusive,
+		TaxAmount:      result.TaxAmount,
 	}
 
 	err = r.Kafka.SendMessage("checkout-events", fmt.Sprintf("%d", userID), task)
@@ -260,5 +1093,71 @@ func (r *ProductRepository) Checkout(ctx context.Context, userID int, userEmail
 		// Tidak return error agar user tetap tau checkout berhasil (meski email telat)
 	}
 
-	return nil
+	return result, nil
+}
+
+// saleCost menghitung HPP baris dari cost_price produk.
+// nil jika harga pokok belum diketahui atau mata uang pembeliannya beda dengan mata uang transaksi.
+func saleCost(ctx context.Context, tx *sql.Tx, productID, baseQuantity int, currency string) (*int64, error) {
+	var unitCost money.Money
+	err := tx.QueryRowContext(ctx, "SELECT cost_price, cost_currency FROM products WHERE id = $1", productID).
+		Scan(&unitCost.Amount, &unitCost.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if unitCost.Amount <= 0 || unitCost.Currency != currency {
+		return nil, nil
+	}
+
+	cost, err := unitCost.Mul(int64(baseQuantity))
+	if err != nil {
+		return nil, err
+	}
+	return &cost.Amount, nil
+}
+
+// saleUnit adalah satuan yang dipakai saat checkout
+type saleUnit struct {
+	unitID *int // nil = satuan dasar
+	name   string
+	factor int
+	price  money.Money
+}
+
+// baseQuantity mengonversi jumlah ke satuan dasar. Kolom stok bertipe INT, jadi hasil yang
+// melewati int32 (atau overflow) ditolak; kalau tidak, hasil negatif malah menambah stok.
+func (u saleUnit) baseQuantity(quantity int) (int, error) {
+	if quantity <= 0 || u.factor <= 0 {
+		return 0, errors.New("jumlah & faktor konversi harus positif")
+	}
+	if int64(quantity) > math.MaxInt32/int64(u.factor) {
+		return 0, money.ErrOverflow
+	}
+	return quantity * u.factor, nil
+}
+
+// resolveSaleUnit mengambil harga & faktor konversi. unitID 0 = satuan dasar produk.
+func resolveSaleUnit(ctx context.Context, tx *sql.Tx, productID, unitID int) (saleUnit, error) {
+	var u saleUnit
+	if unitID == 0 {
+		u.factor = 1
+		if err := tx.QueryRowContext(ctx, "SELECT base_unit FROM products WHERE id = $1", productID).Scan(&u.name); err != nil {
+			return u, err
+		}
+		// Harga yang berlaku saat checkout, termasuk jadwal harga yang belum sempat diterapkan worker
+		price, err := effectivePrice(ctx, tx, productID)
+		u.price = price
+		return u, err
+	}
+
+	query := `
+		SELECT u.name, u.conversion_factor, u.price, p.currency
+		FROM product_units u JOIN products p ON p.id = u.product_id
+		WHERE u.id = $1 AND u.product_id = $2 AND u.active`
+	err := tx.QueryRowContext(ctx, query, unitID, productID).Scan(&u.name, &u.factor, &u.price.Amount, &u.price.Currency)
+	if err == sql.ErrNoRows {
+		return u, ErrUnitNotFound
+	}
+	u.unitID = &unitID
+	return u, err
 }
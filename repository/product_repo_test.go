@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductCursor(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 10, 30, 0, 123456000, time.UTC)
+	cursor := encodeProductCursor(productCursor{Sort: "created_at", Desc: true, Value: sortKey(createdAt), ID: 7})
+
+	c, err := decodeProductCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at", c.Sort)
+	assert.True(t, c.Desc)
+	assert.Equal(t, 7, c.ID)
+	v, err := c.cursorValue()
+	assert.NoError(t, err)
+	assert.True(t, createdAt.Equal(v.(time.Time)))
+
+	c, err = decodeProductCursor(encodeProductCursor(productCursor{Sort: "price", Value: sortKey(int64(1500000)), ID: 3}))
+	assert.NoError(t, err)
+	v, err = c.cursorValue()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500000), v)
+
+	_, err = decodeProductCursor("bukan-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = decodeProductCursor(encodeProductCursor(productCursor{Sort: "password", Value: "x", ID: 1}))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestProductSortExpr(t *testing.T) {
+	q := productFilterWhere(models.ProductFilter{LocationID: 2}, SearchBackendLike)
+	expr, ok := productSortExpr("stock", q)
+	assert.True(t, ok)
+	assert.Equal(t, "sl.quantity", expr)
+
+	expr, ok = productSortExpr("id", q)
+	assert.True(t, ok)
+	assert.Equal(t, "p.id", expr)
+
+	_, ok = productSortExpr("name; DROP TABLE products", q)
+	assert.False(t, ok)
+
+	// relevance hanya ada kalau search pakai backend postgres
+	_, ok = productSortExpr("relevance", q)
+	assert.False(t, ok)
+}
+
+func TestProductFilterWhereSearch(t *testing.T) {
+	f := models.ProductFilter{Search: "kopi", CategoryID: 4}
+
+	q := productFilterWhere(f, SearchBackendLike)
+	assert.Contains(t, q.where.String(), "p.name ILIKE $1")
+	assert.Empty(t, q.rank)
+
+	q = productFilterWhere(f, SearchBackendPostgres)
+	assert.Contains(t, q.where.String(), "p.search_vector @@ plainto_tsquery('simple', $1) OR $1 <% p.name")
+	assert.Contains(t, q.rank, "word_similarity($1, p.name)")
+	assert.Equal(t, []interface{}{"kopi", 4}, q.where.args)
+
+	// Skor float8 harus balik persis lewat cursor
+	c, err := decodeProductCursor(encodeProductCursor(productCursor{Sort: "relevance", Desc: true, Value: sortKey(0.6079271018540267), ID: 9}))
+	assert.NoError(t, err)
+	v, err := c.cursorValue()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.6079271018540267, v)
+}
+
+func TestProductListCacheKey(t *testing.T) {
+	minPrice := money.New(1000000, "IDR")
+	a := models.ProductFilter{Page: 1, Limit: 10, Sort: "id", Order: "asc", Search: "Kopi", IDs: []int{3, 5}, MinPrice: &minPrice}
+	b := a
+	b.Search = "kopi"
+	assert.Equal(t, productListCacheKey(a, 1), productListCacheKey(b, 1))
+
+	b.IDs = []int{3}
+	assert.NotEqual(t, productListCacheKey(a, 1), productListCacheKey(b, 1))
+
+	// Dengan cursor, page tidak ikut menentukan hasil
+	c := models.ProductFilter{Page: 1, Limit: 10, Cursor: "abc"}
+	d := c
+	d.Page = 4
+	assert.Equal(t, productListCacheKey(c, 1), productListCacheKey(d, 1))
+
+	// Versi naik setelah produk berubah, list lama tidak terbaca lagi
+	assert.NotEqual(t, productListCacheKey(c, 1), productListCacheKey(c, 2))
+}
+
+func TestSaleUnitBaseQuantity(t *testing.T) {
+	n, err := saleUnit{factor: 12}.baseQuantity(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 120, n)
+
+	// Melewati batas kolom INT, sebelumnya bisa wrap jadi negatif
+	_, err = saleUnit{factor: 1 << 20}.baseQuantity(1 << 12)
+	assert.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = saleUnit{factor: 1}.baseQuantity(-1)
+	assert.Error(t, err)
+}
+
+func TestResolveSaleUnit(t *testing.T) {
+	ctx := context.Background()
+
+	// Satuan dasar: harga yang berlaku, stok berkurang sejumlah yang dibeli
+	tx := openFakeTx(t,
+		fakeQuery{match: "SELECT base_unit", columns: []string{"base_unit"}, rows: [][]driver.Value{{"kg"}}},
+		fakeQuery{match: "FROM product_prices", columns: []string{"price", "currency"}, rows: [][]driver.Value{{int64(1500000), "IDR"}}},
+	)
+	u, err := resolveSaleUnit(ctx, tx, 1, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, u.unitID)
+	assert.Equal(t, "kg", u.name)
+	assert.Equal(t, money.New(1500000, "IDR"), u.price)
+	base, err := u.baseQuantity(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, base)
+
+	// Bungkus isi 16: beli 2 bungkus = 32 batang keluar dari stok, harga per bungkus
+	tx = openFakeTx(t, fakeQuery{
+		match:   "FROM product_units",
+		columns: []string{"name", "conversion_factor", "price", "currency"},
+		rows:    [][]driver.Value{{"Bungkus isi 16", int64(16), int64(3200000), "IDR"}},
+	})
+	u, err = resolveSaleUnit(ctx, tx, 2, 7)
+	assert.NoError(t, err)
+	if assert.NotNil(t, u.unitID) {
+		assert.Equal(t, 7, *u.unitID)
+	}
+	base, err = u.baseQuantity(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, base)
+	subtotal, err := u.price.Mul(2)
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(6400000, "IDR"), subtotal)
+
+	// Satuan milik produk lain / sudah dihapus
+	tx = openFakeTx(t, fakeQuery{match: "FROM product_units", columns: []string{"name", "conversion_factor", "price", "currency"}})
+	_, err = resolveSaleUnit(ctx, tx, 2, 99)
+	assert.ErrorIs(t, err, ErrUnitNotFound)
+}
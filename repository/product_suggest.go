@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"phase3-api-architecture/pkg/search"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SuggestSalesWindow: rentang penjualan yang dihitung untuk bobot popularitas autocomplete
+const SuggestSalesWindow = 90 * 24 * time.Hour
+
+// Sumber hasil autocomplete
+const (
+	SuggestSourceES       = "elasticsearch"
+	SuggestSourcePostgres = "postgres"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Suggest: saran produk untuk autocomplete POS. ES (completion + fuzzy) dipakai kalau ada,
+// kalau gagal / sirkuit terbuka / tidak ada hasil (mis. dokumen belum punya field suggest)
+// fallback ke prefix + trigram di Postgres. Batas waktu dari ctx.
+func (r *ProductRepository) Suggest(ctx context.Context, q string, limit int) (models.SuggestResult, error) {
+	result := models.SuggestResult{Query: q, Items: []models.ProductSuggestion{}}
+
+	if r.ES != nil {
+		out, err := r.SuggestBreaker.Execute(func() (interface{}, error) {
+			return search.Suggest(ctx, r.ES, q, limit)
+		})
+		if err == nil && len(out.([]search.Suggestion)) > 0 {
+			for _, s := range out.([]search.Suggestion) {
+				item := models.ProductSuggestion{ID: s.ID, Name: s.Name, Price: money.New(s.PriceMinor, s.Currency)}
+				if s.SKU != "" {
+					item.SKU = &s.SKU
+				}
+				result.Items = append(result.Items, item)
+			}
+			result.Source = SuggestSourceES
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err != nil {
+			slog.Warn("suggest via elasticsearch failed, falling back to postgres", "error", err)
+		}
+	}
+
+	items, err := r.suggestPostgres(ctx, q, limit)
+	if err != nil {
+		return result, err
+	}
+	result.Items = items
+	result.Source = SuggestSourcePostgres
+	return result, nil
+}
+
+// suggestPostgres: nama yang diawali q dulu, lalu kata di tengah nama yang diawali q, lalu
+// yang mirip (typo, pakai index trigram dengan threshold default pg_trgm). Di tiap kelompok
+// produk yang paling laris di SuggestSalesWindow didahulukan; jumlah terjual dibaca dari
+// rollup sales_daily (tertinggal sampai job sales-rollup berikutnya, cukup untuk urutan).
+func (r *ProductRepository) suggestPostgres(ctx context.Context, q string, limit int) ([]models.ProductSuggestion, error) {
+	prefix := likeEscaper.Replace(q) + "%"
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.name, p.sku, p.price, p.currency
+		FROM products p
+		LEFT JOIN LATERAL (
+			SELECT SUM(d.quantity) AS sold FROM sales_daily d
+			WHERE d.product_id = p.id AND d.day >= $4::DATE
+		) s ON true
+		WHERE p.deleted_at IS NULL
+		  AND (p.name ILIKE $1 OR p.name ILIKE '% ' || $1 OR p.sku ILIKE $1 OR $2 <% p.name)
+		ORDER BY (p.name ILIKE $1) DESC, (p.name ILIKE '% ' || $1) DESC, COALESCE(s.sold, 0) DESC,
+		         word_similarity($2, p.name) DESC, p.name, p.id
+		LIMIT $3`, prefix, q, limit, time.Now().Add(-SuggestSalesWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ProductSuggestion{}
+	for rows.Next() {
+		var s models.ProductSuggestion
+		if err := rows.Scan(&s.ID, &s.Name, &s.SKU, &s.Price.Amount, &s.Price.Currency); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+// SalesCounts: unit terjual (satuan dasar) per produk sejak since, untuk bobot popularitas
+// autocomplete. Produk tanpa penjualan tidak ada di map (anggap 0).
+func (r *ProductRepository) SalesCounts(ctx context.Context, productIDs []int, since time.Time) (map[int]int, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT product_id, SUM(quantity) FROM transactions
+		WHERE product_id = ANY($1) AND created_at >= $2
+		GROUP BY product_id`, pq.Array(productIDs), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sold := map[int]int{}
+	for rows.Next() {
+		var id, n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		sold[id] = n
+	}
+	return sold, rows.Err()
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"strings"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promosi tidak ditemukan")
+	ErrInvalidPromotion  = errors.New("aturan promosi tidak valid")
+	ErrDuplicateCoupon   = errors.New("kode kupon sudah dipakai")
+	ErrCouponInvalid     = errors.New("kupon tidak valid atau tidak berlaku untuk pembelian ini")
+	ErrCouponLimit       = errors.New("kupon sudah mencapai batas pemakaian untuk akun ini")
+	ErrPromotionLimit    = errors.New("kuota promosi baru saja habis, silakan checkout ulang")
+)
+
+type PromotionRepository struct {
+	DB *sql.DB
+}
+
+const promotionColumns = `id, name, type, value, currency, buy_quantity, get_quantity, min_quantity, product_id, category_id,
+	code, starts_at, ends_at, usage_limit, per_user_limit, usage_count, active, created_at`
+
+// scanPromotion: kolom value menyimpan persen (percentage) atau nominal minor unit (fixed, mata uang di kolom currency).
+// extra dipakai untuk kolom tambahan setelah promotionColumns.
+func scanPromotion(row rowScanner, p *models.Promotion, extra ...interface{}) error {
+	var value int64
+	var currency sql.NullString
+	dest := []interface{}{&p.ID, &p.Name, &p.Type, &value, &currency, &p.BuyQuantity, &p.GetQuantity, &p.MinQuantity, &p.ProductID, &p.CategoryID,
+		&p.Code, &p.StartsAt, &p.EndsAt, &p.UsageLimit, &p.PerUserLimit, &p.UsageCount, &p.Active, &p.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	if p.Type == models.PromotionFixed {
+		amount := money.New(value, currency.String)
+		p.Amount = &amount
+	} else {
+		p.Value = int(value)
+	}
+	return nil
+}
+
+func (r *PromotionRepository) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+promotionColumns+" FROM promotions ORDER BY active DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []models.Promotion{}
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+// checkPromotionRule memastikan field yang wajib untuk tiap tipe promosi terisi
+func checkPromotionRule(p models.Promotion) error {
+	switch p.Type {
+	case models.PromotionPercentage:
+		if p.Value < 1 || p.Value > 100 {
+			return fmt.Errorf("%w: value persentase harus 1-100", ErrInvalidPromotion)
+		}
+	case models.PromotionFixed:
+		if p.Amount == nil || p.Amount.Amount < 1 {
+			return fmt.Errorf("%w: amount potongan harus lebih dari 0", ErrInvalidPromotion)
+		}
+	case models.PromotionBuyXGetY:
+		if p.BuyQuantity < 1 || p.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy_quantity dan get_quantity harus lebih dari 0", ErrInvalidPromotion)
+		}
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, p *models.Promotion) error {
+	if err := checkPromotionRule(*p); err != nil {
+		return err
+	}
+	if p.MinQuantity < 1 {
+		p.MinQuantity = 1
+	}
+	if p.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*p.Code))
+		p.Code = &code
+	}
+
+	// Nominal potongan fixed disimpan di kolom value yang sama dengan persen
+	value := int64(p.Value)
+	var currency *string
+	if p.Type == models.PromotionFixed {
+		value, currency = p.Amount.Amount, &p.Amount.Currency
+		p.Value = 0
+	} else {
+		p.Amount = nil
+	}
+
+	query := `
+		INSERT INTO promotions (name, type, value, currency, buy_quantity, get_quantity, min_quantity, product_id, category_id,
+		                        code, starts_at, ends_at, usage_limit, per_user_limit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING usage_count, active, created_at, id`
+	err := r.DB.QueryRowContext(ctx, query, p.Name, p.Type, value, currency, p.BuyQuantity, p.GetQuantity, p.MinQuantity, p.ProductID, p.CategoryID,
+		p.Code, p.StartsAt, p.EndsAt, p.UsageLimit, p.PerUserLimit).
+		Scan(&p.UsageCount, &p.Active, &p.CreatedAt, &p.ID)
+	switch {
+	case isUniqueViolation(err, "uq_promotions_code"):
+		return ErrDuplicateCoupon
+	case isForeignKeyViolation(err, "fk_promotion_product"):
+		return ErrProductNotFound
+	case isForeignKeyViolation(err, "fk_promotion_category"):
+		return ErrCategoryNotFound
+	}
+	return err
+}
+
+// Deactivate menghentikan promosi, histori diskon di transaksi tetap tersimpan
+func (r *PromotionRepository) Deactivate(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "UPDATE promotions SET active = FALSE WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+// promotionDiscount menghitung potongan satu promosi untuk satu baris.
+// base = sisa harga yang masih harus dibayar (setelah promosi sebelumnya), potongan tidak melebihi base.
+// Hasilnya tidak bisa overflow: persen <= base dan item gratis <= quantity (subtotal sudah dicek pemanggil).
+// Potongan fixed hanya berlaku jika mata uangnya sama dengan harga produk.
+func promotionDiscount(p models.Promotion, unitPrice money.Money, quantity int, base money.Money) money.Money {
+	zero := money.New(0, base.Currency)
+	if quantity < max(p.MinQuantity, 1) {
+		return zero
+	}
+
+	amount := zero
+	switch p.Type {
+	case models.PromotionPercentage:
+		amount, _ = base.MulDiv(int64(p.Value), 100)
+	case models.PromotionFixed:
+		if p.Amount != nil && p.Amount.Currency == base.Currency {
+			amount = *p.Amount
+		}
+	case models.PromotionBuyXGetY:
+		if set := p.BuyQuantity + p.GetQuantity; p.BuyQuantity > 0 && p.GetQuantity > 0 {
+			amount, _ = unitPrice.Mul(int64(quantity / set * p.GetQuantity))
+		}
+	}
+	return amount.Min(base)
+}
+
+// applyPromotions mengevaluasi promosi untuk satu baris checkout (di dalam transaksi checkout).
+// Aturannya: promosi otomatis tidak bisa digabung (dipilih potongan terbesar),
+// lalu kupon (jika ada) diterapkan ke sisa harganya.
+// Promosi dibaca tanpa lock; batasnya dicek ulang di recordDiscounts hanya untuk promosi yang dipakai.
+func applyPromotions(ctx context.Context, tx *sql.Tx, userID, productID int, unitPrice, subtotal money.Money, quantity int, couponCode string) ([]models.AppliedDiscount, error) {
+	couponCode = strings.ToUpper(strings.TrimSpace(couponCode))
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT c.id, c.parent_id FROM categories c JOIN products p ON p.category_id = c.id WHERE p.id = $1
+			UNION ALL
+			SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT ` + promotionColumns + `,
+		       (SELECT COUNT(*) FROM transaction_discounts td WHERE td.promotion_id = promotions.id AND td.user_id = $2)
+		FROM promotions
+		WHERE active
+		  AND (starts_at IS NULL OR starts_at <= NOW()) AND (ends_at IS NULL OR ends_at > NOW())
+		  AND (usage_limit IS NULL OR usage_count < usage_limit)
+		  AND (product_id IS NULL OR product_id = $1)
+		  AND (category_id IS NULL OR category_id IN (SELECT id FROM ancestors))
+		  AND (code IS NULL OR code = $3)
+		ORDER BY id`
+	rows, err := tx.QueryContext(ctx, query, productID, userID, couponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var auto []models.Promotion
+	var coupon *models.Promotion
+	couponUsed := false
+	for rows.Next() {
+		var p models.Promotion
+		var usedByUser int
+		if err := scanPromotion(rows, &p, &usedByUser); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		limited := p.PerUserLimit != nil && usedByUser >= *p.PerUserLimit
+
+		if p.Code != nil {
+			coupon = &p
+			couponUsed = limited
+			continue
+		}
+		if !limited {
+			auto = append(auto, p)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	discounts := []models.AppliedDiscount{}
+
+	// Promosi otomatis terbaik (seri = yang dibuat duluan)
+	best := models.AppliedDiscount{Amount: money.New(0, subtotal.Currency)}
+	for _, p := range auto {
+		if amount := promotionDiscount(p, unitPrice, quantity, subtotal); amount.Amount > best.Amount.Amount {
+			best = models.AppliedDiscount{PromotionID: p.ID, Name: p.Name, Amount: amount}
+		}
+	}
+	if !best.Amount.IsZero() {
+		discounts = append(discounts, best)
+	}
+
+	if couponCode != "" {
+		if coupon == nil {
+			return nil, ErrCouponInvalid
+		}
+		if couponUsed {
+			return nil, ErrCouponLimit
+		}
+		remaining, err := subtotal.Sub(best.Amount)
+		if err != nil {
+			return nil, err
+		}
+		amount := promotionDiscount(*coupon, unitPrice, quantity, remaining)
+		if amount.IsZero() {
+			return nil, ErrCouponInvalid
+		}
+		discounts = append(discounts, models.AppliedDiscount{PromotionID: coupon.ID, Name: coupon.Name, Code: *coupon.Code, Amount: amount})
+	}
+
+	return discounts, nil
+}
+
+// recordDiscounts menaikkan usage_count promosi yang dipakai lalu mencatat diskonnya di baris transaksi.
+// UPDATE bersyarat sekaligus mengunci baris promosi itu saja, jadi checkout paralel tidak bisa
+// melewati usage_limit. per_user_limit dihitung ulang setelah lock didapat (READ COMMITTED:
+// statement berikutnya sudah melihat pemakaian dari transaksi yang tadi ditunggu).
+func recordDiscounts(ctx context.Context, tx *sql.Tx, transactionID, userID int, discounts []models.AppliedDiscount) error {
+	for _, d := range discounts {
+		var perUserLimit sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			UPDATE promotions SET usage_count = usage_count + 1
+			WHERE id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)
+			RETURNING per_user_limit`, d.PromotionID).Scan(&perUserLimit)
+		if err == sql.ErrNoRows {
+			if d.Code != "" {
+				return ErrCouponInvalid
+			}
+			return ErrPromotionLimit
+		}
+		if err != nil {
+			return err
+		}
+
+		if perUserLimit.Valid {
+			var used int64
+			err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM transaction_discounts WHERE promotion_id = $1 AND user_id = $2",
+				d.PromotionID, userID).Scan(&used)
+			if err != nil {
+				return err
+			}
+			if used >= perUserLimit.Int64 {
+				if d.Code != "" {
+					return ErrCouponLimit
+				}
+				return ErrPromotionLimit
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO transaction_discounts (transaction_id, promotion_id, user_id, amount) VALUES ($1, $2, $3, $4)",
+			transactionID, d.PromotionID, userID, d.Amount.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
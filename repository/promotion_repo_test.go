@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount*100, "IDR")
+}
+
+func TestPromotionDiscount(t *testing.T) {
+	// 10% dari sisa harga
+	p := models.Promotion{Type: models.PromotionPercentage, Value: 10}
+	assert.Equal(t, idr(3000), promotionDiscount(p, idr(10000), 3, idr(30000)))
+	assert.Equal(t, idr(2500), promotionDiscount(p, idr(10000), 3, idr(25000)))
+
+	// Potongan tetap tidak boleh melebihi sisa harga
+	fixed := idr(5000)
+	p = models.Promotion{Type: models.PromotionFixed, Amount: &fixed}
+	assert.Equal(t, idr(5000), promotionDiscount(p, idr(10000), 1, idr(10000)))
+	assert.Equal(t, idr(3000), promotionDiscount(p, idr(3000), 1, idr(3000)))
+
+	// Potongan fixed beda mata uang tidak berlaku
+	usd := money.New(1000, "USD")
+	assert.Equal(t, money.New(0, "USD"), promotionDiscount(p, usd, 1, usd))
+
+	// Beli 2 gratis 1: 7 item = 2 set lengkap -> 2 gratis
+	p = models.Promotion{Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1}
+	assert.Equal(t, idr(4000), promotionDiscount(p, idr(2000), 7, idr(14000)))
+	assert.Equal(t, idr(0), promotionDiscount(p, idr(2000), 2, idr(4000)))
+
+	// Minimal pembelian belum terpenuhi
+	p = models.Promotion{Type: models.PromotionPercentage, Value: 50, MinQuantity: 5}
+	assert.Equal(t, idr(0), promotionDiscount(p, idr(1000), 4, idr(4000)))
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrSupplierNotFound      = errors.New("supplier tidak ditemukan")
+	ErrPurchaseOrderNotFound = errors.New("purchase order tidak ditemukan")
+	ErrPurchaseOrderStatus   = errors.New("status purchase order tidak mengizinkan aksi ini")
+	ErrOverReceipt           = errors.New("jumlah diterima melebihi jumlah yang dipesan")
+	ErrItemNotInOrder        = errors.New("produk tidak ada di purchase order")
+)
+
+type PurchaseRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client
+}
+
+func (r *PurchaseRepository) GetAllSuppliers(ctx context.Context) ([]models.Supplier, error) {
+	query := "SELECT id, name, COALESCE(phone, ''), COALESCE(email, ''), COALESCE(address, ''), created_at FROM suppliers ORDER BY name"
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := []models.Supplier{}
+	for rows.Next() {
+		var s models.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *PurchaseRepository) CreateSupplier(ctx context.Context, s *models.Supplier) error {
+	query := "INSERT INTO suppliers (name, phone, email, address) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	return r.DB.QueryRowContext(ctx, query, s.Name, s.Phone, s.Email, s.Address).Scan(&s.ID, &s.CreatedAt)
+}
+
+// CreateOrder membuat PO berstatus draft. Stok belum berubah sampai barang diterima.
+func (r *PurchaseRepository) CreateOrder(ctx context.Context, userID int, req models.PurchaseOrderRequest) (models.PurchaseOrder, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	defer tx.Rollback()
+
+	po := models.PurchaseOrder{
+		SupplierID: req.SupplierID,
+		LocationID: req.LocationID,
+		Status:     models.PurchaseDraft,
+		Notes:      req.Notes,
+		CreatedBy:  userID,
+	}
+	if po.LocationID == 0 {
+		if po.LocationID, err = defaultLocationID(ctx, tx); err != nil {
+			return models.PurchaseOrder{}, err
+		}
+	}
+
+	query := `
+		INSERT INTO purchase_orders (supplier_id, location_id, status, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`
+	err = tx.QueryRowContext(ctx, query, po.SupplierID, po.LocationID, po.Status, po.Notes, userID).
+		Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err, "fk_po_supplier") {
+			return models.PurchaseOrder{}, ErrSupplierNotFound
+		}
+		if isForeignKeyViolation(err, "fk_po_location") {
+			return models.PurchaseOrder{}, ErrLocationNotFound
+		}
+		return models.PurchaseOrder{}, err
+	}
+
+	for _, item := range req.Items {
+		if item.UnitCost.Currency == "" {
+			item.UnitCost.Currency = money.DefaultCurrency // unit_cost tidak diisi
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity_ordered, unit_cost, currency)
+			VALUES ($1, $2, $3, $4, $5)`, po.ID, item.ProductID, item.QuantityOrdered, item.UnitCost.Amount, item.UnitCost.Currency)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return models.PurchaseOrder{}, ErrProductNotFound
+			}
+			return models.PurchaseOrder{}, err
+		}
+		item.QuantityReceived = 0
+		po.Items = append(po.Items, item)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return po, nil
+}
+
+func (r *PurchaseRepository) GetAllOrders(ctx context.Context, status string) ([]models.PurchaseOrder, error) {
+	query := `
+		SELECT id, supplier_id, location_id, status, COALESCE(notes, ''), COALESCE(created_by, 0), created_at, updated_at
+		FROM purchase_orders`
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = $1"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.PurchaseOrder{}
+	for rows.Next() {
+		var po models.PurchaseOrder
+		if err := rows.Scan(&po.ID, &po.SupplierID, &po.LocationID, &po.Status, &po.Notes, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	return orders, rows.Err()
+}
+
+func (r *PurchaseRepository) GetOrder(ctx context.Context, id int) (models.PurchaseOrder, error) {
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	defer tx.Rollback()
+
+	return getPurchaseOrder(ctx, tx, id, false)
+}
+
+// MarkOrdered mengubah PO dari draft menjadi ordered (sudah dikirim ke supplier)
+func (r *PurchaseRepository) MarkOrdered(ctx context.Context, id int) error {
+	query := "UPDATE purchase_orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3"
+	res, err := r.DB.ExecContext(ctx, query, models.PurchaseOrdered, id, models.PurchaseDraft)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Bedakan PO tidak ada vs status salah
+		var exists bool
+		if err := r.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM purchase_orders WHERE id = $1)", id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrPurchaseOrderNotFound
+		}
+		return ErrPurchaseOrderStatus
+	}
+	return nil
+}
+
+// Receive mencatat penerimaan barang: stok lokasi PO bertambah (additive, bukan overwrite),
+// quantity_received di PO ikut naik dan cost_price produk diperbarui.
+func (r *PurchaseRepository) Receive(ctx context.Context, userID, orderID int, req models.GoodsReceiptRequest) (models.GoodsReceipt, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.GoodsReceipt{}, err
+	}
+	defer tx.Rollback()
+
+	po, err := getPurchaseOrder(ctx, tx, orderID, true)
+	if err != nil {
+		return models.GoodsReceipt{}, err
+	}
+	if po.Status != models.PurchaseOrdered && po.Status != models.PurchasePartiallyReceived {
+		return models.GoodsReceipt{}, ErrPurchaseOrderStatus
+	}
+
+	ordered := make(map[int]models.PurchaseOrderItem, len(po.Items))
+	for _, item := range po.Items {
+		ordered[item.ProductID] = item
+	}
+
+	receipt := models.GoodsReceipt{
+		PurchaseOrderID: orderID,
+		LocationID:      po.LocationID,
+		ReceivedBy:      userID,
+	}
+	query := "INSERT INTO goods_receipts (purchase_order_id, location_id, received_by) VALUES ($1, $2, $3) RETURNING id, received_at"
+	if err := tx.QueryRowContext(ctx, query, orderID, po.LocationID, userID).Scan(&receipt.ID, &receipt.ReceivedAt); err != nil {
+		return models.GoodsReceipt{}, err
+	}
+
+	items := append([]models.GoodsReceiptItem(nil), req.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	for _, item := range items {
+		line, ok := ordered[item.ProductID]
+		if !ok {
+			return models.GoodsReceipt{}, ErrItemNotInOrder
+		}
+		if line.QuantityReceived+item.Quantity > line.QuantityOrdered {
+			return models.GoodsReceipt{}, ErrOverReceipt
+		}
+
+		unitCost := line.UnitCost
+		if item.UnitCost != nil {
+			if item.UnitCost.Currency != unitCost.Currency {
+				return models.GoodsReceipt{}, money.ErrCurrencyMismatch
+			}
+			unitCost = *item.UnitCost
+		}
+		item.UnitCost = &unitCost
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO goods_receipt_items (receipt_id, product_id, quantity, unit_cost, currency) VALUES ($1, $2, $3, $4, $5)",
+			receipt.ID, item.ProductID, item.Quantity, unitCost.Amount, unitCost.Currency)
+		if err != nil {
+			return models.GoodsReceipt{}, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_order_items SET quantity_received = quantity_received + $1
+			WHERE purchase_order_id = $2 AND product_id = $3`, item.Quantity, orderID, item.ProductID)
+		if err != nil {
+			return models.GoodsReceipt{}, err
+		}
+
+		if err := adjustLocationStock(ctx, tx, po.LocationID, item.ProductID, item.Quantity); err != nil {
+			return models.GoodsReceipt{}, err
+		}
+		if item.LotID, err = addLot(ctx, tx, item.ProductID, po.LocationID, receipt.ID, item.Quantity, item.LotNumber, item.ExpiryDate); err != nil {
+			return models.GoodsReceipt{}, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET cost_price = $1, cost_currency = $2 WHERE id = $3",
+			unitCost.Amount, unitCost.Currency, item.ProductID); err != nil {
+			return models.GoodsReceipt{}, err
+		}
+
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	// PO selesai kalau semua baris sudah diterima penuh
+	status := models.PurchaseReceived
+	var pending bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM purchase_order_items WHERE purchase_order_id = $1 AND quantity_received < quantity_ordered)", orderID).Scan(&pending)
+	if err != nil {
+		return models.GoodsReceipt{}, err
+	}
+	if pending {
+		status = models.PurchasePartiallyReceived
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE purchase_orders SET status = $1, updated_at = NOW() WHERE id = $2", status, orderID); err != nil {
+		return models.GoodsReceipt{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.GoodsReceipt{}, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	invalidateProductCache(ctx, r.Redis, ids...)
+	return receipt, nil
+}
+
+// Adjust mencatat koreksi stok manual sebagai selisih (delta) di satu lokasi
+func (r *PurchaseRepository) Adjust(ctx context.Context, userID, productID int, req models.StockAdjustmentRequest) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	locationID := req.LocationID
+	if locationID == 0 {
+		if locationID, err = defaultLocationID(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if req.Delta < 0 {
+		// Pengurangan tidak boleh memakan stok yang sedang di-hold
+		available, err := lockAvailableStock(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+		if available < -req.Delta {
+			return ErrInsufficientStock
+		}
+	} else if req.LotID != 0 {
+		return ErrLotPositive
+	}
+
+	var adjustmentID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO stock_adjustments (product_id, location_id, delta, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`, productID, locationID, req.Delta, req.Reason, userID).Scan(&adjustmentID)
+	if err != nil {
+		return err
+	}
+
+	if req.Delta < 0 {
+		// Koreksi boleh membuang lot kedaluwarsa (misal barang rusak / dimusnahkan)
+		consume := lotConsumption{refColumn: "adjustment_id", refID: adjustmentID, allowExpired: true, lotID: req.LotID}
+		if err := consumeLots(ctx, tx, locationID, productID, -req.Delta, consume); err != nil {
+			return err
+		}
+	}
+
+	if err := adjustLocationStock(ctx, tx, locationID, productID, req.Delta); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	invalidateProductCache(ctx, r.Redis, productID)
+	return nil
+}
+
+func getPurchaseOrder(ctx context.Context, tx *sql.Tx, id int, forUpdate bool) (models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	query := `
+		SELECT id, supplier_id, location_id, status, COALESCE(notes, ''), COALESCE(created_by, 0), created_at, updated_at
+		FROM purchase_orders WHERE id = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	err := tx.QueryRowContext(ctx, query, id).
+		Scan(&po.ID, &po.SupplierID, &po.LocationID, &po.Status, &po.Notes, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return po, ErrPurchaseOrderNotFound
+		}
+		return po, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT product_id, quantity_ordered, quantity_received, unit_cost, currency
+		FROM purchase_order_items WHERE purchase_order_id = $1 ORDER BY product_id`, id)
+	if err != nil {
+		return po, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		if err := rows.Scan(&item.ProductID, &item.QuantityOrdered, &item.QuantityReceived, &item.UnitCost.Amount, &item.UnitCost.Currency); err != nil {
+			return po, err
+		}
+		po.Items = append(po.Items, item)
+	}
+	return po, rows.Err()
+}
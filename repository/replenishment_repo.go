@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"sort"
+)
+
+// ReplenishmentRepository dipakai job worker untuk memantau stok menipis & menyiapkan restock
+type ReplenishmentRepository struct {
+	DB *sql.DB
+}
+
+// lowStockCond: produk aktif yang dipantau (reorder_point > 0) dan stok tersedia sudah <= reorder_point
+const lowStockCond = "p.deleted_at IS NULL AND p.reorder_point > 0 AND p.stock - " + activeHoldsExpr + " <= p.reorder_point"
+
+// MarkLowStock menandai produk yang baru saja turun di bawah reorder point dan mengembalikannya.
+// Produk yang sudah ditandai tidak dikembalikan lagi sampai stoknya pulih, jadi alert tidak berulang.
+func (r *ReplenishmentRepository) MarkLowStock(ctx context.Context) ([]models.LowStockItem, error) {
+	// Reset penanda untuk produk yang stoknya sudah pulih
+	queryReset := "UPDATE products p SET low_stock_since = NULL WHERE p.low_stock_since IS NOT NULL AND NOT (" + lowStockCond + ")"
+	if _, err := r.DB.ExecContext(ctx, queryReset); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE products p SET low_stock_since = NOW()
+		WHERE p.low_stock_since IS NULL AND ` + lowStockCond + `
+		RETURNING p.id, p.name, p.stock - ` + activeHoldsExpr + `, p.reorder_point, p.reorder_quantity, p.supplier_id`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.LowStockItem{}
+	for rows.Next() {
+		var it models.LowStockItem
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.Stock, &it.ReorderPoint, &it.ReorderQuantity, &it.SupplierID); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// GetLowStockItems mengembalikan semua produk yang sedang di bawah reorder point
+// beserta jumlah terjual selama lookbackDays terakhir (untuk digest & saran restock)
+func (r *ReplenishmentRepository) GetLowStockItems(ctx context.Context, lookbackDays int) ([]models.LowStockItem, error) {
+	query := `
+		SELECT p.id, p.name, p.stock - ` + activeHoldsExpr + `, p.reorder_point, p.reorder_quantity, p.supplier_id,
+		       COALESCE((
+		           SELECT SUM(t.quantity) FROM transactions t
+		           WHERE t.product_id = p.id AND t.created_at >= NOW() - make_interval(days => $1)
+		       ), 0)
+		FROM products p
+		WHERE ` + lowStockCond + `
+		ORDER BY p.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, lookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.LowStockItem{}
+	for rows.Next() {
+		var it models.LowStockItem
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.Stock, &it.ReorderPoint, &it.ReorderQuantity, &it.SupplierID, &it.SoldLastPeriod); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// DraftReorders membuat satu draft PO per supplier untuk produk low stock.
+// Produk tanpa supplier utama atau yang masih punya PO terbuka dilewati.
+func (r *ReplenishmentRepository) DraftReorders(ctx context.Context, items []models.LowStockItem, lookbackDays, coverDays int) ([]models.PurchaseOrder, error) {
+	bySupplier := map[int][]models.LowStockItem{}
+	for _, it := range items {
+		if it.SupplierID == nil {
+			continue
+		}
+		bySupplier[*it.SupplierID] = append(bySupplier[*it.SupplierID], it)
+	}
+
+	supplierIDs := make([]int, 0, len(bySupplier))
+	for id := range bySupplier {
+		supplierIDs = append(supplierIDs, id)
+	}
+	sort.Ints(supplierIDs)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	locationID, err := defaultLocationID(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []models.PurchaseOrder{}
+	for _, supplierID := range supplierIDs {
+		var lines []models.PurchaseOrderItem
+		for _, it := range bySupplier[supplierID] {
+			open, err := hasOpenPurchaseLine(ctx, tx, it.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			if open {
+				continue
+			}
+
+			var unitCost money.Money
+			if err := tx.QueryRowContext(ctx, "SELECT cost_price, cost_currency FROM products WHERE id = $1", it.ProductID).
+				Scan(&unitCost.Amount, &unitCost.Currency); err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, models.PurchaseOrderItem{
+				ProductID:       it.ProductID,
+				QuantityOrdered: suggestReorderQuantity(it, lookbackDays, coverDays),
+				UnitCost:        unitCost,
+			})
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		po := models.PurchaseOrder{
+			SupplierID: supplierID,
+			LocationID: locationID,
+			Status:     models.PurchaseDraft,
+			Notes:      fmt.Sprintf("Draft otomatis dari low-stock job (penjualan %d hari terakhir)", lookbackDays),
+			Items:      lines,
+		}
+		query := `
+			INSERT INTO purchase_orders (supplier_id, location_id, status, notes)
+			VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
+		if err := tx.QueryRowContext(ctx, query, supplierID, locationID, po.Status, po.Notes).Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		for _, line := range lines {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity_ordered, unit_cost, currency)
+				VALUES ($1, $2, $3, $4, $5)`, po.ID, line.ProductID, line.QuantityOrdered, line.UnitCost.Amount, line.UnitCost.Currency)
+			if err != nil {
+				return nil, err
+			}
+		}
+		orders = append(orders, po)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// hasOpenPurchaseLine: produk masih ada di PO yang belum selesai, jangan dipesan dobel
+func hasOpenPurchaseLine(ctx context.Context, tx *sql.Tx, productID int) (bool, error) {
+	var open bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM purchase_order_items poi
+			JOIN purchase_orders po ON po.id = poi.purchase_order_id
+			WHERE poi.product_id = $1 AND po.status IN ('draft', 'ordered', 'partially_received')
+		)`
+	err := tx.QueryRowContext(ctx, query, productID).Scan(&open)
+	return open, err
+}
+
+// suggestReorderQuantity menghitung jumlah pesan ulang:
+// target = reorder point + perkiraan penjualan selama coverDays (dari rata-rata harian lookback),
+// lalu dikurangi stok yang ada. Minimal reorder_quantity, dan minimal 1.
+func suggestReorderQuantity(it models.LowStockItem, lookbackDays, coverDays int) int {
+	var expected int
+	if lookbackDays > 0 {
+		perDay := float64(it.SoldLastPeriod) / float64(lookbackDays)
+		expected = int(math.Ceil(perDay * float64(coverDays)))
+	}
+
+	qty := it.ReorderPoint + expected - it.Stock
+	if qty < it.ReorderQuantity {
+		qty = it.ReorderQuantity
+	}
+	if qty < 1 {
+		qty = 1
+	}
+	return qty
+}
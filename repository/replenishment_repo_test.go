@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"phase3-api-architecture/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestReorderQuantity(t *testing.T) {
+	// Terjual 60 dalam 30 hari = 2/hari, cover 14 hari = 28
+	// target = reorder point 10 + 28 = 38, stok 5 -> pesan 33
+	item := models.LowStockItem{Stock: 5, ReorderPoint: 10, ReorderQuantity: 20, SoldLastPeriod: 60}
+	assert.Equal(t, 33, suggestReorderQuantity(item, 30, 14))
+
+	// Tidak ada penjualan -> pakai reorder_quantity
+	item = models.LowStockItem{Stock: 8, ReorderPoint: 10, ReorderQuantity: 24}
+	assert.Equal(t, 24, suggestReorderQuantity(item, 30, 14))
+
+	// reorder_quantity belum diatur & stok sudah pas di threshold -> minimal 1
+	item = models.LowStockItem{Stock: 10, ReorderPoint: 10}
+	assert.Equal(t, 1, suggestReorderQuantity(item, 30, 14))
+}
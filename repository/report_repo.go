@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+	"time"
+)
+
+var (
+	ErrInvalidPeriod    = errors.New("period harus daily, weekly atau monthly")
+	ErrSnapshotNotFound = errors.New("snapshot stok untuk tanggal tersebut belum ada")
+)
+
+// ReportRepository: laporan penjualan & persediaan. Agregat berat dibaca dari tabel rollup
+// (sales_daily, stock_snapshots) yang diisi job worker, bukan scan transactions setiap request.
+type ReportRepository struct {
+	DB *sql.DB
+}
+
+// reportPeriods: nama period di API -> field date_trunc Postgres
+var reportPeriods = map[string]string{
+	models.ReportDaily:   "day",
+	models.ReportWeekly:  "week",
+	models.ReportMonthly: "month",
+}
+
+// RefreshSalesRollup menghitung ulang sales_daily untuk `days` hari terakhir (termasuk hari ini).
+// Kalau hari terakhir di rollup lebih lama dari itu (worker sempat mati), mulai dari hari
+// tersebut supaya tidak ada hari yang bolong. Jika rollup masih kosong, semua transaksi
+// di-backfill. Transaksi tidak pernah diubah, jadi hari yang sudah lewat cukup dihitung ulang
+// sebentar untuk menangkap commit yang telat.
+func (r *ReportRepository) RefreshSalesRollup(ctx context.Context, days int) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var since sql.NullTime
+	query := `
+		SELECT CASE WHEN EXISTS (SELECT 1 FROM sales_daily)
+		            THEN LEAST((SELECT MAX(day) FROM sales_daily), CURRENT_DATE - $1::INT)
+		            ELSE (SELECT MIN(created_at)::DATE FROM transactions) END`
+	if err := tx.QueryRowContext(ctx, query, days).Scan(&since); err != nil {
+		return 0, err
+	}
+	if !since.Valid {
+		return 0, nil // belum ada transaksi sama sekali
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sales_daily WHERE day >= $1", since.Time); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO sales_daily (day, product_id, currency, transactions, quantity, gross_amount, discount_amount, tax_amount,
+		                         total_amount, revenue_amount, costed_quantity, costed_revenue, cost_amount)
+		SELECT created_at::DATE, product_id, currency, COUNT(*), SUM(quantity),
+		       SUM(COALESCE(subtotal, total_price)), SUM(discount_amount), SUM(tax_amount),
+		       SUM(total_price), SUM(total_price - tax_amount),
+		       COALESCE(SUM(quantity) FILTER (WHERE cost_amount IS NOT NULL), 0),
+		       COALESCE(SUM(total_price - tax_amount) FILTER (WHERE cost_amount IS NOT NULL), 0),
+		       COALESCE(SUM(cost_amount), 0)
+		FROM transactions
+		WHERE created_at >= $1
+		GROUP BY 1, 2, 3`, since.Time)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), tx.Commit()
+}
+
+// SnapshotStock menyimpan stok fisik & harga pokok hari ini. Dipanggil berkala, jadi snapshot
+// sebuah tanggal berisi kondisi terakhir hari itu. Produk di trash yang masih ada stoknya tetap dihitung.
+func (r *ReportRepository) SnapshotStock(ctx context.Context) (int, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		INSERT INTO stock_snapshots (day, product_id, quantity, unit_cost, currency)
+		SELECT CURRENT_DATE, id, stock, cost_price, cost_currency
+		FROM products WHERE deleted_at IS NULL OR stock <> 0
+		ON CONFLICT (day, product_id) DO UPDATE
+		SET quantity = EXCLUDED.quantity, unit_cost = EXCLUDED.unit_cost, currency = EXCLUDED.currency, taken_at = NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Sales mengembalikan total penjualan per hari / minggu / bulan
+func (r *ReportRepository) Sales(ctx context.Context, period string, rng models.ReportRange) ([]models.SalesPeriod, error) {
+	field, ok := reportPeriods[period]
+	if !ok {
+		return nil, ErrInvalidPeriod
+	}
+
+	query := `
+		SELECT date_trunc($1, day)::DATE, SUM(transactions)::INT, SUM(quantity)::INT,
+		       SUM(gross_amount)::BIGINT, SUM(discount_amount)::BIGINT, SUM(tax_amount)::BIGINT,
+		       SUM(total_amount)::BIGINT, SUM(revenue_amount)::BIGINT
+		FROM sales_daily
+		WHERE day BETWEEN $2 AND $3 AND currency = $4
+		GROUP BY 1 ORDER BY 1`
+	rows, err := r.DB.QueryContext(ctx, query, field, rng.From, rng.To, rng.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.SalesPeriod{}
+	for rows.Next() {
+		var p models.SalesPeriod
+		var gross, discount, tax, total, revenue int64
+		if err := rows.Scan(&p.PeriodStart, &p.Transactions, &p.Quantity, &gross, &discount, &tax, &total, &revenue); err != nil {
+			return nil, err
+		}
+		p.Gross = money.New(gross, rng.Currency)
+		p.Discount = money.New(discount, rng.Currency)
+		p.Tax = money.New(tax, rng.Currency)
+		p.Total = money.New(total, rng.Currency)
+		p.Revenue = money.New(revenue, rng.Currency)
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// TopProducts mengembalikan produk terlaris, diurutkan berdasarkan quantity atau revenue (byRevenue)
+func (r *ReportRepository) TopProducts(ctx context.Context, rng models.ReportRange, byRevenue bool, limit int) ([]models.ProductSales, error) {
+	order := "3 DESC, 4 DESC"
+	if byRevenue {
+		order = "4 DESC, 3 DESC"
+	}
+
+	query := `
+		SELECT s.product_id, p.name, SUM(s.quantity)::INT, SUM(s.revenue_amount)::BIGINT, SUM(s.transactions)::INT
+		FROM sales_daily s JOIN products p ON p.id = s.product_id
+		WHERE s.day BETWEEN $1 AND $2 AND s.currency = $3
+		GROUP BY s.product_id, p.name
+		ORDER BY ` + order + `, s.product_id
+		LIMIT $4`
+	rows, err := r.DB.QueryContext(ctx, query, rng.From, rng.To, rng.Currency, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.ProductSales{}
+	for rows.Next() {
+		var ps models.ProductSales
+		var revenue int64
+		if err := rows.Scan(&ps.ProductID, &ps.Name, &ps.Quantity, &revenue, &ps.Transactions); err != nil {
+			return nil, err
+		}
+		ps.Revenue = money.New(revenue, rng.Currency)
+		products = append(products, ps)
+	}
+	return products, rows.Err()
+}
+
+// SlowMovers mengembalikan produk aktif yang masih ada stoknya dengan penjualan paling sedikit
+// di rentang laporan (semua mata uang), stok terbesar dulu jika sama.
+func (r *ReportRepository) SlowMovers(ctx context.Context, rng models.ReportRange, limit int) ([]models.SlowMover, error) {
+	query := `
+		SELECT p.id, p.name, p.stock,
+		       COALESCE((SELECT SUM(s.quantity) FROM sales_daily s
+		                 WHERE s.product_id = p.id AND s.day BETWEEN $1 AND $2), 0)::INT AS sold,
+		       (SELECT MAX(s.day) FROM sales_daily s WHERE s.product_id = p.id)
+		FROM products p
+		WHERE p.deleted_at IS NULL AND p.stock > 0
+		ORDER BY sold, p.stock DESC, p.id
+		LIMIT $3`
+	rows, err := r.DB.QueryContext(ctx, query, rng.From, rng.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.SlowMover{}
+	for rows.Next() {
+		var m models.SlowMover
+		if err := rows.Scan(&m.ProductID, &m.Name, &m.Stock, &m.SoldQuantity, &m.LastSoldOn); err != nil {
+			return nil, err
+		}
+		items = append(items, m)
+	}
+	return items, rows.Err()
+}
+
+// GrossMargin menghitung margin kotor per periode dari transaksi yang HPP-nya diketahui
+func (r *ReportRepository) GrossMargin(ctx context.Context, period string, rng models.ReportRange) ([]models.MarginPeriod, error) {
+	field, ok := reportPeriods[period]
+	if !ok {
+		return nil, ErrInvalidPeriod
+	}
+
+	query := `
+		SELECT date_trunc($1, day)::DATE, SUM(revenue_amount)::BIGINT, SUM(costed_revenue)::BIGINT, SUM(cost_amount)::BIGINT,
+		       SUM(quantity)::BIGINT, SUM(costed_quantity)::BIGINT
+		FROM sales_daily
+		WHERE day BETWEEN $2 AND $3 AND currency = $4
+		GROUP BY 1 ORDER BY 1`
+	rows, err := r.DB.QueryContext(ctx, query, field, rng.From, rng.To, rng.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.MarginPeriod{}
+	for rows.Next() {
+		var p models.MarginPeriod
+		var revenue, costedRevenue, cost, quantity, costedQuantity int64
+		if err := rows.Scan(&p.PeriodStart, &revenue, &costedRevenue, &cost, &quantity, &costedQuantity); err != nil {
+			return nil, err
+		}
+		p.Revenue = money.New(revenue, rng.Currency)
+		p.CostedRevenue = money.New(costedRevenue, rng.Currency)
+		p.Cost = money.New(cost, rng.Currency)
+		p.GrossMargin = money.New(costedRevenue-cost, rng.Currency)
+		if costedRevenue > 0 {
+			bp, _ := p.GrossMargin.MulDiv(10000, costedRevenue)
+			p.MarginBP = int(bp.Amount)
+		}
+		if quantity > 0 {
+			p.CostCoverageBP = int(costedQuantity * 10000 / quantity)
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// InventoryValuation menghitung nilai persediaan pada tanggal date (nil = hari ini, dihitung langsung).
+// Tanggal lampau memakai snapshot harian. Hanya produk yang harga pokoknya dalam mata uang currency.
+func (r *ReportRepository) InventoryValuation(ctx context.Context, date *time.Time, currency string) (models.InventoryValuation, error) {
+	v := models.InventoryValuation{Currency: currency, Items: []models.InventoryValuationItem{}}
+
+	var live bool
+	if err := r.DB.QueryRowContext(ctx, "SELECT $1::DATE IS NULL OR $1::DATE >= CURRENT_DATE, COALESCE($1::DATE, CURRENT_DATE)", date).
+		Scan(&live, &v.Date); err != nil {
+		return v, err
+	}
+	v.Live = live
+
+	var rows *sql.Rows
+	var err error
+	if live {
+		rows, err = r.DB.QueryContext(ctx, `
+			SELECT p.id, p.name, p.stock, p.cost_price
+			FROM products p WHERE p.stock <> 0 AND p.cost_currency = $1
+			ORDER BY p.id`, currency)
+	} else {
+		var exists bool
+		if err := r.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM stock_snapshots WHERE day = $1)", v.Date).Scan(&exists); err != nil {
+			return v, err
+		}
+		if !exists {
+			return v, ErrSnapshotNotFound
+		}
+		rows, err = r.DB.QueryContext(ctx, `
+			SELECT s.product_id, COALESCE(p.name, ''), s.quantity, s.unit_cost
+			FROM stock_snapshots s LEFT JOIN products p ON p.id = s.product_id
+			WHERE s.day = $1 AND s.quantity <> 0 AND s.currency = $2
+			ORDER BY s.product_id`, v.Date, currency)
+	}
+	if err != nil {
+		return v, err
+	}
+	defer rows.Close()
+
+	v.TotalValue = money.New(0, currency)
+	for rows.Next() {
+		var it models.InventoryValuationItem
+		var unitCost int64
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.Quantity, &unitCost); err != nil {
+			return v, err
+		}
+		it.UnitCost = money.New(unitCost, currency)
+		if it.Value, err = it.UnitCost.Mul(int64(it.Quantity)); err != nil {
+			return v, err
+		}
+		if unitCost == 0 {
+			v.UncostedProducts++
+		}
+		if v.TotalValue, err = v.TotalValue.Add(it.Value); err != nil {
+			return v, err
+		}
+		v.Items = append(v.Items, it)
+	}
+	return v, rows.Err()
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"phase3-api-architecture/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultReservationTTL = 15 * time.Minute
+
+var (
+	ErrInsufficientStock   = errors.New("stok tidak mencukupi atau produk tidak ditemukan")
+	ErrReservationNotFound = errors.New("reservasi tidak ditemukan atau sudah kedaluwarsa")
+)
+
+// activeHoldsExpr menghitung total hold aktif untuk produk p.
+// Hold yang sudah lewat expires_at langsung tidak dihitung walau sweeper belum jalan.
+const activeHoldsExpr = `COALESCE((
+	SELECT SUM(r.quantity) FROM reservations r
+	WHERE r.product_id = p.id AND r.status = 'active' AND r.expires_at > NOW()
+), 0)`
+
+type ReservationRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client
+}
+
+func (r *ReservationRepository) Create(ctx context.Context, userID int, req models.ReservationRequest) (models.Reservation, error) {
+	ttl := defaultReservationTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	defer tx.Rollback()
+
+	available, err := lockAvailableStock(ctx, tx, req.ProductID)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	if available < req.Quantity {
+		return models.Reservation{}, ErrInsufficientStock
+	}
+
+	res := models.Reservation{
+		UserID:    userID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		Status:    models.ReservationActive,
+	}
+	queryInsert := `
+		INSERT INTO reservations (user_id, product_id, quantity, status, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(mins => $5))
+		RETURNING id, expires_at, created_at`
+	err = tx.QueryRowContext(ctx, queryInsert, userID, req.ProductID, req.Quantity, res.Status, int(ttl.Minutes())).
+		Scan(&res.ID, &res.ExpiresAt, &res.CreatedAt)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Reservation{}, err
+	}
+
+	invalidateProductCache(ctx, r.Redis, req.ProductID)
+	return res, nil
+}
+
+// Release membatalkan hold milik user sebelum waktunya habis
+func (r *ReservationRepository) Release(ctx context.Context, userID, id int) error {
+	query := `
+		UPDATE reservations SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id = $3 AND status = 'active'
+		RETURNING product_id`
+
+	var productID int
+	err := r.DB.QueryRowContext(ctx, query, models.ReservationReleased, id, userID).Scan(&productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrReservationNotFound
+		}
+		return err
+	}
+
+	invalidateProductCache(ctx, r.Redis, productID)
+	return nil
+}
+
+// ReleaseExpired menandai semua hold yang lewat waktu sebagai expired.
+// Aman dijalankan dari beberapa replica sekaligus karena UPDATE-nya idempotent.
+func (r *ReservationRepository) ReleaseExpired(ctx context.Context) (int, error) {
+	query := `
+		UPDATE reservations SET status = $1, updated_at = NOW()
+		WHERE status = 'active' AND expires_at <= NOW()
+		RETURNING product_id`
+
+	rows, err := r.DB.QueryContext(ctx, query, models.ReservationExpired)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var productIDs []int
+	for rows.Next() {
+		var productID int
+		if err := rows.Scan(&productID); err != nil {
+			return len(productIDs), err
+		}
+		productIDs = append(productIDs, productID)
+	}
+	if len(productIDs) > 0 {
+		invalidateProductCache(ctx, r.Redis, productIDs...)
+	}
+
+	return len(productIDs), rows.Err()
+}
+
+// RunExpirySweeper menjalankan ReleaseExpired secara periodik sampai ctx dibatalkan
+func (r *ReservationRepository) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := r.ReleaseExpired(ctx)
+			if err != nil {
+				log.Printf("[RESERVATION-SWEEPER] Gagal melepas hold expired: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[RESERVATION-SWEEPER] %d hold expired dilepas", n)
+			}
+		}
+	}
+}
+
+// lockAvailableStock mengunci baris produk (FOR UPDATE) lalu menghitung stok tersedia.
+// Hold & checkout untuk produk yang sama jadi berjalan berurutan dan tidak bisa overbook.
+func lockAvailableStock(ctx context.Context, tx *sql.Tx, productID int) (int, error) {
+	var stock int
+	// Produk di trash dianggap tidak ada, jadi tidak bisa dijual / di-hold lagi
+	err := tx.QueryRowContext(ctx, "SELECT stock FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&stock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrInsufficientStock
+		}
+		return 0, err
+	}
+
+	var held int
+	query := `SELECT ` + activeHoldsExpr + ` FROM products p WHERE p.id = $1`
+	if err := tx.QueryRowContext(ctx, query, productID).Scan(&held); err != nil {
+		return 0, err
+	}
+
+	return stock - held, nil
+}
+
+// convertReservation mengubah hold menjadi checkout di dalam transaksi checkout.
+// Seluruh hold dianggap terpakai walau quantity checkout lebih kecil.
+func convertReservation(ctx context.Context, tx *sql.Tx, id, userID, productID, quantity int) error {
+	query := `
+		UPDATE reservations SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id = $3 AND product_id = $4 AND quantity >= $5
+		  AND status = 'active' AND expires_at > NOW()`
+
+	res, err := tx.ExecContext(ctx, query, models.ReservationConverted, id, userID, productID, quantity)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/utils"
+)
+
+var ErrTaxRateNotFound = errors.New("tarif pajak tidak ditemukan")
+
+type TaxRepository struct {
+	DB *sql.DB
+}
+
+const taxRateColumns = "id, name, rate_bp, inclusive, rounding, is_default, created_at"
+
+func scanTaxRate(row rowScanner, t *models.TaxRate) error {
+	return row.Scan(&t.ID, &t.Name, &t.RateBP, &t.Inclusive, &t.Rounding, &t.IsDefault, &t.CreatedAt)
+}
+
+func (r *TaxRepository) GetAll(ctx context.Context) ([]models.TaxRate, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+taxRateColumns+" FROM tax_rates ORDER BY is_default DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.TaxRate{}
+	for rows.Next() {
+		var t models.TaxRate
+		if err := scanTaxRate(rows, &t); err != nil {
+			return nil, err
+		}
+		rates = append(rates, t)
+	}
+	return rates, rows.Err()
+}
+
+// Create menambah tarif pajak. Jika IsDefault, tarif default sebelumnya dilepas.
+func (r *TaxRepository) Create(ctx context.Context, t *models.TaxRate) error {
+	if t.Rounding == "" {
+		t.Rounding = utils.RoundHalfUp
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if t.IsDefault {
+		if _, err := tx.ExecContext(ctx, "UPDATE tax_rates SET is_default = FALSE WHERE is_default"); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO tax_rates (name, rate_bp, inclusive, rounding, is_default)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, query, t.Name, t.RateBP, t.Inclusive, t.Rounding, t.IsDefault).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// resolveTaxRate mencari tarif yang berlaku untuk produk: tarif produk, lalu kategori terdekat
+// (naik ke parent), lalu tarif default. nil = produk tidak kena pajak.
+func resolveTaxRate(ctx context.Context, tx *sql.Tx, productID int) (*models.TaxRate, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT c.id, c.parent_id, c.tax_rate_id, 0 AS depth
+			FROM categories c JOIN products p ON p.category_id = c.id WHERE p.id = $1
+			UNION ALL
+			SELECT c.id, c.parent_id, c.tax_rate_id, a.depth + 1
+			FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT ` + taxRateColumns + ` FROM tax_rates WHERE id = COALESCE(
+			(SELECT tax_rate_id FROM products WHERE id = $1),
+			(SELECT tax_rate_id FROM ancestors WHERE tax_rate_id IS NOT NULL ORDER BY depth LIMIT 1),
+			(SELECT id FROM tax_rates WHERE is_default)
+		)`
+
+	var t models.TaxRate
+	err := scanTaxRate(tx.QueryRowContext(ctx, query, productID), &t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// TransactionRepository membaca histori transaksi (checkout menulis lewat ProductRepository.Checkout)
+type TransactionRepository struct {
+	DB *sql.DB
+}
+
+// Transaksi lama (sebelum satuan jual / rincian harga ada) diisi nilai yang setara
+const transactionColumns = `t.id, t.user_id, t.product_id, p.name, t.quantity, t.total_price, t.currency,
+	COALESCE(t.location_id, 0), t.created_at, t.unit_id, COALESCE(t.unit_quantity, t.quantity), COALESCE(u.name, p.base_unit),
+	COALESCE(t.subtotal, t.total_price), t.discount_amount, t.tax_rate_bp, t.tax_inclusive, t.tax_amount`
+
+func scanTransaction(row rowScanner, t *models.Transaction) error {
+	var currency string
+	err := row.Scan(&t.ID, &t.UserID, &t.ProductID, &t.ProductName, &t.Quantity, &t.TotalPrice.Amount, &currency,
+		&t.LocationID, &t.CreatedAt, &t.UnitID, &t.UnitQuantity, &t.UnitName,
+		&t.Subtotal.Amount, &t.DiscountAmount.Amount, &t.TaxRateBP, &t.TaxInclusive, &t.TaxAmount.Amount)
+	if err != nil {
+		return err
+	}
+	t.TotalPrice.Currency = currency
+	t.Subtotal.Currency = currency
+	t.DiscountAmount.Currency = currency
+	t.TaxAmount.Currency = currency
+	return nil
+}
+
+// encodeCursor: posisi baris terakhir halaman (created_at mikrodetik + id), dibuat opaque untuk client
+func encodeCursor(createdAt time.Time, id int) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixMicro(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	micro, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	us, err1 := strconv.ParseInt(micro, 10, 64)
+	n, err2 := strconv.Atoi(id)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.UnixMicro(us).UTC(), n, nil
+}
+
+// sqlWhere mengumpulkan kondisi WHERE, placeholder ? diganti $n sesuai urutan argumen
+type sqlWhere struct {
+	conds []string
+	args  []interface{}
+}
+
+func (w *sqlWhere) add(cond string, vals ...interface{}) {
+	for _, v := range vals {
+		w.args = append(w.args, v)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(w.args)), 1)
+	}
+	w.conds = append(w.conds, cond)
+}
+
+// param menambah argumen tanpa kondisi dan mengembalikan placeholder-nya, untuk nilai
+// yang dipakai berkali-kali (di WHERE dan ORDER BY)
+func (w *sqlWhere) param(v interface{}) string {
+	w.args = append(w.args, v)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+func (w *sqlWhere) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// transactionFilterWhere: filter histori transaksi (tanpa cursor), dipakai List & export
+func transactionFilterWhere(f models.TransactionFilter) *sqlWhere {
+	where := &sqlWhere{}
+	if f.UserID != 0 {
+		where.add("t.user_id = ?", f.UserID)
+	}
+	if f.ProductID != 0 {
+		where.add("t.product_id = ?", f.ProductID)
+	}
+	if f.From != nil {
+		where.add("t.created_at >= ?", f.From.UTC())
+	}
+	if f.To != nil {
+		where.add("t.created_at < ?", f.To.UTC())
+	}
+	if f.MinTotal != nil {
+		where.add("t.currency = ? AND t.total_price >= ?", f.MinTotal.Currency, f.MinTotal.Amount)
+	}
+	if f.MaxTotal != nil {
+		where.add("t.currency = ? AND t.total_price <= ?", f.MaxTotal.Currency, f.MaxTotal.Amount)
+	}
+	return where
+}
+
+// List mengembalikan histori transaksi terbaru dulu dengan cursor pagination (keyset created_at, id),
+// jadi halaman berikutnya tetap konsisten walaupun ada checkout baru di antara request.
+func (r *TransactionRepository) List(ctx context.Context, f models.TransactionFilter) (models.TransactionPage, error) {
+	page := models.TransactionPage{Items: []models.Transaction{}}
+	if f.Limit < 1 || f.Limit > 100 {
+		f.Limit = 20
+	}
+
+	where := transactionFilterWhere(f)
+	if f.Cursor != "" {
+		createdAt, id, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return page, err
+		}
+		where.add("(t.created_at, t.id) < (?, ?)", createdAt, id)
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		LEFT JOIN product_units u ON u.id = t.unit_id` + where.String()
+	// Ambil 1 baris lebih untuk tahu masih ada halaman berikutnya atau tidak
+	query += fmt.Sprintf(" ORDER BY t.created_at DESC, t.id DESC LIMIT %d", f.Limit+1)
+
+	rows, err := r.DB.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		return page, err
+	}
+	for rows.Next() {
+		var t models.Transaction
+		if err := scanTransaction(rows, &t); err != nil {
+			rows.Close()
+			return page, err
+		}
+		page.Items = append(page.Items, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > f.Limit {
+		page.Items = page.Items[:f.Limit]
+		last := page.Items[f.Limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, r.loadDiscounts(ctx, page.Items)
+}
+
+// loadDiscounts mengisi promosi / kupon yang dipakai setiap transaksi di halaman ini (satu query)
+func (r *TransactionRepository) loadDiscounts(ctx context.Context, items []models.Transaction) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(items))
+	index := map[int]int{}
+	for i, t := range items {
+		ids[i] = int64(t.ID)
+		index[t.ID] = i
+	}
+
+	query := `
+		SELECT td.transaction_id, td.promotion_id, pr.name, COALESCE(pr.code, ''), td.amount
+		FROM transaction_discounts td JOIN promotions pr ON pr.id = td.promotion_id
+		WHERE td.transaction_id = ANY($1)
+		ORDER BY td.id`
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID int
+		var d models.AppliedDiscount
+		if err := rows.Scan(&transactionID, &d.PromotionID, &d.Name, &d.Code, &d.Amount.Amount); err != nil {
+			return err
+		}
+		t := &items[index[transactionID]]
+		d.Amount.Currency = t.TotalPrice.Currency
+		t.Discounts = append(t.Discounts, d)
+	}
+	return rows.Err()
+}
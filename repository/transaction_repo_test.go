@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionCursor(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 10, 30, 0, 123456000, time.UTC)
+
+	gotTime, gotID, err := decodeCursor(encodeCursor(createdAt, 42))
+	assert.NoError(t, err)
+	assert.True(t, createdAt.Equal(gotTime))
+	assert.Equal(t, 42, gotID)
+
+	_, _, err = decodeCursor("bukan-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
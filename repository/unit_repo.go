@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrUnitNotFound  = errors.New("satuan produk tidak ditemukan")
+	ErrUnitExists    = errors.New("nama satuan sudah dipakai di produk ini")
+	ErrDuplicateUnit = errors.New("SKU satuan sudah dipakai")
+)
+
+// ProductUnitRepository mengelola satuan jual produk (kemasan + konversi ke satuan dasar)
+type ProductUnitRepository struct {
+	DB    *sql.DB
+	Redis *redis.Client
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getProductUnits mengembalikan satuan aktif produk, dari kemasan terkecil
+func getProductUnits(ctx context.Context, q queryer, productID int) ([]models.ProductUnit, error) {
+	query := `
+		SELECT u.id, u.product_id, u.name, u.conversion_factor, u.price, p.currency, u.sku, u.created_at
+		FROM product_units u JOIN products p ON p.id = u.product_id
+		WHERE u.product_id = $1 AND u.active
+		ORDER BY u.conversion_factor, u.id`
+	rows, err := q.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []models.ProductUnit{}
+	for rows.Next() {
+		var u models.ProductUnit
+		if err := rows.Scan(&u.ID, &u.ProductID, &u.Name, &u.ConversionFactor, &u.Price.Amount, &u.Price.Currency, &u.SKU, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	return units, rows.Err()
+}
+
+// unitWriteError menerjemahkan pelanggaran unique constraint satuan
+func unitWriteError(err error) error {
+	switch {
+	case isUniqueViolation(err, "uq_product_units_name"):
+		return ErrUnitExists
+	case isUniqueViolation(err, "uq_product_units_sku"):
+		return ErrDuplicateUnit
+	}
+	return err
+}
+
+func (r *ProductUnitRepository) GetByProduct(ctx context.Context, productID int) ([]models.ProductUnit, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)"
+	if err := r.DB.QueryRowContext(ctx, query, productID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	return getProductUnits(ctx, r.DB, productID)
+}
+
+// checkUnitCurrency: harga satuan selalu dalam mata uang produknya
+func (r *ProductUnitRepository) checkUnitCurrency(ctx context.Context, u *models.ProductUnit) error {
+	var currency string
+	err := r.DB.QueryRowContext(ctx, "SELECT currency FROM products WHERE id = $1 AND deleted_at IS NULL", u.ProductID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if u.Price.Currency != currency {
+		return fmt.Errorf("%w: produk memakai %s", money.ErrCurrencyMismatch, currency)
+	}
+	return nil
+}
+
+func (r *ProductUnitRepository) Create(ctx context.Context, u *models.ProductUnit) error {
+	if err := r.checkUnitCurrency(ctx, u); err != nil {
+		return err
+	}
+
+	// Produk di trash tidak bisa ditambah satuan
+	query := `
+		INSERT INTO product_units (product_id, name, conversion_factor, price, sku)
+		SELECT $1, $2, $3, $4, $5
+		WHERE EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)
+		RETURNING id, created_at`
+	err := r.DB.QueryRowContext(ctx, query, u.ProductID, u.Name, u.ConversionFactor, u.Price.Amount, u.SKU).Scan(&u.ID, &u.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrProductNotFound
+		}
+		return unitWriteError(err)
+	}
+
+	invalidateProductCache(ctx, r.Redis, u.ProductID)
+	return nil
+}
+
+// Update mengubah nama, konversi, harga & SKU satuan. Transaksi lama tidak terpengaruh
+// karena quantity transaksi sudah disimpan dalam satuan dasar.
+func (r *ProductUnitRepository) Update(ctx context.Context, u *models.ProductUnit) error {
+	if err := r.checkUnitCurrency(ctx, u); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE product_units SET name = $1, conversion_factor = $2, price = $3, sku = $4, updated_at = NOW()
+		WHERE id = $5 AND product_id = $6 AND active
+		RETURNING created_at`
+	err := r.DB.QueryRowContext(ctx, query, u.Name, u.ConversionFactor, u.Price.Amount, u.SKU, u.ID, u.ProductID).Scan(&u.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUnitNotFound
+		}
+		return unitWriteError(err)
+	}
+
+	invalidateProductCache(ctx, r.Redis, u.ProductID)
+	return nil
+}
+
+// Deactivate menghapus satuan dari daftar jual; barisnya tetap ada untuk histori transaksi
+func (r *ProductUnitRepository) Deactivate(ctx context.Context, productID, unitID int) error {
+	query := "UPDATE product_units SET active = FALSE, updated_at = NOW() WHERE id = $1 AND product_id = $2 AND active"
+	res, err := r.DB.ExecContext(ctx, query, unitID, productID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrUnitNotFound
+	}
+
+	invalidateProductCache(ctx, r.Redis, productID)
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"phase3-api-architecture/models"
 )
@@ -28,3 +29,22 @@ func (r *UserRepository) GetByEmail(email string) (models.User, error) {
 	err := r.DB.QueryRow(query, email).Scan(&u.ID, &u.Email, &u.Password, &u.Role)
 	return u, err
 }
+
+// GetEmailsByRole dipakai worker untuk mengirim digest ke semua admin
+func (r *UserRepository) GetEmailsByRole(ctx context.Context, role string) ([]string, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT email FROM users WHERE role = $1 ORDER BY id", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"phase3-api-architecture/models"
+	"phase3-api-architecture/pkg/money"
+)
+
+var (
+	ErrParentNotFound   = errors.New("produk induk tidak ditemukan")
+	ErrNestedVariant    = errors.New("varian tidak bisa jadi induk varian lain")
+	ErrDuplicateVariant = errors.New("nama varian sudah dipakai di produk ini")
+	ErrVariantNotFound  = errors.New("varian produk tidak ditemukan")
+)
+
+// GetVariants mengembalikan varian aktif sebuah produk (stok tersedia per varian), urut nama varian
+func (r *ProductRepository) GetVariants(ctx context.Context, parentID int) ([]models.Product, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)"
+	if err := r.DB.QueryRowContext(ctx, query, parentID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	query = "SELECT " + productColumns + ", " + availableStockColumns + `
+		FROM products p WHERE p.parent_id = $1 AND p.deleted_at IS NULL
+		ORDER BY LOWER(p.variant_name), p.id`
+	rows, err := r.DB.QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []models.Product{}
+	for rows.Next() {
+		var p models.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		variants = append(variants, p)
+	}
+	return variants, rows.Err()
+}
+
+// checkVariantParent dipanggil sebelum insert varian: induk harus aktif, bukan varian juga,
+// dan mata uangnya sama. Induk dikunci FOR SHARE supaya tidak masuk trash di tengah insert.
+func checkVariantParent(ctx context.Context, tx *sql.Tx, p *models.Product) error {
+	var grandparentID *int
+	var currency string
+	err := tx.QueryRowContext(ctx, "SELECT parent_id, currency FROM products WHERE id = $1 AND deleted_at IS NULL FOR SHARE", *p.ParentID).
+		Scan(&grandparentID, &currency)
+	if err == sql.ErrNoRows {
+		return ErrParentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if grandparentID != nil {
+		return ErrNestedVariant
+	}
+	if p.Price.Currency != currency {
+		return fmt.Errorf("%w: produk induk memakai %s", money.ErrCurrencyMismatch, currency)
+	}
+	return nil
+}
+
+// variantProductID: produk yang benar-benar dijual saat checkout. variantID 0 = produk itu sendiri,
+// selain itu harus varian aktif dari productID.
+func variantProductID(ctx context.Context, tx *sql.Tx, productID, variantID int) (int, error) {
+	if variantID == 0 {
+		return productID, nil
+	}
+
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND parent_id = $2 AND deleted_at IS NULL)"
+	if err := tx.QueryRowContext(ctx, query, variantID, productID).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrVariantNotFound
+	}
+	return variantID, nil
+}
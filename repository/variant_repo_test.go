@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariantProductID(t *testing.T) {
+	ctx := context.Background()
+
+	// Tanpa varian: produk itu sendiri, tidak perlu query
+	id, err := variantProductID(ctx, openFakeTx(t), 5, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
+
+	exists := func(ok bool) fakeQuery {
+		return fakeQuery{match: "parent_id = $2", columns: []string{"exists"}, rows: [][]driver.Value{{ok}}}
+	}
+	id, err = variantProductID(ctx, openFakeTx(t, exists(true)), 5, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, id)
+
+	// Varian produk lain / sudah di trash
+	_, err = variantProductID(ctx, openFakeTx(t, exists(false)), 5, 9)
+	assert.ErrorIs(t, err, ErrVariantNotFound)
+}
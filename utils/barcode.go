@@ -0,0 +1,28 @@
+package utils
+
+// ValidEAN13 mengecek barcode EAN-13: harus 13 digit dan digit terakhir sesuai checksum.
+// Bobot digit ke-1..12 bergantian 1 dan 3, check digit = (10 - total%10) % 10.
+func ValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		if i == 12 {
+			break
+		}
+
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return int(code[12]-'0') == (10-sum%10)%10
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidEAN13(t *testing.T) {
+	assert.True(t, ValidEAN13("4006381333931"))
+	assert.True(t, ValidEAN13("8991002101692"))
+
+	assert.False(t, ValidEAN13("4006381333932"), "checksum salah")
+	assert.False(t, ValidEAN13("400638133393"), "kurang dari 13 digit")
+	assert.False(t, ValidEAN13("40063813339a1"), "bukan angka")
+}
@@ -9,6 +9,19 @@ type APIResponse struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
+}
+
+// PageMeta: info pagination untuk response list
+type PageMeta struct {
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+	// Page hanya terisi kalau request pakai page (bukan cursor)
+	Page       int    `json:"page,omitempty"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
 
 func ResponseJSON(w http.ResponseWriter, statusCode int, message string, data interface{}) {
@@ -24,6 +37,21 @@ func ResponseJSON(w http.ResponseWriter, statusCode int, message string, data in
 	json.NewEncoder(w).Encode(response)
 }
 
+// ResponsePage sama seperti ResponseJSON ditambah meta pagination
+func ResponsePage(w http.ResponseWriter, statusCode int, message string, data interface{}, meta PageMeta) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := APIResponse{
+		Status:  "success",
+		Message: message,
+		Data:    data,
+		Meta:    &meta,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
 func ResponseError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
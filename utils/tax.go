@@ -0,0 +1,42 @@
+package utils
+
+import "math/big"
+
+// Aturan pembulatan pajak ke minor unit mata uang
+const (
+	RoundHalfUp = "half_up" // >= 0.5 dibulatkan ke atas (default)
+	RoundDown   = "down"
+	RoundUp     = "up"
+)
+
+// ComputeTax menghitung pajak dari amount (harga setelah diskon, dalam minor unit) dengan tarif rateBP basis poin (1100 = 11%).
+// Jika inclusive, amount sudah termasuk pajak dan hasilnya adalah porsi pajak di dalamnya: amount x rate / (1 + rate).
+// Perkalian memakai big.Int jadi tidak bisa overflow; hasilnya selalu <= amount.
+func ComputeTax(amount int64, rateBP int, inclusive bool, rounding string) int64 {
+	if amount <= 0 || rateBP <= 0 {
+		return 0
+	}
+
+	num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(rateBP)))
+	den := big.NewInt(10000)
+	if inclusive {
+		den.Add(den, big.NewInt(int64(rateBP)))
+	}
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	switch rounding {
+	case RoundDown:
+	case RoundUp:
+		if rem.Sign() > 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	default:
+		if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return amount
+	}
+	return q.Int64()
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeTax(t *testing.T) {
+	// Exclusive 11%: 10.005 x 11% = 1.100,55
+	assert.Equal(t, int64(1101), ComputeTax(10005, 1100, false, RoundHalfUp))
+	assert.Equal(t, int64(1100), ComputeTax(10005, 1100, false, RoundDown))
+	assert.Equal(t, int64(1101), ComputeTax(10005, 1100, false, RoundUp))
+	assert.Equal(t, int64(1100), ComputeTax(10000, 1100, false, RoundUp))
+
+	// Inclusive 11%: harga 111.000 sudah termasuk PPN 11.000
+	assert.Equal(t, int64(11000), ComputeTax(111000, 1100, true, RoundHalfUp))
+	// 15.000 x 11/111 = 1.486,48
+	assert.Equal(t, int64(1486), ComputeTax(15000, 1100, true, RoundHalfUp))
+	assert.Equal(t, int64(1487), ComputeTax(15000, 1100, true, RoundUp))
+
+	// Tarif 0 / harga 0 tidak kena pajak
+	assert.Equal(t, int64(0), ComputeTax(15000, 0, false, RoundHalfUp))
+	assert.Equal(t, int64(0), ComputeTax(0, 1100, false, RoundHalfUp))
+
+	// Nominal besar tidak overflow: 9e17 x 11%
+	assert.Equal(t, int64(99000000000000000), ComputeTax(900000000000000000, 1100, false, RoundHalfUp))
+}